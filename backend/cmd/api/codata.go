@@ -0,0 +1,92 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+// importCODATA implements "api import-codata -file allascii.txt [-apply]".
+// Without -apply it only prints the diff.
+func importCODATA(str *store.Store, args []string) error {
+	flags := flag.NewFlagSet("import-codata", flag.ContinueOnError)
+	path := flags.String("file", "", "path to a CODATA allascii table")
+	version := flags.String("version", "", "dataset version, if the table does not name it")
+	apply := flags.Bool("apply", false, "apply the changes instead of only printing them")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *path == "" {
+		return errors.New("-file is required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	table, err := services.ParseCODATA(file)
+	if err != nil {
+		return err
+	}
+	if *version != "" {
+		table.Version = *version
+	}
+
+	report, err := str.ImportCODATA(table, nil, *apply)
+	if err != nil {
+		return err
+	}
+	printCODATAReport(os.Stdout, report)
+	return nil
+}
+
+func printCODATAReport(w io.Writer, report *models.CODATAReport) {
+	fmt.Fprintf(w, "CODATA %s", report.Version)
+	if report.PreviousVersion != "" {
+		fmt.Fprintf(w, " (last import: %s)", report.PreviousVersion)
+	}
+	fmt.Fprintf(w, "\n%d added, %d updated, %d unchanged, %d skipped\n\n",
+		len(report.Added), len(report.Updated), report.Unchanged, len(report.Skipped))
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, change := range report.Updated {
+		fmt.Fprintf(tw, "~ %s\t%s\t→ %s\t%s\n", change.Name,
+			formatCODATAValue(change.OldValue, change.OldUncertainty, change.OldExact != nil && *change.OldExact),
+			formatCODATAValue(&change.NewValue, change.NewUncertainty, change.NewExact), change.Unit)
+	}
+	for _, change := range report.Added {
+		fmt.Fprintf(tw, "+ %s\t\t%s\t%s\n", change.Name,
+			formatCODATAValue(&change.NewValue, change.NewUncertainty, change.NewExact), change.Unit)
+	}
+	for _, skipped := range report.Skipped {
+		fmt.Fprintf(tw, "! %s\t%s\t\t\n", skipped.Name, skipped.Reason)
+	}
+	tw.Flush()
+
+	if report.Applied {
+		fmt.Fprintf(w, "\nApplied as dataset %s\n", report.DatasetID)
+	} else {
+		fmt.Fprintln(w, "\nDry run, rerun with -apply to make these changes")
+	}
+}
+
+func formatCODATAValue(value, uncertainty *float64, exact bool) string {
+	switch {
+	case value == nil:
+		return "(unparsed)"
+	case exact:
+		return fmt.Sprintf("%g (exact)", *value)
+	case uncertainty != nil:
+		return fmt.Sprintf("%g ± %g", *value, *uncertainty)
+	}
+	return fmt.Sprintf("%g", *value)
+}
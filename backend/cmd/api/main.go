@@ -1,15 +1,21 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
+	"os"
 	"time"
+	// The runtime image has no zoneinfo; user timezones need the embedded copy.
+	_ "time/tzdata"
 
 	"github.com/gin-contrib/cors"
 	"github.com/gin-gonic/gin"
 	"github.com/itmo-pride/student-taskboard/backend/internal/config"
 	"github.com/itmo-pride/student-taskboard/backend/internal/db"
 	"github.com/itmo-pride/student-taskboard/backend/internal/handlers"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/notify"
 	"github.com/itmo-pride/student-taskboard/backend/internal/store"
 	"github.com/itmo-pride/student-taskboard/backend/internal/ws"
 )
@@ -28,10 +34,32 @@ func main() {
 
 	str := store.NewStore(database)
 
+	if len(os.Args) > 1 {
+		if err := runCommand(str, os.Args[1], os.Args[2:]); err != nil {
+			log.Fatalf("%s: %v", os.Args[1], err)
+		}
+		return
+	}
+
+	if err := str.PromoteSiteAdmins(cfg.SiteAdmins); err != nil {
+		log.Fatalf("Failed to promote site admins: %v", err)
+	}
+
 	hub := ws.NewHub(str)
 	go hub.Run()
 
-	router := setupRouter(cfg, str, hub)
+	channels := []notify.Channel{notify.NewInAppChannel(str)}
+	if cfg.SMTPHost != "" {
+		channels = append(channels, notify.NewEmailChannel(cfg.SMTPHost, cfg.SMTPPort, cfg.SMTPUser, cfg.SMTPPassword, cfg.SMTPFrom))
+	}
+	if cfg.NotifyWebhookURL != "" {
+		channels = append(channels, notify.NewWebhookChannel(cfg.NotifyWebhookURL))
+	}
+	notifier := notify.New(str, channels...)
+	go notifier.Run(context.Background())
+	go notifier.RunDeadlineReminders(context.Background(), cfg.ReminderInterval, cfg.ReminderWindow)
+
+	router := setupRouter(cfg, str, hub, notifier)
 
 	addr := fmt.Sprintf(":%s", cfg.Port)
 	log.Printf("Server starting on %s", addr)
@@ -40,7 +68,16 @@ func main() {
 	}
 }
 
-func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine {
+// runCommand runs a maintenance subcommand instead of the server.
+func runCommand(str *store.Store, name string, args []string) error {
+	switch name {
+	case "import-codata":
+		return importCODATA(str, args)
+	}
+	return fmt.Errorf("unknown command, available: import-codata")
+}
+
+func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub, notifier *notify.Notifier) *gin.Engine {
 	if cfg.Env == "production" {
 		gin.SetMode(gin.ReleaseMode)
 	}
@@ -51,7 +88,7 @@ func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine
 		AllowOrigins:     []string{"*"},
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
 		AllowHeaders:     []string{"Origin", "Content-Type", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		ExposeHeaders:    []string{"Content-Length", "X-Next-Cursor"},
 		AllowCredentials: false,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -72,6 +109,11 @@ func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine
 		protected.Use(handlers.AuthMiddleware(cfg))
 		{
 			protected.GET("/me", handlers.GetMe(str))
+			protected.PUT("/me", handlers.UpdateMe(str))
+			protected.GET("/me/tasks", handlers.GetMyTasks(str))
+			protected.GET("/me/notifications", handlers.GetNotifications(str))
+			protected.GET("/me/notifications/unread-count", handlers.GetUnreadNotificationCount(str))
+			protected.POST("/me/notifications/read", handlers.MarkNotificationsRead(str))
 
 			protected.GET("/projects", handlers.GetProjects(str))
 			protected.POST("/projects", handlers.CreateProject(str))
@@ -84,6 +126,11 @@ func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine
 			protected.POST("/projects/:id/transfer-ownership", handlers.TransferOwnership(str))
 			protected.PUT("/projects/:id/members/:userId/role", handlers.UpdateMemberRole(str))
 			protected.GET("/projects/:id/my-role", handlers.GetMyRole(str))
+			protected.GET("/projects/:id/activity", handlers.GetProjectActivity(str))
+			protected.GET("/projects/:id/export", handlers.ExportProject(str))
+			protected.GET("/projects/:id/workflow", handlers.GetWorkflow(str))
+			protected.PUT("/projects/:id/workflow", handlers.UpdateWorkflow(str))
+			protected.POST("/projects/import", handlers.ImportProject(str, cfg))
 
 			protected.GET("/projects/:id/boards", handlers.GetBoards(str))
 			protected.POST("/projects/:id/boards", handlers.CreateBoard(str))
@@ -95,15 +142,40 @@ func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine
 			protected.GET("/users/search", handlers.SearchUsers(str))
 
 			protected.GET("/projects/:id/tasks", handlers.GetTasks(str))
-			protected.POST("/projects/:id/tasks", handlers.CreateTask(str))
+			protected.POST("/projects/:id/tasks", handlers.CreateTask(str, notifier))
+			protected.GET("/projects/:id/tasks/graph", handlers.GetTaskGraph(str))
+			protected.GET("/projects/:id/views", handlers.GetViews(str))
+			protected.POST("/projects/:id/views", handlers.CreateView(str))
+			protected.GET("/views/:viewId", handlers.GetView(str))
+			protected.PUT("/views/:viewId", handlers.UpdateView(str))
+			protected.DELETE("/views/:viewId", handlers.DeleteView(str))
+			protected.GET("/views/:viewId/tasks", handlers.GetViewTasks(str))
 			protected.GET("/tasks/:id", handlers.GetTask(str))
-			protected.PUT("/tasks/:id", handlers.UpdateTask(str))
+			protected.PUT("/tasks/:id", handlers.UpdateTask(str, notifier))
 			protected.DELETE("/tasks/:id", handlers.DeleteTask(str))
+			protected.POST("/tasks/:id/move", handlers.MoveTask(str))
+			protected.GET("/tasks/:id/history", handlers.GetTaskHistory(str))
+			protected.POST("/tasks/:id/revert", handlers.RevertTask(str))
+			protected.GET("/tasks/:id/subtasks", handlers.GetSubtasks(str))
+			protected.GET("/tasks/:id/dependencies", handlers.GetTaskDependencies(str))
+			protected.POST("/tasks/:id/dependencies", handlers.AddTaskDependency(str))
+			protected.DELETE("/tasks/:id/dependencies/:otherId", handlers.RemoveTaskDependency(str))
+			protected.GET("/tasks/:id/assignees", handlers.GetTaskAssignees(str))
+			protected.POST("/tasks/:id/assignees", handlers.AddTaskAssignee(str, notifier))
+			protected.DELETE("/tasks/:id/assignees/:userId", handlers.RemoveTaskAssignee(str))
+			protected.GET("/tasks/:id/watchers", handlers.GetTaskWatchers(str))
+			protected.POST("/tasks/:id/watchers", handlers.AddTaskWatcher(str))
+			protected.DELETE("/tasks/:id/watchers/:userId", handlers.RemoveTaskWatcher(str))
+			protected.GET("/tasks/:id/checklist", handlers.GetChecklist(str))
+			protected.POST("/tasks/:id/checklist", handlers.CreateChecklistItem(str))
+			protected.PUT("/checklist/:itemId", handlers.UpdateChecklistItem(str))
+			protected.DELETE("/checklist/:itemId", handlers.DeleteChecklistItem(str))
 
 			protected.GET("/tasks/:id/comments", handlers.GetComments(str))
-			protected.POST("/tasks/:id/comments", handlers.CreateComment(str))
-			protected.PUT("/comments/:commentId", handlers.UpdateComment(str))
+			protected.POST("/tasks/:id/comments", handlers.CreateComment(str, notifier))
+			protected.PUT("/comments/:commentId", handlers.UpdateComment(str, notifier))
 			protected.DELETE("/comments/:commentId", handlers.DeleteComment(str))
+			protected.POST("/comments/:commentId/reactions", handlers.ToggleCommentReaction(str))
 
 			protected.GET("/projects/:id/tags", handlers.GetTags(str))
 			protected.POST("/projects/:id/tags", handlers.CreateTag(str))
@@ -114,17 +186,54 @@ func setupRouter(cfg *config.Config, str *store.Store, hub *ws.Hub) *gin.Engine
 			protected.POST("/tasks/:id/tags", handlers.AddTagToTask(str))
 			protected.DELETE("/tasks/:id/tags/:tagId", handlers.RemoveTagFromTask(str))
 
+			protected.GET("/projects/:id/datasets", handlers.GetDatasets(str))
+			protected.POST("/projects/:id/datasets", handlers.CreateDataset(str))
+			protected.POST("/projects/:id/datasets/import", handlers.ImportDataset(str, cfg))
+			protected.GET("/datasets/:id", handlers.GetDataset(str))
+			protected.PUT("/datasets/:id", handlers.UpdateDataset(str))
+			protected.DELETE("/datasets/:id", handlers.DeleteDataset(str))
+			protected.POST("/datasets/:id/columns", handlers.CreateDatasetColumn(str))
+			protected.PUT("/datasets/:id/columns/:columnId", handlers.UpdateDatasetColumn(str))
+			protected.DELETE("/datasets/:id/columns/:columnId", handlers.DeleteDatasetColumn(str))
+			protected.GET("/datasets/:id/rows", handlers.GetDatasetRows(str))
+			protected.POST("/datasets/:id/rows", handlers.CreateDatasetRow(str))
+			protected.PUT("/datasets/:id/rows/:rowId", handlers.UpdateDatasetRow(str))
+			protected.DELETE("/datasets/:id/rows/:rowId", handlers.DeleteDatasetRow(str))
+			protected.GET("/datasets/:id/stats", handlers.GetDatasetStats(str))
+			protected.POST("/datasets/:id/evaluate", handlers.EvaluateDataset(str))
+			protected.POST("/datasets/:id/fit", handlers.FitDataset(str))
+			protected.POST("/datasets/:id/tasks", handlers.LinkDatasetTask(str))
+			protected.DELETE("/datasets/:id/tasks/:taskId", handlers.UnlinkDatasetTask(str))
+			protected.POST("/datasets/:id/formulas", handlers.LinkDatasetFormula(str))
+			protected.DELETE("/datasets/:id/formulas/:formulaId", handlers.UnlinkDatasetFormula(str))
+			protected.GET("/tasks/:id/datasets", handlers.GetTaskDatasets(str))
+
 			protected.GET("/constants", handlers.GetConstants(str))
 			protected.POST("/constants", handlers.CreateConstant(str))
 			protected.GET("/constants/:id", handlers.GetConstant(str))
 			protected.PUT("/constants/:id", handlers.UpdateConstant(str))
 			protected.DELETE("/constants/:id", handlers.DeleteConstant(str))
 
+			protected.POST("/units/convert", handlers.ConvertUnits())
+			protected.POST("/units/check", handlers.CheckUnit())
+
 			protected.GET("/formulas", handlers.GetFormulas(str))
 			protected.POST("/formulas", handlers.CreateFormula(str))
 			protected.GET("/formulas/:id", handlers.GetFormula(str))
 			protected.PUT("/formulas/:id", handlers.UpdateFormula(str))
 			protected.DELETE("/formulas/:id", handlers.DeleteFormula(str))
+			protected.POST("/formulas/:id/evaluate", handlers.EvaluateFormula(str))
+			protected.POST("/formulas/:id/uncertainty", handlers.PropagateUncertainty(str))
+
+			protected.GET("/moderation/queue", handlers.GetModerationQueue(str))
+			protected.GET("/moderation/log", handlers.GetModerationLog(str))
+			protected.POST("/moderation/constants/:id/approve", handlers.ReviewConstant(str, notifier, models.ReviewApproved))
+			protected.POST("/moderation/constants/:id/reject", handlers.ReviewConstant(str, notifier, models.ReviewRejected))
+			protected.POST("/moderation/formulas/:id/approve", handlers.ReviewFormula(str, notifier, models.ReviewApproved))
+			protected.POST("/moderation/formulas/:id/reject", handlers.ReviewFormula(str, notifier, models.ReviewRejected))
+			protected.PUT("/admin/users/:id/site-role", handlers.SetUserSiteRole(str))
+			protected.POST("/admin/codata/import", handlers.ImportCODATA(str, cfg))
+			protected.GET("/admin/codata/datasets", handlers.GetConstantDatasets(str))
 
 			protected.POST("/attachments", handlers.UploadAttachment(str, cfg))
 			protected.GET("/attachments/:id", handlers.GetAttachment(str))
@@ -3,6 +3,7 @@ package config
 import (
     "fmt"
     "os"
+    "strings"
     "time"
 
     "github.com/joho/godotenv"
@@ -24,6 +25,7 @@ type Config struct {
     
     UploadPath        string
     MaxUploadSize     int64
+    MaxImportSize     int64
     AllowedFileTypes  string
     
     UseS3         bool
@@ -31,6 +33,20 @@ type Config struct {
     S3Region      string
     S3AccessKey   string
     S3SecretKey   string
+
+    ReminderInterval time.Duration
+    ReminderWindow   time.Duration
+
+    SMTPHost     string
+    SMTPPort     string
+    SMTPUser     string
+    SMTPPassword string
+    SMTPFrom     string
+
+    NotifyWebhookURL string
+
+    // SiteAdmins are emails of users promoted to site admin on startup.
+    SiteAdmins []string
 }
 
 func Load() (*Config, error) {
@@ -41,6 +57,16 @@ func Load() (*Config, error) {
         return nil, fmt.Errorf("invalid JWT_EXPIRES_IN: %w", err)
     }
 
+    reminderInterval, err := time.ParseDuration(getEnv("REMINDER_INTERVAL", "15m"))
+    if err != nil {
+        return nil, fmt.Errorf("invalid REMINDER_INTERVAL: %w", err)
+    }
+
+    reminderWindow, err := time.ParseDuration(getEnv("REMINDER_WINDOW", "24h"))
+    if err != nil {
+        return nil, fmt.Errorf("invalid REMINDER_WINDOW: %w", err)
+    }
+
     return &Config{
         Port: getEnv("PORT", "8080"),
         Env:  getEnv("ENV", "development"),
@@ -57,16 +83,40 @@ func Load() (*Config, error) {
         
         UploadPath:       getEnv("UPLOAD_PATH", "./uploads"),
         MaxUploadSize:    10485760, // 10MB
-        AllowedFileTypes: getEnv("ALLOWED_FILE_TYPES", ".pdf,.png,.jpg,.jpeg,.tex,.txt"),
+        MaxImportSize:    104857600, // 100MB
+        AllowedFileTypes: getEnv("ALLOWED_FILE_TYPES", ".pdf,.png,.jpg,.jpeg,.tex,.txt,.csv,.tsv"),
         
         UseS3:       getEnv("USE_S3", "false") == "true",
         S3Bucket:    getEnv("S3_BUCKET", ""),
         S3Region:    getEnv("S3_REGION", ""),
         S3AccessKey: getEnv("S3_ACCESS_KEY", ""),
         S3SecretKey: getEnv("S3_SECRET_KEY", ""),
+
+        ReminderInterval: reminderInterval,
+        ReminderWindow:   reminderWindow,
+
+        SMTPHost:     getEnv("SMTP_HOST", ""),
+        SMTPPort:     getEnv("SMTP_PORT", "587"),
+        SMTPUser:     getEnv("SMTP_USER", ""),
+        SMTPPassword: getEnv("SMTP_PASSWORD", ""),
+        SMTPFrom:     getEnv("SMTP_FROM", ""),
+
+        NotifyWebhookURL: getEnv("NOTIFY_WEBHOOK_URL", ""),
+
+        SiteAdmins: splitList(getEnv("SITE_ADMINS", "")),
     }, nil
 }
 
+func splitList(value string) []string {
+    var items []string
+    for _, item := range strings.Split(value, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
+
 func getEnv(key, defaultValue string) string {
     if value := os.Getenv(key); value != "" {
         return value
@@ -0,0 +1,151 @@
+package expr
+
+import (
+	"fmt"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+// Issue is a dimensional inconsistency found by Check. Term is the part of
+// the formula at fault.
+type Issue struct {
+	Term    string
+	Message string
+}
+
+// dimInfo is what the checker knows about a subexpression: its dimension,
+// if every variable in it has one, and its value if it is a plain number.
+type dimInfo struct {
+	dim   units.Dimension
+	known bool
+	value *float64
+}
+
+type checker struct {
+	dims   map[string]units.Dimension
+	issues []Issue
+}
+
+// Check checks the dimensions of an equation without evaluating it. dims
+// holds the dimensions of the variables that have one; parts of the
+// formula with other variables are only checked as far as the known parts
+// go. It returns the dimension of the formula, if known, and the
+// inconsistencies found.
+func Check(eq *Equation, dims map[string]units.Dimension) (*units.Dimension, []Issue) {
+	c := &checker{dims: dims}
+	right := c.check(eq.Right)
+	if eq.Left == nil {
+		return right.dimension(), c.issues
+	}
+
+	left := c.check(eq.Left)
+	if left.known && right.known && left.dim != right.dim {
+		c.report(eq.String(), "left side has dimension %s but right side has %s", left.dim, right.dim)
+	}
+	if right.known {
+		return right.dimension(), c.issues
+	}
+	return left.dimension(), c.issues
+}
+
+func (d dimInfo) dimension() *units.Dimension {
+	if !d.known {
+		return nil
+	}
+	return &d.dim
+}
+
+func (c *checker) report(term, format string, args ...interface{}) {
+	c.issues = append(c.issues, Issue{Term: term, Message: fmt.Sprintf(format, args...)})
+}
+
+func (c *checker) check(n Node) dimInfo {
+	switch n := n.(type) {
+	case *Num:
+		value := n.Value
+		return dimInfo{known: true, value: &value}
+	case *Var:
+		dim, ok := c.dims[n.Name]
+		return dimInfo{dim: dim, known: ok}
+	case *Neg:
+		x := c.check(n.X)
+		if x.value != nil {
+			value := -*x.value
+			x.value = &value
+		}
+		return x
+	case *Binary:
+		return c.checkBinary(n, c.check(n.X), c.check(n.Y))
+	case *Call:
+		return c.checkCall(n, c.check(n.X))
+	}
+	return dimInfo{}
+}
+
+func (c *checker) checkBinary(n *Binary, x, y dimInfo) dimInfo {
+	var out dimInfo
+	if x.value != nil && y.value != nil {
+		q, err := evalBinary(n, Quantity{Value: *x.value}, Quantity{Value: *y.value})
+		if err == nil {
+			out.value = &q.Value
+		}
+	}
+
+	switch n.Op {
+	case '+', '-':
+		if x.known && y.known && x.dim != y.dim {
+			verb := "add"
+			if n.Op == '-' {
+				verb = "subtract"
+			}
+			c.report(n.String(), "cannot %s %s and %s", verb, x.dim, y.dim)
+		}
+		// Either known side gives the dimension of the sum.
+		if x.known {
+			out.dim, out.known = x.dim, true
+		} else {
+			out.dim, out.known = y.dim, y.known
+		}
+	case '*':
+		out.dim, out.known = x.dim.Mul(y.dim), x.known && y.known
+	case '/':
+		out.dim, out.known = x.dim.Div(y.dim), x.known && y.known
+	case '^':
+		switch {
+		case y.known && !y.dim.IsDimensionless():
+			c.report(n.String(), "exponent has dimension %s", y.dim)
+		case !x.known:
+		case x.dim.IsDimensionless():
+			out.known = true
+		case y.value == nil:
+			c.report(n.String(), "%s can only be raised to a constant power", x.dim)
+		default:
+			dim, ok := powDimension(x.dim, *y.value)
+			if !ok {
+				c.report(n.String(), "%s cannot be raised to the power %g", x.dim, *y.value)
+			}
+			out.dim, out.known = dim, ok
+		}
+	}
+	return out
+}
+
+func (c *checker) checkCall(n *Call, x dimInfo) dimInfo {
+	switch n.Func {
+	case "abs":
+		return dimInfo{dim: x.dim, known: x.known}
+	case "sqrt":
+		if !x.known {
+			return dimInfo{}
+		}
+		dim, ok := powDimension(x.dim, 0.5)
+		if !ok {
+			c.report(n.String(), "%s has no square root", x.dim)
+		}
+		return dimInfo{dim: dim, known: ok}
+	}
+	if x.known && !x.dim.IsDimensionless() {
+		c.report(n.String(), "%s of a quantity with dimension %s", n.Func, x.dim)
+	}
+	return dimInfo{known: true}
+}
@@ -0,0 +1,219 @@
+package expr
+
+import "math"
+
+// Diff returns the derivative of n with respect to the variable name. The
+// result is simplified only as far as folding numbers and dropping zero and
+// unit factors, which keeps it readable in responses.
+func Diff(n Node, name string) Node {
+	switch n := n.(type) {
+	case *Num:
+		return zero()
+	case *Var:
+		if n.Name == name {
+			return num(1)
+		}
+		return zero()
+	case *Neg:
+		return neg(Diff(n.X, name))
+	case *Call:
+		return mul(diffCall(n), Diff(n.X, name))
+	case *Binary:
+		return diffBinary(n, name)
+	}
+	return zero()
+}
+
+func diffBinary(n *Binary, name string) Node {
+	x, y := n.X, n.Y
+	dx, dy := Diff(x, name), Diff(y, name)
+	switch n.Op {
+	case '+':
+		return add(dx, dy)
+	case '-':
+		return sub(dx, dy)
+	case '*':
+		return add(mul(dx, y), mul(x, dy))
+	case '/':
+		if isZero(dy) {
+			return div(dx, y)
+		}
+		return div(sub(mul(dx, y), mul(x, dy)), pow(y, num(2)))
+	}
+
+	// Powers: the familiar rule for a constant exponent, the exponential
+	// rule for a constant base, and the general rule otherwise.
+	switch {
+	case isZero(dy):
+		return mul(mul(y, pow(x, sub(y, num(1)))), dx)
+	case isZero(dx):
+		return mul(mul(n, ln(x)), dy)
+	}
+	return mul(n, add(mul(dy, ln(x)), div(mul(y, dx), x)))
+}
+
+// diffCall returns the derivative of a function with respect to its
+// argument, to be multiplied by the derivative of the argument.
+func diffCall(n *Call) Node {
+	x := n.X
+	switch n.Func {
+	case "sqrt":
+		return div(num(1), mul(num(2), n))
+	case "abs":
+		return div(x, n)
+	case "sin":
+		return &Call{Func: "cos", X: x}
+	case "cos":
+		return neg(&Call{Func: "sin", X: x})
+	case "tan":
+		return div(num(1), pow(&Call{Func: "cos", X: x}, num(2)))
+	case "cot":
+		return neg(div(num(1), pow(&Call{Func: "sin", X: x}, num(2))))
+	case "sec":
+		return mul(n, &Call{Func: "tan", X: x})
+	case "csc":
+		return neg(mul(n, &Call{Func: "cot", X: x}))
+	case "arcsin":
+		return div(num(1), &Call{Func: "sqrt", X: sub(num(1), pow(x, num(2)))})
+	case "arccos":
+		return neg(div(num(1), &Call{Func: "sqrt", X: sub(num(1), pow(x, num(2)))}))
+	case "arctan":
+		return div(num(1), add(num(1), pow(x, num(2))))
+	case "sinh":
+		return &Call{Func: "cosh", X: x}
+	case "cosh":
+		return &Call{Func: "sinh", X: x}
+	case "tanh":
+		return div(num(1), pow(&Call{Func: "cosh", X: x}, num(2)))
+	case "coth":
+		return neg(div(num(1), pow(&Call{Func: "sinh", X: x}, num(2))))
+	case "exp":
+		return n
+	case "ln":
+		return div(num(1), x)
+	case "log":
+		return div(num(1), mul(x, &Num{Value: math.Ln10, Text: "ln(10)"}))
+	}
+	return zero()
+}
+
+func num(v float64) *Num { return &Num{Value: v} }
+
+func ln(x Node) Node {
+	if x == euler {
+		return num(1)
+	}
+	return &Call{Func: "ln", X: x}
+}
+
+func zero() *Num { return num(0) }
+
+func numValue(n Node) (float64, bool) {
+	if n, ok := n.(*Num); ok {
+		return n.Value, true
+	}
+	return 0, false
+}
+
+func isZero(n Node) bool {
+	v, ok := numValue(n)
+	return ok && v == 0
+}
+
+func isOne(n Node) bool {
+	v, ok := numValue(n)
+	return ok && v == 1
+}
+
+func neg(x Node) Node {
+	if v, ok := numValue(x); ok {
+		return num(-v)
+	}
+	if n, ok := x.(*Neg); ok {
+		return n.X
+	}
+	return &Neg{X: x}
+}
+
+func add(x, y Node) Node {
+	a, aok := numValue(x)
+	b, bok := numValue(y)
+	switch {
+	case aok && bok:
+		return num(a + b)
+	case isZero(x):
+		return y
+	case isZero(y):
+		return x
+	}
+	if n, ok := y.(*Neg); ok {
+		return &Binary{Op: '-', X: x, Y: n.X}
+	}
+	return &Binary{Op: '+', X: x, Y: y}
+}
+
+func sub(x, y Node) Node {
+	a, aok := numValue(x)
+	b, bok := numValue(y)
+	switch {
+	case aok && bok:
+		return num(a - b)
+	case isZero(y):
+		return x
+	case isZero(x):
+		return neg(y)
+	}
+	if n, ok := y.(*Neg); ok {
+		return &Binary{Op: '+', X: x, Y: n.X}
+	}
+	return &Binary{Op: '-', X: x, Y: y}
+}
+
+func mul(x, y Node) Node {
+	a, aok := numValue(x)
+	b, bok := numValue(y)
+	switch {
+	case aok && bok:
+		return num(a * b)
+	case isZero(x) || isZero(y):
+		return zero()
+	case isOne(x):
+		return y
+	case isOne(y):
+		return x
+	}
+	if n, ok := x.(*Neg); ok {
+		return neg(mul(n.X, y))
+	}
+	if n, ok := y.(*Neg); ok {
+		return neg(mul(x, n.X))
+	}
+	return &Binary{Op: '*', X: x, Y: y}
+}
+
+func div(x, y Node) Node {
+	a, aok := numValue(x)
+	b, bok := numValue(y)
+	switch {
+	case aok && bok && b != 0:
+		return num(a / b)
+	case isZero(x):
+		return zero()
+	case isOne(y):
+		return x
+	}
+	if n, ok := x.(*Neg); ok {
+		return neg(div(n.X, y))
+	}
+	return &Binary{Op: '/', X: x, Y: y}
+}
+
+func pow(x, y Node) Node {
+	switch {
+	case isZero(y):
+		return num(1)
+	case isOne(y):
+		return x
+	}
+	return &Binary{Op: '^', X: x, Y: y}
+}
@@ -0,0 +1,59 @@
+package expr
+
+import (
+	"math"
+	"testing"
+)
+
+// TestDiff compares derivatives with central differences at a point where
+// every formula is smooth.
+func TestDiff(t *testing.T) {
+	point := map[string]float64{"x": 1.3, "y": 0.7}
+	tests := []string{
+		`x^y`,
+		`\log_{y} x`,
+		`|x-y|`,
+		`\sqrt[3]{x y}`,
+		`\arctan x`,
+		`\arctan \frac{y}{x}`,
+		`\frac{\sin x}{x^2 + 1}`,
+		`\mathrm{e}^{-x y}`,
+		`\sqrt{x^2 + y^2}`,
+		`x \ln x - y \log x`,
+		`\sin^2 x + \cos^2 y`,
+		`\tanh(x - y) \cosh y`,
+		`\arcsin y \arccos \frac{x}{2}`,
+	}
+	for _, latex := range tests {
+		f := MustParse(latex).Right
+		for _, name := range []string{"x", "y"} {
+			d := Diff(f, name)
+			got := evalAt(t, d, point)
+
+			const h = 1e-6
+			vars := map[string]float64{"x": point["x"], "y": point["y"]}
+			vars[name] = point[name] + h
+			above := evalAt(t, f, vars)
+			vars[name] = point[name] - h
+			below := evalAt(t, f, vars)
+			want := (above - below) / (2 * h)
+
+			if math.Abs(got-want) > 1e-6*math.Max(1, math.Abs(want)) {
+				t.Errorf("d/d%s %s = %s = %g, want %g", name, latex, d, got, want)
+			}
+		}
+	}
+}
+
+func evalAt(t *testing.T, n Node, point map[string]float64) float64 {
+	t.Helper()
+	vars := make(map[string]Quantity, len(point))
+	for name, v := range point {
+		vars[name] = Quantity{Value: v}
+	}
+	q, err := Eval(n, vars)
+	if err != nil {
+		t.Fatalf("Eval(%s): %v", n, err)
+	}
+	return q.Value
+}
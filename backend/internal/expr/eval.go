@@ -0,0 +1,170 @@
+package expr
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+// Quantity is a value in coherent SI units with its dimension.
+type Quantity struct {
+	Value float64
+	Dim   units.Dimension
+}
+
+// mathFunctions take and return dimensionless values.
+var mathFunctions = map[string]func(float64) float64{
+	"sin":    math.Sin,
+	"cos":    math.Cos,
+	"tan":    math.Tan,
+	"cot":    func(x float64) float64 { return 1 / math.Tan(x) },
+	"sec":    func(x float64) float64 { return 1 / math.Cos(x) },
+	"csc":    func(x float64) float64 { return 1 / math.Sin(x) },
+	"arcsin": math.Asin,
+	"arccos": math.Acos,
+	"arctan": math.Atan,
+	"sinh":   math.Sinh,
+	"cosh":   math.Cosh,
+	"tanh":   math.Tanh,
+	"coth":   func(x float64) float64 { return 1 / math.Tanh(x) },
+	"exp":    math.Exp,
+	"ln":     math.Log,
+	"log":    math.Log10,
+}
+
+// Eval evaluates n with the given variable values, checking dimensions as
+// it goes: only quantities of the same dimension can be added, and
+// exponents and function arguments must be dimensionless.
+func Eval(n Node, vars map[string]Quantity) (Quantity, error) {
+	q, err := eval(n, vars)
+	if err != nil {
+		return Quantity{}, err
+	}
+	if math.IsNaN(q.Value) || math.IsInf(q.Value, 0) {
+		return Quantity{}, &EvalError{Term: n.String(), Message: "result is not a finite number"}
+	}
+	return q, nil
+}
+
+func eval(n Node, vars map[string]Quantity) (Quantity, error) {
+	switch n := n.(type) {
+	case *Num:
+		return Quantity{Value: n.Value}, nil
+	case *Var:
+		q, ok := vars[n.Name]
+		if !ok {
+			return Quantity{}, &EvalError{Term: n.Name, Message: "no value for variable"}
+		}
+		return q, nil
+	case *Neg:
+		q, err := eval(n.X, vars)
+		q.Value = -q.Value
+		return q, err
+	case *Call:
+		return evalCall(n, vars)
+	case *Binary:
+		x, err := eval(n.X, vars)
+		if err != nil {
+			return Quantity{}, err
+		}
+		y, err := eval(n.Y, vars)
+		if err != nil {
+			return Quantity{}, err
+		}
+		return evalBinary(n, x, y)
+	}
+	return Quantity{}, fmt.Errorf("unknown node %T", n)
+}
+
+func evalBinary(n *Binary, x, y Quantity) (Quantity, error) {
+	switch n.Op {
+	case '+', '-':
+		if x.Dim != y.Dim {
+			return Quantity{}, &EvalError{
+				Term:    n.String(),
+				Message: fmt.Sprintf("cannot combine %s and %s", x.Dim, y.Dim),
+			}
+		}
+		if n.Op == '-' {
+			y.Value = -y.Value
+		}
+		return Quantity{Value: x.Value + y.Value, Dim: x.Dim}, nil
+	case '*':
+		return Quantity{Value: x.Value * y.Value, Dim: x.Dim.Mul(y.Dim)}, nil
+	case '/':
+		if y.Value == 0 {
+			return Quantity{}, &EvalError{Term: n.String(), Message: "division by zero"}
+		}
+		return Quantity{Value: x.Value / y.Value, Dim: x.Dim.Div(y.Dim)}, nil
+	}
+
+	if !y.Dim.IsDimensionless() {
+		return Quantity{}, &EvalError{
+			Term:    n.String(),
+			Message: fmt.Sprintf("exponent has dimension %s", y.Dim),
+		}
+	}
+	dim, ok := powDimension(x.Dim, y.Value)
+	if !ok {
+		return Quantity{}, &EvalError{
+			Term:    n.String(),
+			Message: fmt.Sprintf("%s cannot be raised to the power %g", x.Dim, y.Value),
+		}
+	}
+	return Quantity{Value: math.Pow(x.Value, y.Value), Dim: dim}, nil
+}
+
+// powDimension raises a dimension to a real power, which only works when
+// every exponent comes out whole: m^2 has a square root, m does not.
+func powDimension(d units.Dimension, p float64) (units.Dimension, bool) {
+	var out units.Dimension
+	for i, e := range d {
+		v := float64(e) * p
+		r := math.Round(v)
+		if math.Abs(v-r) > 1e-9 {
+			return units.Dimension{}, false
+		}
+		out[i] = int(r)
+	}
+	return out, true
+}
+
+func evalCall(n *Call, vars map[string]Quantity) (Quantity, error) {
+	x, err := eval(n.X, vars)
+	if err != nil {
+		return Quantity{}, err
+	}
+	switch n.Func {
+	case "abs":
+		return Quantity{Value: math.Abs(x.Value), Dim: x.Dim}, nil
+	case "sqrt":
+		dim, ok := powDimension(x.Dim, 0.5)
+		if !ok {
+			return Quantity{}, &EvalError{
+				Term:    n.String(),
+				Message: fmt.Sprintf("%s has no square root", x.Dim),
+			}
+		}
+		if x.Value < 0 {
+			return Quantity{}, &EvalError{Term: n.String(), Message: "square root of a negative number"}
+		}
+		return Quantity{Value: math.Sqrt(x.Value), Dim: dim}, nil
+	}
+
+	f, ok := mathFunctions[n.Func]
+	if !ok {
+		return Quantity{}, fmt.Errorf("unknown function %s", n.Func)
+	}
+	if !x.Dim.IsDimensionless() {
+		return Quantity{}, &EvalError{
+			Term:    n.String(),
+			Message: fmt.Sprintf("%s of a quantity with dimension %s", n.Func, x.Dim),
+		}
+	}
+	v := f(x.Value)
+	if math.IsNaN(v) {
+		return Quantity{}, &EvalError{Term: n.String(), Message: "argument out of range"}
+	}
+	return Quantity{Value: v}, nil
+}
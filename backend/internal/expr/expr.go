@@ -0,0 +1,217 @@
+// Package expr parses the LaTeX that formulas are written in into
+// expression trees and evaluates them on quantities with units.
+//
+// The supported subset is what students write for physics formulas:
+// numbers, single-letter variables with subscripts (E_k, v_{0}, \alpha),
+// + - \cdot \times / and implicit multiplication, powers, \frac, \sqrt,
+// absolute values, the common functions, \pi and \mathrm{e}, and at most
+// one "=". Variable names are normalized the way constant symbols are
+// stored, so \varepsilon_0 and ε0 are the same name.
+package expr
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Node is a node of an expression tree.
+type Node interface {
+	// String writes the expression in plain notation, e.g. "1/2*m*v^2".
+	String() string
+	precedence() int
+}
+
+const (
+	precSum = iota + 1
+	precProduct
+	precNeg
+	precPow
+	precAtom
+)
+
+// Num is a number, or a mathematical constant written as Text.
+type Num struct {
+	Value float64
+	Text  string
+}
+
+type Var struct {
+	Name string
+}
+
+type Neg struct {
+	X Node
+}
+
+// Binary is one of the operations + - * / ^.
+type Binary struct {
+	Op byte
+	X  Node
+	Y  Node
+}
+
+// Call applies a function such as "sin" or "sqrt" to its argument.
+type Call struct {
+	Func string
+	X    Node
+}
+
+func (n *Num) String() string {
+	if n.Text != "" {
+		return n.Text
+	}
+	return strconv.FormatFloat(n.Value, 'g', -1, 64)
+}
+
+func (n *Var) String() string { return n.Name }
+
+func (n *Neg) String() string { return "-" + wrap(n.X, precNeg, false) }
+
+func (n *Binary) String() string {
+	prec := n.precedence()
+	// The right operand of - and / and the left one of ^ need brackets
+	// at equal precedence.
+	left := wrap(n.X, prec, n.Op == '^')
+	right := wrap(n.Y, prec, n.Op == '-' || n.Op == '/')
+	if n.Op == '+' || n.Op == '-' {
+		return left + " " + string(n.Op) + " " + right
+	}
+	return left + string(n.Op) + right
+}
+
+func (n *Call) String() string { return n.Func + "(" + n.X.String() + ")" }
+
+func (n *Num) precedence() int  { return precAtom }
+func (n *Var) precedence() int  { return precAtom }
+func (n *Neg) precedence() int  { return precNeg }
+func (n *Call) precedence() int { return precAtom }
+
+func (n *Binary) precedence() int {
+	switch n.Op {
+	case '+', '-':
+		return precSum
+	case '*', '/':
+		return precProduct
+	}
+	return precPow
+}
+
+func wrap(n Node, prec int, strict bool) string {
+	if p := n.precedence(); p < prec || strict && p == prec {
+		return "(" + n.String() + ")"
+	}
+	return n.String()
+}
+
+// Equation is a parsed formula. Left is nil when the formula is a bare
+// expression without "=".
+type Equation struct {
+	Left  Node
+	Right Node
+}
+
+func (e *Equation) String() string {
+	if e.Left == nil {
+		return e.Right.String()
+	}
+	return e.Left.String() + " = " + e.Right.String()
+}
+
+// Solved returns the variable the equation defines and the expression that
+// computes it: the side opposite a lone variable. A bare expression defines
+// no name.
+func (e *Equation) Solved() (string, Node, error) {
+	if e.Left == nil {
+		return "", e.Right, nil
+	}
+	if v, ok := e.Left.(*Var); ok {
+		return v.Name, e.Right, nil
+	}
+	if v, ok := e.Right.(*Var); ok {
+		return v.Name, e.Left, nil
+	}
+	return "", nil, fmt.Errorf("cannot evaluate %s: neither side is a single variable", e)
+}
+
+// Variables returns the names of the variables in n, sorted.
+func Variables(n Node) []string {
+	seen := map[string]bool{}
+	var walk func(Node)
+	walk = func(n Node) {
+		switch n := n.(type) {
+		case *Var:
+			seen[n.Name] = true
+		case *Neg:
+			walk(n.X)
+		case *Binary:
+			walk(n.X)
+			walk(n.Y)
+		case *Call:
+			walk(n.X)
+		}
+	}
+	walk(n)
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// SyntaxError reports LaTeX outside the supported subset. Pos is a byte
+// offset into the input.
+type SyntaxError struct {
+	Pos     int
+	Message string
+}
+
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("%s at position %d", e.Message, e.Pos)
+}
+
+func syntaxErrorf(pos int, format string, args ...interface{}) error {
+	return &SyntaxError{Pos: pos, Message: fmt.Sprintf(format, args...)}
+}
+
+// EvalError reports a term that cannot be evaluated, such as a sum of
+// quantities with different dimensions.
+type EvalError struct {
+	Term    string
+	Message string
+}
+
+func (e *EvalError) Error() string {
+	return fmt.Sprintf("%s in %s", e.Message, e.Term)
+}
+
+var (
+	pi    = &Num{Value: math.Pi, Text: "π"}
+	euler = &Num{Value: math.E, Text: "e"}
+)
+
+// Symbol normalizes a constant symbol or variable name to the form used in
+// parsed expressions, so "E_{k}", "\alpha" and "ε0" become "E_k", "α" and
+// "ε_0". Symbols that are not a single variable are returned trimmed.
+func Symbol(s string) string {
+	s = strings.TrimSpace(s)
+	tokens, err := tokenize(s)
+	if err != nil {
+		return s
+	}
+	// A letter directly followed by digits, as in ε0 or μ0, is subscripted.
+	if len(tokens) == 3 && tokens[0].kind == tokLetter && tokens[1].kind == tokNumber &&
+		tokens[1].pos == tokens[0].pos+len(tokens[0].text) {
+		return tokens[0].text + "_" + tokens[1].text
+	}
+	p := &parser{tokens: tokens}
+	v, err := p.parseVariable()
+	if err != nil || p.peek().kind != tokEOF {
+		return s
+	}
+	return v.Name
+}
@@ -0,0 +1,317 @@
+package expr
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	// tokLetter is a variable name: one letter, a Greek letter command
+	// mapped to Unicode, or the text of \mathrm{…} and friends.
+	tokLetter
+	// tokCommand is \frac, \sqrt, a function name, or "e" for \mathrm{e}.
+	tokCommand
+	// tokSymbol is an operator or bracket, with \cdot and the like mapped
+	// to their ASCII form.
+	tokSymbol
+)
+
+type token struct {
+	kind tokenKind
+	text string
+	pos  int
+}
+
+// greek maps letter commands to the Unicode letters constant symbols are
+// stored with. Variant forms map to the plain letter so that \varepsilon_0
+// finds ε0.
+var greek = map[string]string{
+	"alpha": "α", "beta": "β", "gamma": "γ", "delta": "δ", "epsilon": "ε", "varepsilon": "ε",
+	"zeta": "ζ", "eta": "η", "theta": "θ", "vartheta": "θ", "iota": "ι", "kappa": "κ",
+	"lambda": "λ", "mu": "μ", "nu": "ν", "xi": "ξ", "pi": "π", "varpi": "π", "rho": "ρ",
+	"varrho": "ρ", "sigma": "σ", "varsigma": "σ", "tau": "τ", "upsilon": "υ", "phi": "φ",
+	"varphi": "φ", "chi": "χ", "psi": "ψ", "omega": "ω",
+	"Gamma": "Γ", "Delta": "Δ", "Theta": "Θ", "Lambda": "Λ", "Xi": "Ξ", "Pi": "Π",
+	"Sigma": "Σ", "Upsilon": "Υ", "Phi": "Φ", "Psi": "Ψ", "Omega": "Ω",
+	"hbar": "ħ", "ell": "ℓ", "infty": "∞",
+}
+
+// letterVariants maps look-alike code points to the one used in names.
+var letterVariants = map[rune]rune{
+	'µ': 'μ', 'ϵ': 'ε', 'ϑ': 'θ', 'ϕ': 'φ', 'ϱ': 'ρ', 'ς': 'σ', 'ϖ': 'π', 'ℏ': 'ħ',
+}
+
+// accents become combining marks on the letter they decorate, so \bar{x}
+// is the variable "x̄".
+var accents = map[string]string{
+	"bar": "̄", "overline": "̅", "hat": "̂", "tilde": "̃",
+	"dot": "̇", "ddot": "̈", "vec": "⃗",
+}
+
+var operatorCommands = map[string]string{
+	"cdot": "*", "times": "*", "ast": "*", "div": "/",
+}
+
+var symbolRunes = map[rune]string{
+	'+': "+", '-': "-", '−': "-", '*': "*", '·': "*", '⋅': "*", '×': "*", '/': "/", '÷': "/",
+	'^': "^", '_': "_", '=': "=", '(': "(", ')': ")", '{': "{", '}': "}", '[': "[", ']': "]", '|': "|", ',': ",",
+}
+
+// ignoredCommands only affect spacing or delimiter size.
+var ignoredCommands = map[string]bool{
+	"left": true, "right": true, "big": true, "Big": true, "bigg": true, "Bigg": true,
+	"bigl": true, "bigr": true, "Bigl": true, "Bigr": true, "quad": true, "qquad": true,
+	"displaystyle": true, "textstyle": true, "limits": true,
+}
+
+// textCommands take an upright or text argument that names one thing.
+var textCommands = map[string]bool{
+	"mathrm": true, "text": true, "textrm": true, "mathit": true, "mathbf": true,
+	"mathsf": true, "boldsymbol": true, "operatorname": true,
+}
+
+// functions are the commands that apply a function to an argument, with
+// aliases mapped to the name used in the tree.
+var functions = map[string]string{
+	"sin": "sin", "cos": "cos", "tan": "tan", "cot": "cot", "sec": "sec", "csc": "csc",
+	"arcsin": "arcsin", "arccos": "arccos", "arctan": "arctan",
+	"sinh": "sinh", "cosh": "cosh", "tanh": "tanh", "coth": "coth",
+	"exp": "exp", "ln": "ln", "log": "log", "lg": "log",
+}
+
+type lexer struct {
+	input  string
+	pos    int
+	tokens []token
+	// splitDigits is the number of following numbers that LaTeX reads as a
+	// single digit, as in x^23 or \frac12.
+	splitDigits int
+}
+
+func tokenize(input string) ([]token, error) {
+	l := &lexer{input: input}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, err
+		}
+		l.tokens = append(l.tokens, tok)
+		if tok.kind == tokEOF {
+			return l.tokens, nil
+		}
+	}
+}
+
+func (l *lexer) errorf(pos int, format string, args ...interface{}) error {
+	return syntaxErrorf(pos, format, args...)
+}
+
+func (l *lexer) emit(kind tokenKind, text string, pos int) (token, error) {
+	switch {
+	case kind == tokSymbol && (text == "^" || text == "_"):
+		l.splitDigits = 1
+	case kind == tokCommand && text == "frac":
+		l.splitDigits = 2
+	case kind == tokCommand && text == "sqrt":
+		l.splitDigits = 1
+	case kind == tokNumber && l.splitDigits > 0:
+		l.splitDigits--
+	default:
+		l.splitDigits = 0
+	}
+	return token{kind: kind, text: text, pos: pos}, nil
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.input) {
+		r, size := utf8.DecodeRuneInString(l.input[l.pos:])
+		start := l.pos
+
+		switch {
+		case unicode.IsSpace(r):
+			l.pos += size
+			continue
+		case r == '\\':
+			tok, skip, err := l.command()
+			if err != nil {
+				return token{}, err
+			}
+			if skip {
+				continue
+			}
+			return tok, nil
+		case r >= '0' && r <= '9' || r == '.':
+			return l.number()
+		case unicode.IsLetter(r) || r == '∞':
+			l.pos += size
+			if v, ok := letterVariants[r]; ok {
+				r = v
+			}
+			return l.emit(tokLetter, string(r), start)
+		}
+
+		if sym, ok := symbolRunes[r]; ok {
+			l.pos += size
+			return l.emit(tokSymbol, sym, start)
+		}
+		return token{}, l.errorf(start, "unexpected %q", r)
+	}
+	return token{kind: tokEOF, pos: len(l.input)}, nil
+}
+
+func (l *lexer) number() (token, error) {
+	start := l.pos
+	if l.splitDigits > 0 && l.input[l.pos] != '.' {
+		l.pos++
+		return l.emit(tokNumber, l.input[start:l.pos], start)
+	}
+	seenDot := false
+	for l.pos < len(l.input) {
+		ch := l.input[l.pos]
+		if ch == '.' && !seenDot {
+			seenDot = true
+		} else if ch < '0' || ch > '9' {
+			break
+		}
+		l.pos++
+	}
+	text := l.input[start:l.pos]
+	if text == "." {
+		return token{}, l.errorf(start, "unexpected %q", '.')
+	}
+	return l.emit(tokNumber, text, start)
+}
+
+// command lexes a backslash sequence. skip is set for spacing and sizing
+// commands that produce no token.
+func (l *lexer) command() (tok token, skip bool, err error) {
+	start := l.pos
+	l.pos++
+	nameStart := l.pos
+	for l.pos < len(l.input) && isASCIILetter(l.input[l.pos]) {
+		l.pos++
+	}
+	name := l.input[nameStart:l.pos]
+
+	if name == "" {
+		if l.pos >= len(l.input) {
+			return token{}, false, l.errorf(start, "unexpected end after \\")
+		}
+		switch ch := l.input[l.pos]; ch {
+		case ',', ';', ':', '!', ' ':
+			l.pos++
+			return token{}, true, nil
+		case '{', '}':
+			l.pos++
+			// Escaped braces are visible brackets.
+			text := "("
+			if ch == '}' {
+				text = ")"
+			}
+			tok, err = l.emit(tokSymbol, text, start)
+			return tok, false, err
+		}
+		return token{}, false, l.errorf(start, "unsupported command \\%c", l.input[l.pos])
+	}
+
+	switch {
+	case ignoredCommands[name]:
+		if (name == "left" || name == "right") && l.pos < len(l.input) && l.input[l.pos] == '.' {
+			l.pos++
+		}
+		return token{}, true, nil
+	case greek[name] != "":
+		tok, err = l.emit(tokLetter, greek[name], start)
+		return tok, false, err
+	case operatorCommands[name] != "":
+		tok, err = l.emit(tokSymbol, operatorCommands[name], start)
+		return tok, false, err
+	case functions[name] != "":
+		tok, err = l.emit(tokCommand, functions[name], start)
+		return tok, false, err
+	case name == "frac" || name == "dfrac" || name == "tfrac":
+		tok, err = l.emit(tokCommand, "frac", start)
+		return tok, false, err
+	case name == "sqrt":
+		tok, err = l.emit(tokCommand, "sqrt", start)
+		return tok, false, err
+	case name == "lvert" || name == "rvert" || name == "vert":
+		tok, err = l.emit(tokSymbol, "|", start)
+		return tok, false, err
+	case textCommands[name]:
+		text, err := l.braced()
+		if err != nil {
+			return token{}, false, err
+		}
+		text = strings.Join(strings.Fields(text), "")
+		switch {
+		case text == "":
+			return token{}, false, l.errorf(start, "empty \\%s", name)
+		case text == "e":
+			tok, err = l.emit(tokCommand, "e", start)
+		case functions[text] != "":
+			tok, err = l.emit(tokCommand, functions[text], start)
+		default:
+			tok, err = l.emit(tokLetter, normalizeLetters(text), start)
+		}
+		return tok, false, err
+	case accents[name] != "":
+		text, err := l.braced()
+		if err != nil {
+			return token{}, false, err
+		}
+		text = strings.TrimSpace(text)
+		if strings.HasPrefix(text, `\`) && greek[text[1:]] != "" {
+			text = greek[text[1:]]
+		}
+		if utf8.RuneCountInString(text) != 1 || !unicode.IsLetter([]rune(text)[0]) {
+			return token{}, false, l.errorf(start, "\\%s takes a single letter", name)
+		}
+		tok, err = l.emit(tokLetter, normalizeLetters(text)+accents[name], start)
+		return tok, false, err
+	}
+	return token{}, false, l.errorf(start, "unsupported command \\%s", name)
+}
+
+// braced reads the raw text of a {…} argument.
+func (l *lexer) braced() (string, error) {
+	for l.pos < len(l.input) && l.input[l.pos] == ' ' {
+		l.pos++
+	}
+	if l.pos >= len(l.input) || l.input[l.pos] != '{' {
+		return "", l.errorf(l.pos, "expected {")
+	}
+	depth := 0
+	start := l.pos + 1
+	for ; l.pos < len(l.input); l.pos++ {
+		switch l.input[l.pos] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				l.pos++
+				return l.input[start : l.pos-1], nil
+			}
+		}
+	}
+	return "", l.errorf(start-1, "unclosed {")
+}
+
+func normalizeLetters(s string) string {
+	return strings.Map(func(r rune) rune {
+		if v, ok := letterVariants[r]; ok {
+			return v
+		}
+		return r
+	}, s)
+}
+
+func isASCIILetter(ch byte) bool {
+	return ch >= 'a' && ch <= 'z' || ch >= 'A' && ch <= 'Z'
+}
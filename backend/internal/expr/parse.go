@@ -0,0 +1,441 @@
+package expr
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Parse parses a formula written in the supported subset of LaTeX.
+func Parse(latex string) (*Equation, error) {
+	tokens, err := tokenize(latex)
+	if err != nil {
+		return nil, err
+	}
+	p := &parser{tokens: tokens}
+
+	right, err := p.parseSum()
+	if err != nil {
+		return nil, err
+	}
+	eq := &Equation{Right: right}
+	if p.accept("=") {
+		eq.Left = right
+		if eq.Right, err = p.parseSum(); err != nil {
+			return nil, err
+		}
+	}
+	if tok := p.peek(); tok.kind != tokEOF {
+		if tok.text == "=" {
+			return nil, syntaxErrorf(tok.pos, "a formula can contain only one =")
+		}
+		return nil, p.unexpected(tok)
+	}
+	return eq, nil
+}
+
+// MustParse is like Parse but panics on error.
+func MustParse(latex string) *Equation {
+	eq, err := Parse(latex)
+	if err != nil {
+		panic(err)
+	}
+	return eq
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+	// inAbs is set inside |…|, where a bar closes the absolute value
+	// instead of starting a new factor.
+	inAbs bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	tok := p.tokens[p.pos]
+	if tok.kind != tokEOF {
+		p.pos++
+	}
+	return tok
+}
+
+func (p *parser) isSymbol(text string) bool {
+	tok := p.peek()
+	return tok.kind == tokSymbol && tok.text == text
+}
+
+func (p *parser) accept(text string) bool {
+	if p.isSymbol(text) {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+func (p *parser) expect(text string) error {
+	if !p.accept(text) {
+		tok := p.peek()
+		if tok.kind == tokEOF {
+			return syntaxErrorf(tok.pos, "expected %s before the end", text)
+		}
+		return syntaxErrorf(tok.pos, "expected %s, found %s", text, describe(tok))
+	}
+	return nil
+}
+
+func (p *parser) unexpected(tok token) error {
+	if tok.kind == tokEOF {
+		return syntaxErrorf(tok.pos, "unexpected end of formula")
+	}
+	return syntaxErrorf(tok.pos, "unexpected %s", describe(tok))
+}
+
+func describe(tok token) string {
+	if tok.kind == tokCommand {
+		return `\` + tok.text
+	}
+	return strconv.Quote(tok.text)
+}
+
+// parseSum parses terms joined by + and -, with an optional leading sign.
+func (p *parser) parseSum() (Node, error) {
+	negate := false
+	if p.accept("-") {
+		negate = true
+	} else {
+		p.accept("+")
+	}
+	left, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+	if negate {
+		left = &Neg{X: left}
+	}
+
+	for p.isSymbol("+") || p.isSymbol("-") {
+		op := p.next().text[0]
+		right, err := p.parseTerm()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, X: left, Y: right}
+	}
+	return left, nil
+}
+
+// parseTerm parses factors joined by * and / or written side by side.
+func (p *parser) parseTerm() (Node, error) {
+	left, err := p.parsePower()
+	if err != nil {
+		return nil, err
+	}
+	for {
+		op := byte('*')
+		if p.isSymbol("*") || p.isSymbol("/") {
+			op = p.next().text[0]
+		} else if !p.startsFactor(true) {
+			return left, nil
+		}
+		right, err := p.parsePower()
+		if err != nil {
+			return nil, err
+		}
+		left = &Binary{Op: op, X: left, Y: right}
+	}
+}
+
+// startsFactor reports whether the next token can begin a factor of an
+// implicit product. Function arguments written without brackets stop at
+// the next function, so \sin x \cos y is sin(x)·cos(y).
+func (p *parser) startsFactor(functions bool) bool {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber, tokLetter:
+		return true
+	case tokCommand:
+		return functions || tok.text == "frac" || tok.text == "sqrt" || tok.text == "e"
+	case tokSymbol:
+		switch tok.text {
+		case "(", "{", "[":
+			return true
+		case "|":
+			return !p.inAbs
+		}
+	}
+	return false
+}
+
+// parsePower parses a primary with an optional superscript. A variable
+// may take its subscript after the superscript, as in v^2_0.
+func (p *parser) parsePower() (Node, error) {
+	base, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+	if !p.isSymbol("^") {
+		return base, nil
+	}
+	caret := p.next()
+	exponent, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	if v, ok := base.(*Var); ok && p.isSymbol("_") && !hasSubscript(v.Name) {
+		p.next()
+		sub, err := p.parseSubscript()
+		if err != nil {
+			return nil, err
+		}
+		base = &Var{Name: v.Name + "_" + sub}
+	}
+	if p.isSymbol("^") {
+		return nil, syntaxErrorf(caret.pos, "double superscript, use braces")
+	}
+	return &Binary{Op: '^', X: base, Y: exponent}, nil
+}
+
+// parseGroup parses a superscript or command argument: a braced
+// expression or a single token.
+func (p *parser) parseGroup() (Node, error) {
+	if p.accept("{") {
+		n, err := p.parseSum()
+		if err != nil {
+			return nil, err
+		}
+		return n, p.expect("}")
+	}
+	switch tok := p.peek(); tok.kind {
+	case tokNumber:
+		return p.parseNumber()
+	case tokLetter:
+		p.next()
+		return letterNode(tok.text), nil
+	case tokCommand:
+		if tok.text == "e" {
+			p.next()
+			return euler, nil
+		}
+	}
+	return nil, p.unexpected(p.peek())
+}
+
+func (p *parser) parsePrimary() (Node, error) {
+	tok := p.peek()
+	switch tok.kind {
+	case tokNumber:
+		return p.parseNumber()
+	case tokLetter:
+		v, err := p.parseVariable()
+		if err != nil {
+			return nil, err
+		}
+		if v.Name == "π" {
+			return pi, nil
+		}
+		return v, nil
+	case tokCommand:
+		p.next()
+		switch tok.text {
+		case "e":
+			return euler, nil
+		case "frac":
+			num, err := p.parseGroup()
+			if err != nil {
+				return nil, err
+			}
+			den, err := p.parseGroup()
+			if err != nil {
+				return nil, err
+			}
+			return &Binary{Op: '/', X: num, Y: den}, nil
+		case "sqrt":
+			return p.parseRoot()
+		}
+		return p.parseFunction(tok.text)
+	case tokSymbol:
+		switch tok.text {
+		case "(", "{", "[":
+			p.next()
+			closing := map[string]string{"(": ")", "{": "}", "[": "]"}[tok.text]
+			inAbs := p.inAbs
+			p.inAbs = false
+			n, err := p.parseSum()
+			p.inAbs = inAbs
+			if err != nil {
+				return nil, err
+			}
+			return n, p.expect(closing)
+		case "|":
+			if p.inAbs {
+				break
+			}
+			p.next()
+			p.inAbs = true
+			n, err := p.parseSum()
+			p.inAbs = false
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect("|"); err != nil {
+				return nil, err
+			}
+			return &Call{Func: "abs", X: n}, nil
+		}
+	}
+	return nil, p.unexpected(tok)
+}
+
+func (p *parser) parseNumber() (Node, error) {
+	tok := p.next()
+	value, err := strconv.ParseFloat(tok.text, 64)
+	if err != nil {
+		return nil, syntaxErrorf(tok.pos, "invalid number %q", tok.text)
+	}
+	return &Num{Value: value}, nil
+}
+
+// parseVariable parses a letter with an optional subscript.
+func (p *parser) parseVariable() (*Var, error) {
+	tok := p.next()
+	if tok.kind != tokLetter {
+		return nil, p.unexpected(tok)
+	}
+	name := tok.text
+	if p.accept("_") {
+		sub, err := p.parseSubscript()
+		if err != nil {
+			return nil, err
+		}
+		name += "_" + sub
+	}
+	return &Var{Name: name}, nil
+}
+
+// parseSubscript reads a subscript as text: E_{kin} is the variable
+// "E_kin", not E times k·i·n.
+func (p *parser) parseSubscript() (string, error) {
+	if !p.accept("{") {
+		tok := p.next()
+		if tok.kind != tokLetter && tok.kind != tokNumber {
+			return "", p.unexpected(tok)
+		}
+		return tok.text, nil
+	}
+	text := ""
+	for !p.accept("}") {
+		tok := p.next()
+		switch tok.kind {
+		case tokEOF:
+			return "", syntaxErrorf(tok.pos, "expected } before the end")
+		case tokLetter, tokNumber:
+			text += tok.text
+		case tokSymbol:
+			if tok.text != "," && tok.text != "-" && tok.text != "+" {
+				return "", p.unexpected(tok)
+			}
+			text += tok.text
+		default:
+			return "", p.unexpected(tok)
+		}
+	}
+	if text == "" {
+		return "", syntaxErrorf(p.tokens[p.pos-1].pos, "empty subscript")
+	}
+	return text, nil
+}
+
+// parseRoot parses \sqrt{x} and \sqrt[n]{x}.
+func (p *parser) parseRoot() (Node, error) {
+	var degree Node
+	if p.accept("[") {
+		var err error
+		if degree, err = p.parseSum(); err != nil {
+			return nil, err
+		}
+		if err := p.expect("]"); err != nil {
+			return nil, err
+		}
+	}
+	x, err := p.parseGroup()
+	if err != nil {
+		return nil, err
+	}
+	if degree == nil {
+		return &Call{Func: "sqrt", X: x}, nil
+	}
+	return &Binary{Op: '^', X: x, Y: &Binary{Op: '/', X: &Num{Value: 1}, Y: degree}}, nil
+}
+
+// inverses are the functions written \sin^{-1} and so on.
+var inverses = map[string]string{
+	"sin": "arcsin", "cos": "arccos", "tan": "arctan",
+}
+
+// parseFunction parses a function's argument, which is either bracketed or
+// the product of the factors up to the next operator or function. \log_b
+// takes a base, and \sin^2 x is (sin x)^2.
+func (p *parser) parseFunction(name string) (Node, error) {
+	var base, power Node
+	var err error
+	if name == "log" && p.accept("_") {
+		if base, err = p.parseGroup(); err != nil {
+			return nil, err
+		}
+	}
+	if p.accept("^") {
+		if power, err = p.parseGroup(); err != nil {
+			return nil, err
+		}
+	}
+
+	var arg Node
+	if p.isSymbol("(") || p.isSymbol("[") || p.isSymbol("{") {
+		arg, err = p.parsePrimary()
+	} else {
+		arg, err = p.parsePower()
+		for err == nil && p.startsFactor(false) {
+			var factor Node
+			if factor, err = p.parsePower(); err == nil {
+				arg = &Binary{Op: '*', X: arg, Y: factor}
+			}
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var call Node = &Call{Func: name, X: arg}
+	if base != nil {
+		call = &Binary{Op: '/', X: &Call{Func: "ln", X: arg}, Y: &Call{Func: "ln", X: base}}
+	}
+	if power == nil {
+		return call, nil
+	}
+	if isMinusOne(power) && inverses[name] != "" {
+		return &Call{Func: inverses[name], X: arg}, nil
+	}
+	return &Binary{Op: '^', X: call, Y: power}, nil
+}
+
+func letterNode(name string) Node {
+	if name == "π" {
+		return pi
+	}
+	return &Var{Name: name}
+}
+
+func hasSubscript(name string) bool {
+	return strings.Contains(name, "_")
+}
+
+func isMinusOne(n Node) bool {
+	if neg, ok := n.(*Neg); ok {
+		one, ok := neg.X.(*Num)
+		return ok && one.Value == 1
+	}
+	return false
+}
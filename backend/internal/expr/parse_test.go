@@ -0,0 +1,102 @@
+package expr
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		latex string
+		want  string
+	}{
+		{`E = \frac{1}{2} m v^2`, "E = 1/2*m*v^2"},
+		{`F = G \frac{m_1 m_2}{r^2}`, "F = G*m_1*m_2/r^2"},
+		{`x^y`, "x^y"},
+		{`\log_{y} x`, "ln(x)/ln(y)"},
+		{`|x-y|`, "abs(x - y)"},
+		{`\sqrt[3]{x}`, "x^(1/3)"},
+		{`\arctan x`, "arctan(x)"},
+		{`\sin^2 x`, "sin(x)^2"},
+		{`\sin^{-1} x`, "arcsin(x)"},
+		{`\sqrt{x}\cdot y`, "sqrt(x)*y"},
+		{`x \times y`, "x*y"},
+		{`2\pi r`, "2*π*r"},
+		{`\mathrm{e}^{-t/\tau}`, "e^(-(t/τ))"},
+		{`\varepsilon_0 E`, "ε_0*E"},
+		{`v_{0} + a t`, "v_0 + a*t"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latex, func(t *testing.T) {
+			eq, err := Parse(tt.latex)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.latex, err)
+			}
+			if got := eq.String(); got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.latex, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestParseRoundTrip checks that the plain notation of arithmetic parses back
+// to the same tree, so String puts brackets wherever precedence needs them.
+// Compound exponents are left out: LaTeX needs braces where String writes
+// brackets.
+func TestParseRoundTrip(t *testing.T) {
+	for _, latex := range []string{
+		`E = \frac{1}{2} m v^2`,
+		`a - (b - c)`,
+		`a - b + c`,
+		`\frac{a}{b c}`,
+		`\frac{a}{b} c`,
+		`(a^b)^c`,
+		`-x^2`,
+		`(-x)^2`,
+		`\frac{x + y}{x - y}`,
+		`2\pi r`,
+	} {
+		want := MustParse(latex).String()
+		again, err := Parse(want)
+		if err != nil {
+			t.Errorf("Parse(%q) of %q: %v", want, latex, err)
+			continue
+		}
+		if got := again.String(); got != want {
+			t.Errorf("%q: %q parses back as %q", latex, want, got)
+		}
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		latex   string
+		pos     int
+		message string
+	}{
+		{``, 0, "unexpected end of formula"},
+		{`x +`, 3, "unexpected end of formula"},
+		{`x^`, 2, "unexpected end of formula"},
+		{`\frac{1}{`, 9, "unexpected end of formula"},
+		{`)`, 0, `unexpected ")"`},
+		{`\sqrt[3]{}`, 9, `unexpected "}"`},
+		{`\log_{} x`, 6, `unexpected "}"`},
+		{`|x`, 2, "expected | before the end"},
+		{`a = b = c`, 6, "a formula can contain only one ="},
+		{`\foo x`, 0, `unsupported command \foo`},
+		{`2 @ 3`, 2, `unexpected '@'`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.latex, func(t *testing.T) {
+			_, err := Parse(tt.latex)
+			var syntaxErr *SyntaxError
+			if !errors.As(err, &syntaxErr) {
+				t.Fatalf("Parse(%q) error = %v, want *SyntaxError", tt.latex, err)
+			}
+			if syntaxErr.Pos != tt.pos || syntaxErr.Message != tt.message {
+				t.Errorf("Parse(%q) error = %q at %d, want %q at %d",
+					tt.latex, syntaxErr.Message, syntaxErr.Pos, tt.message, tt.pos)
+			}
+		})
+	}
+}
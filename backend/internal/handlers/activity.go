@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+var activityEntityTypes = map[string]bool{
+	"project":         true,
+	"task":            true,
+	"task_tag":        true,
+	"comment":         true,
+	"tag":             true,
+	"member":          true,
+	"board":           true,
+	"attachment":      true,
+	"workflow":        true,
+	"checklist_item":  true,
+	"task_dependency": true,
+	"task_assignee":   true,
+	"task_watcher":    true,
+	"view":            true,
+}
+
+func GetProjectActivity(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		filter := models.ActivityFilter{
+			EntityType: c.Query("entity_type"),
+			Cursor:     c.Query("cursor"),
+		}
+
+		if filter.EntityType != "" && !activityEntityTypes[filter.EntityType] {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_type"})
+			return
+		}
+		if userParam := c.Query("user_id"); userParam != "" {
+			uid, err := uuid.Parse(userParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user_id"})
+				return
+			}
+			filter.UserID = &uid
+		}
+		if fromParam := c.Query("from"); fromParam != "" {
+			from, err := parseTimeParam(fromParam, false)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid from, use RFC3339 or YYYY-MM-DD"})
+				return
+			}
+			filter.From = &from
+		}
+		if toParam := c.Query("to"); toParam != "" {
+			to, err := parseTimeParam(toParam, true)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid to, use RFC3339 or YYYY-MM-DD"})
+				return
+			}
+			filter.To = &to
+		}
+		if limitParam := c.Query("limit"); limitParam != "" {
+			limit, err := strconv.Atoi(limitParam)
+			if err != nil || limit < 1 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid limit"})
+				return
+			}
+			filter.Limit = limit
+		}
+
+		page, err := s.GetActivityEvents(projectID, filter)
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCursor) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid cursor"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get activity"})
+			return
+		}
+
+		c.JSON(http.StatusOK, page)
+	}
+}
+
+// parseTimeParam accepts RFC3339 timestamps or plain dates. A plain date used
+// as an exclusive upper bound covers the whole day.
+func parseTimeParam(value string, endOfDay bool) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	t, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return t, nil
+}
@@ -0,0 +1,151 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/config"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+var unsafeFilenameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+func ExportProject(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		role, err := s.GetMemberRole(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if role != "owner" && role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only owner or admin can export project"})
+			return
+		}
+
+		archive, err := s.ExportProject(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to export project"})
+			return
+		}
+
+		name := unsafeFilenameChars.ReplaceAllString(archive.Project.Name, "_")
+		filename := fmt.Sprintf("%s-%s.zip", name, time.Now().Format("20060102"))
+		c.Header("Content-Type", "application/zip")
+		c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+		c.Status(http.StatusOK)
+
+		// The archive is streamed, so once it has started an error can only
+		// cut it short; the client is left with a ZIP that does not open.
+		if err := services.WriteProjectArchive(c.Writer, archive); err != nil {
+			_ = c.Error(err)
+		}
+	}
+}
+
+func ImportProject(s *store.Store, cfg *config.Config) gin.HandlerFunc {
+	fileService := services.NewFileService(cfg.UploadPath, cfg.AllowedFileTypes, cfg.MaxUploadSize)
+
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file required"})
+			return
+		}
+		if fileHeader.Size > cfg.MaxImportSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "archive exceeds maximum import size"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read archive"})
+			return
+		}
+		defer file.Close()
+
+		archive, zr, err := services.ReadProjectArchive(file, fileHeader.Size)
+		if err != nil {
+			if errors.Is(err, services.ErrUnsupportedArchiveVersion) {
+				c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		conflicts := []models.ImportConflict{}
+		saved := make(map[uuid.UUID]store.ImportedFile)
+		cleanup := func() {
+			for _, f := range saved {
+				fileService.DeleteFile(f.FilePath)
+			}
+		}
+
+		for _, att := range archive.Attachments {
+			if att.ArchivePath == "" {
+				conflicts = append(conflicts, models.ImportConflict{
+					EntityType: "attachment",
+					SourceID:   att.ID,
+					Reason:     "file was missing at export time",
+				})
+				continue
+			}
+
+			src, err := services.OpenArchiveFile(zr, att.ArchivePath)
+			if err != nil {
+				conflicts = append(conflicts, models.ImportConflict{
+					EntityType: "attachment",
+					SourceID:   att.ID,
+					Reason:     "file is missing from the archive",
+				})
+				continue
+			}
+			filename, path, err := fileService.SaveReader(att.OriginalName, src)
+			src.Close()
+			if err != nil {
+				conflicts = append(conflicts, models.ImportConflict{
+					EntityType: "attachment",
+					SourceID:   att.ID,
+					Reason:     err.Error(),
+				})
+				continue
+			}
+			saved[att.ID] = store.ImportedFile{Filename: filename, FilePath: path}
+		}
+
+		report, err := s.ImportProject(archive, userID, saved)
+		if err != nil {
+			cleanup()
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import project"})
+			return
+		}
+		report.Conflicts = append(conflicts, report.Conflicts...)
+
+		c.JSON(http.StatusCreated, report)
+	}
+}
@@ -0,0 +1,378 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/notify"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetTaskAssignees(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		assignees, err := s.GetTaskAssignees(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get assignees"})
+			return
+		}
+
+		c.JSON(http.StatusOK, assignees)
+	}
+}
+
+func AddTaskAssignee(s *store.Store, n *notify.Notifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.AddTaskAssigneeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if code, err := checkAssignees(s, task.ProjectID, []uuid.UUID{req.UserID}); err != nil {
+			if code == http.StatusInternalServerError {
+				c.JSON(code, gin.H{"error": "internal server error"})
+				return
+			}
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityAdded, "task_assignee", taskID,
+			nil, gin.H{"user_id": req.UserID})
+		added, err := s.AddTaskAssignee(task, req.UserID, activity)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add assignee"})
+			return
+		}
+		if added {
+			n.TaskAssigned(task, userID, []uuid.UUID{req.UserID})
+		}
+
+		if err := s.AttachTaskDetails(task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func RemoveTaskAssignee(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		assigneeID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityRemoved, "task_assignee", taskID,
+			gin.H{"user_id": assigneeID}, nil)
+		if err := s.RemoveTaskAssignee(task, assigneeID, activity); err != nil {
+			if errors.Is(err, store.ErrAssigneeNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove assignee"})
+			return
+		}
+
+		if err := s.AttachTaskDetails(task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func GetTaskWatchers(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		watchers, err := s.GetTaskWatchers(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get watchers"})
+			return
+		}
+
+		c.JSON(http.StatusOK, watchers)
+	}
+}
+
+func AddTaskWatcher(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.AddTaskWatcherRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		watcherID := userID
+		if req.UserID != nil && *req.UserID != userID {
+			isWatcherMember, err := s.IsProjectMember(task.ProjectID, *req.UserID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if !isWatcherMember {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "watcher must be a project member"})
+				return
+			}
+			watcherID = *req.UserID
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityAdded, "task_watcher", taskID,
+			nil, gin.H{"user_id": watcherID})
+		if err := s.AddTaskWatcher(taskID, watcherID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add watcher"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "watcher added"})
+	}
+}
+
+func RemoveTaskWatcher(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		watcherID, err := uuid.Parse(c.Param("userId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityRemoved, "task_watcher", taskID,
+			gin.H{"user_id": watcherID}, nil)
+		if err := s.RemoveTaskWatcher(taskID, watcherID, activity); err != nil {
+			if errors.Is(err, store.ErrWatcherNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove watcher"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "watcher removed"})
+	}
+}
+
+// checkAssignees verifies that every user is a member of the project.
+func checkAssignees(s *store.Store, projectID uuid.UUID, userIDs []uuid.UUID) (int, error) {
+	for _, id := range userIDs {
+		isMember, err := s.IsProjectMember(projectID, id)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if !isMember {
+			return http.StatusBadRequest, fmt.Errorf("assignee %s is not a project member", id)
+		}
+	}
+	return http.StatusOK, nil
+}
+
+func uniqueUUIDs(ids []uuid.UUID) []uuid.UUID {
+	seen := make(map[uuid.UUID]bool, len(ids))
+	unique := make([]uuid.UUID, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
@@ -42,8 +42,10 @@ func UploadAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
             return
         }
 
+        var projectID *uuid.UUID
         switch entityType {
         case "project":
+            projectID = &entityID
             isMember, err := s.IsProjectMember(entityID, userID)
             if err != nil {
                 c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -63,6 +65,7 @@ func UploadAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
                 c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
                 return
             }
+            projectID = &task.ProjectID
             isMember, err := s.IsProjectMember(task.ProjectID, userID)
             if err != nil {
                 c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
@@ -86,6 +89,14 @@ func UploadAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
                 c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
                 return
             }
+            projectID = formula.ProjectID
+        case "dataset":
+            dataset, code, err := loadDataset(s, entityIDStr, userID)
+            if err != nil {
+                c.JSON(code, gin.H{"error": err.Error()})
+                return
+            }
+            projectID = &dataset.ProjectID
         default:
             c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_type"})
             return
@@ -110,7 +121,12 @@ func UploadAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
             CreatedAt:    time.Now(),
         }
 
-        if err := s.CreateAttachment(attachment); err != nil {
+        var activity *models.ActivityEvent
+        if projectID != nil {
+            activity = store.NewActivity(*projectID, userID, models.ActivityCreated, "attachment", attachment.ID, nil, attachment)
+        }
+
+        if err := s.CreateAttachment(attachment, activity); err != nil {
             fileService.DeleteFile(filepath)
             c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create attachment"})
             return
@@ -188,6 +204,11 @@ func GetAttachment(s *store.Store) gin.HandlerFunc {
                 c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
                 return
             }
+        case "dataset":
+            if _, code, err := loadDataset(s, attachment.EntityID.String(), userID); err != nil {
+                c.JSON(code, gin.H{"error": err.Error()})
+                return
+            }
         }
 
         c.JSON(http.StatusOK, attachment)
@@ -225,7 +246,17 @@ func DeleteAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
             return
         }
 
-        if err := s.DeleteAttachment(attachmentID); err != nil {
+        projectID, err := attachmentProjectID(s, attachment)
+        if err != nil {
+            c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+            return
+        }
+        var activity *models.ActivityEvent
+        if projectID != nil {
+            activity = store.NewActivity(*projectID, userID, models.ActivityDeleted, "attachment", attachment.ID, attachment, nil)
+        }
+
+        if err := s.DeleteAttachment(attachmentID, activity); err != nil {
             c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete attachment"})
             return
         }
@@ -235,3 +266,31 @@ func DeleteAttachment(s *store.Store, cfg *config.Config) gin.HandlerFunc {
         c.JSON(http.StatusOK, gin.H{"message": "attachment deleted"})
     }
 }
+
+// attachmentProjectID resolves the project an attachment belongs to, or nil for
+// attachments on personal formulas.
+func attachmentProjectID(s *store.Store, attachment *models.Attachment) (*uuid.UUID, error) {
+    switch attachment.EntityType {
+    case "project":
+        return &attachment.EntityID, nil
+    case "task":
+        task, err := s.GetTaskByID(attachment.EntityID)
+        if err != nil || task == nil {
+            return nil, err
+        }
+        return &task.ProjectID, nil
+    case "formula":
+        formula, err := s.GetFormulaByID(attachment.EntityID)
+        if err != nil || formula == nil {
+            return nil, err
+        }
+        return formula.ProjectID, nil
+    case "dataset":
+        dataset, err := s.GetDatasetByID(attachment.EntityID)
+        if err != nil || dataset == nil {
+            return nil, err
+        }
+        return &dataset.ProjectID, nil
+    }
+    return nil, nil
+}
@@ -41,6 +41,7 @@ func SignUp(s *store.Store, cfg *config.Config) gin.HandlerFunc {
             Email:     req.Email,
             Password:  hashedPassword,
             Name:      req.Name,
+            Timezone:  models.DefaultTimezone,
             CreatedAt: time.Now(),
             UpdatedAt: time.Now(),
         }
@@ -93,7 +93,8 @@ func CreateBoard(s *store.Store) gin.HandlerFunc {
 			UpdatedAt: time.Now(),
 		}
 
-		if err := s.CreateBoard(board); err != nil {
+		activity := store.NewActivity(projectID, userID, models.ActivityCreated, "board", board.ID, nil, gin.H{"name": board.Name})
+		if err := s.CreateBoard(board, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create board"})
 			return
 		}
@@ -182,8 +183,10 @@ func UpdateBoardName(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		activity := store.NewActivity(board.ProjectID, userID, models.ActivityUpdated, "board", board.ID,
+			gin.H{"name": board.Name}, gin.H{"name": req.Name})
 		board.Name = req.Name
-		if err := s.UpdateBoard(board); err != nil {
+		if err := s.UpdateBoard(board, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update board"})
 			return
 		}
@@ -226,7 +229,8 @@ func DeleteBoard(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.DeleteBoard(boardID); err != nil {
+		activity := store.NewActivity(board.ProjectID, userID, models.ActivityDeleted, "board", board.ID, gin.H{"name": board.Name}, nil)
+		if err := s.DeleteBoard(boardID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete board"})
 			return
 		}
@@ -269,7 +273,8 @@ func ClearBoardHandler(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.ClearBoard(boardID); err != nil {
+		activity := store.NewActivity(board.ProjectID, userID, models.ActivityCleared, "board", board.ID, nil, nil)
+		if err := s.ClearBoard(boardID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to clear board"})
 			return
 		}
@@ -0,0 +1,101 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itmo-pride/student-taskboard/backend/internal/config"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+// ImportCODATA takes an uploaded CODATA allascii table and returns the diff
+// against the global constants. The changes are applied only with
+// ?apply=true, so admins can review the report first.
+func ImportCODATA(s *store.Store, cfg *config.Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if user.SiteRole != models.SiteRoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file required"})
+			return
+		}
+		if fileHeader.Size > cfg.MaxUploadSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds maximum upload size"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		defer file.Close()
+
+		table, err := services.ParseCODATA(file)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if version := c.Query("version"); version != "" {
+			table.Version = version
+		}
+
+		report, err := s.ImportCODATA(table, &userID, c.Query("apply") == "true")
+		if err != nil {
+			if errors.Is(err, store.ErrSystemUserMissing) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to import CODATA table"})
+			return
+		}
+
+		c.JSON(http.StatusOK, report)
+	}
+}
+
+func GetConstantDatasets(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if !user.CanCurate() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "curator role required"})
+			return
+		}
+
+		datasets, err := s.GetConstantDatasets()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constant datasets"})
+			return
+		}
+
+		c.JSON(http.StatusOK, datasets)
+	}
+}
@@ -6,7 +6,10 @@ import (
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/markup"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/notify"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
 	"github.com/itmo-pride/student-taskboard/backend/internal/store"
 )
 
@@ -44,7 +47,7 @@ func GetComments(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		comments, err := s.GetCommentsByTaskID(taskID)
+		comments, err := s.GetCommentsByTaskID(taskID, userID)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get comments"})
 			return
@@ -54,7 +57,7 @@ func GetComments(s *store.Store) gin.HandlerFunc {
 	}
 }
 
-func CreateComment(s *store.Store) gin.HandlerFunc {
+func CreateComment(s *store.Store, n *notify.Notifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := getUserID(c)
 		if err != nil {
@@ -94,37 +97,89 @@ func CreateComment(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		comment := &models.TaskComment{
-			ID:        uuid.New(),
-			TaskID:    taskID,
-			UserID:    userID,
-			Content:   req.Content,
-			CreatedAt: time.Now(),
-			UpdatedAt: time.Now(),
+		contentHTML, err := markup.Render(req.Content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		var parent *models.TaskComment
+		if req.ParentID != nil {
+			parent, err = s.GetCommentByID(*req.ParentID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if parent == nil || parent.TaskID != taskID || parent.DeletedAt != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "parent comment not found"})
+				return
+			}
+			if parent.ParentID != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "replies cannot be nested"})
+				return
+			}
+		}
+
+		mentions, err := resolveMentions(s, task.ProjectID, req.Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve mentions"})
+			return
 		}
 
-		if err := s.CreateComment(comment); err != nil {
+		comment := &models.TaskComment{
+			ID:          uuid.New(),
+			TaskID:      taskID,
+			ParentID:    req.ParentID,
+			UserID:      userID,
+			Content:     req.Content,
+			ContentHTML: contentHTML,
+			Mentions:    mentions,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityCreated, "comment", comment.ID, nil, comment)
+		if err := s.CreateComment(comment, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create comment"})
 			return
 		}
+		notified := mentionedUserIDs(mentions)
+		n.CommentMentioned(task, comment, notified)
+		if parent != nil && !containsUUID(notified, parent.UserID) {
+			n.CommentReplied(task, comment, parent.UserID)
+			notified = append(notified, parent.UserID)
+		}
+		n.TaskCommented(task, comment, notified)
 
 		user, _ := s.GetUserByID(userID)
-		response := models.TaskCommentWithUser{
-			ID:        comment.ID,
-			TaskID:    comment.TaskID,
-			UserID:    comment.UserID,
-			Content:   comment.Content,
-			CreatedAt: comment.CreatedAt,
-			UpdatedAt: comment.UpdatedAt,
-			UserName:  user.Name,
-			UserEmail: user.Email,
-		}
-
-		c.JSON(http.StatusCreated, response)
+		c.JSON(http.StatusCreated, commentWithUser(comment, user, []models.CommentReaction{}))
+	}
+}
+
+// commentWithUser builds the response shape shared by the comment list,
+// create and update endpoints.
+func commentWithUser(comment *models.TaskComment, user *models.User, reactions []models.CommentReaction) models.TaskCommentWithUser {
+	response := models.TaskCommentWithUser{
+		ID:          comment.ID,
+		TaskID:      comment.TaskID,
+		ParentID:    comment.ParentID,
+		UserID:      comment.UserID,
+		Content:     comment.Content,
+		ContentHTML: comment.ContentHTML,
+		Mentions:    comment.Mentions,
+		Reactions:   reactions,
+		CreatedAt:   comment.CreatedAt,
+		UpdatedAt:   comment.UpdatedAt,
+		DeletedAt:   comment.DeletedAt,
+	}
+	if user != nil {
+		response.UserName = user.Name
+		response.UserEmail = user.Email
 	}
+	return response
 }
 
-func UpdateComment(s *store.Store) gin.HandlerFunc {
+func UpdateComment(s *store.Store, n *notify.Notifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := getUserID(c)
 		if err != nil {
@@ -143,7 +198,7 @@ func UpdateComment(s *store.Store) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
-		if comment == nil {
+		if comment == nil || comment.DeletedAt != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 			return
 		}
@@ -153,21 +208,67 @@ func UpdateComment(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		task, err := s.GetTaskByID(comment.TaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
 		var req models.UpdateCommentRequest
 		if err := c.ShouldBindJSON(&req); err != nil {
 			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
 			return
 		}
 
+		contentHTML, err := markup.Render(req.Content)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		previous, err := s.GetCommentMentions(commentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		mentions, err := resolveMentions(s, task.ProjectID, req.Content)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to resolve mentions"})
+			return
+		}
+
+		before := *comment
 		comment.Content = req.Content
+		comment.ContentHTML = contentHTML
+		comment.Mentions = mentions
 		comment.UpdatedAt = time.Now()
 
-		if err := s.UpdateComment(comment); err != nil {
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityUpdated, "comment", comment.ID, before, comment)
+		if err := s.UpdateComment(comment, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update comment"})
 			return
 		}
 
-		c.JSON(http.StatusOK, comment)
+		// Only users mentioned by this edit are notified.
+		notified := make(map[uuid.UUID]bool, len(previous))
+		for _, m := range previous {
+			notified[m.UserID] = true
+		}
+		var added []uuid.UUID
+		for _, id := range mentionedUserIDs(mentions) {
+			if !notified[id] {
+				added = append(added, id)
+			}
+		}
+		n.CommentMentioned(task, comment, added)
+
+		user, _ := s.GetUserByID(userID)
+		reactions, err := s.GetCommentReactions(commentID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		c.JSON(http.StatusOK, commentWithUser(comment, user, reactions))
 	}
 }
 
@@ -190,7 +291,7 @@ func DeleteComment(s *store.Store) gin.HandlerFunc {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
-		if comment == nil {
+		if comment == nil || comment.DeletedAt != nil {
 			c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
 			return
 		}
@@ -213,7 +314,8 @@ func DeleteComment(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.DeleteComment(commentID); err != nil {
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityDeleted, "comment", comment.ID, comment, nil)
+		if err := s.DeleteComment(comment, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete comment"})
 			return
 		}
@@ -221,3 +323,100 @@ func DeleteComment(s *store.Store) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "comment deleted"})
 	}
 }
+
+// ToggleCommentReaction adds the current user's reaction to a comment or
+// takes it back, and returns the comment's reactions.
+func ToggleCommentReaction(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		commentID, err := uuid.Parse(c.Param("commentId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid comment id"})
+			return
+		}
+
+		comment, err := s.GetCommentByID(commentID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if comment == nil || comment.DeletedAt != nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "comment not found"})
+			return
+		}
+
+		task, err := s.GetTaskByID(comment.TaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.ToggleReactionRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if err := models.ValidateReaction(req.Emoji); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if _, err := s.ToggleCommentReaction(commentID, userID, req.Emoji); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to toggle reaction"})
+			return
+		}
+
+		reactions, err := s.GetCommentReactions(commentID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get reactions"})
+			return
+		}
+
+		c.JSON(http.StatusOK, reactions)
+	}
+}
+
+// resolveMentions finds the project members mentioned in comment content.
+func resolveMentions(s *store.Store, projectID uuid.UUID, content string) ([]models.CommentMention, error) {
+	members, err := s.GetProjectMembers(projectID)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]models.User, len(members))
+	for i, m := range members {
+		users[i] = models.User{ID: m.UserID, Name: m.UserName, Email: m.UserEmail}
+	}
+	return services.ParseMentions(content, users), nil
+}
+
+func mentionedUserIDs(mentions []models.CommentMention) []uuid.UUID {
+	ids := make([]uuid.UUID, len(mentions))
+	for i, m := range mentions {
+		ids[i] = m.UserID
+	}
+	return uniqueUUIDs(ids)
+}
+
+func containsUUID(ids []uuid.UUID, id uuid.UUID) bool {
+	for _, candidate := range ids {
+		if candidate == id {
+			return true
+		}
+	}
+	return false
+}
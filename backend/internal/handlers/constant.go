@@ -1,13 +1,16 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
 	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
 )
 
 func GetConstants(s *store.Store) gin.HandlerFunc {
@@ -19,10 +22,13 @@ func GetConstants(s *store.Store) gin.HandlerFunc {
 		}
 
 		scope := c.Query("scope")
-		projectIDStr := c.Query("project_id")
+		if scope != "" && !models.ValidConstantScope(scope) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scope"})
+			return
+		}
 
 		var projectID *uuid.UUID
-		if projectIDStr != "" {
+		if projectIDStr := c.Query("project_id"); projectIDStr != "" {
 			pid, err := uuid.Parse(projectIDStr)
 			if err != nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
@@ -30,8 +36,31 @@ func GetConstants(s *store.Store) gin.HandlerFunc {
 			}
 			projectID = &pid
 		}
+		if scope == models.ConstantScopeProject && projectID == nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "project_id required for project scope"})
+			return
+		}
+
+		if projectID != nil {
+			isMember, err := s.IsProjectMember(*projectID, userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if !isMember {
+				c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+				return
+			}
+		}
 
-		constants, err := s.GetConstants(userID, projectID, scope)
+		// Without a scope the layers are merged, so each symbol resolves to
+		// the constant that applies to this user in this project.
+		var constants []models.Constant
+		if scope == "" {
+			constants, err = s.GetEffectiveConstants(userID, projectID)
+		} else {
+			constants, err = s.GetConstants(userID, projectID, scope)
+		}
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constants"})
 			return
@@ -55,11 +84,17 @@ func CreateConstant(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if req.Scope != "user" && req.Scope != "project" && req.Scope != "global" {
+		if !models.ValidConstantScope(req.Scope) {
 			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid scope"})
 			return
 		}
 
+		constant := &models.Constant{}
+		if err := parseConstantValue(constant, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		if req.Scope == "project" {
 			if req.ScopeID == nil {
 				c.JSON(http.StatusBadRequest, gin.H{"error": "scope_id required for project scope"})
@@ -76,21 +111,32 @@ func CreateConstant(s *store.Store) gin.HandlerFunc {
 			}
 		}
 
-		constant := &models.Constant{
-			ID:          uuid.New(),
-			Name:        req.Name,
-			Symbol:      req.Symbol,
-			Value:       req.Value,
-			Unit:        req.Unit,
-			Description: req.Description,
-			Scope:       req.Scope,
-			ScopeID:     req.ScopeID,
-			CreatedBy:   userID,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+		constant.ID = uuid.New()
+		constant.Name = req.Name
+		constant.Symbol = req.Symbol
+		constant.Description = req.Description
+		constant.Scope = req.Scope
+		constant.ScopeID = req.ScopeID
+		constant.CreatedBy = userID
+		constant.CreatedAt = time.Now()
+		constant.UpdatedAt = time.Now()
+		constant.ReviewStatus = models.ReviewApproved
+
+		// Global constants are visible to everyone, so only curators publish
+		// them directly; other users' proposals wait for review.
+		var event *models.ModerationEvent
+		if req.Scope == models.ConstantScopeGlobal {
+			user, code, err := loadSiteUser(s, userID)
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			status, action := reviewForCreate(user)
+			constant.ReviewStatus = status
+			event = store.NewModerationEvent(userID, action, models.ModerationConstant, constant.ID, "", constant)
 		}
 
-		if err := s.CreateConstant(constant); err != nil {
+		if err := s.CreateConstant(constant, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create constant"})
 			return
 		}
@@ -123,19 +169,21 @@ func GetConstant(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if constant.Scope == "project" && constant.ScopeID != nil {
-			isMember, err := s.IsProjectMember(*constant.ScopeID, userID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-				return
-			}
-			if !isMember {
-				c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		if code, err := checkConstantAccess(s, userID, constant); err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if unit := c.Query("unit"); unit != "" {
+			if err := convertConstant(constant, unit); err != nil {
+				var unitErr *units.Error
+				if errors.As(err, &unitErr) {
+					c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+					return
+				}
+				writeConversionError(c, err)
 				return
 			}
-		} else if constant.Scope == "user" && constant.CreatedBy != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-			return
 		}
 
 		c.JSON(http.StatusOK, constant)
@@ -166,7 +214,25 @@ func UpdateConstant(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if constant.CreatedBy != userID {
+		action := ""
+		if constant.Scope == models.ConstantScopeGlobal {
+			user, code, err := loadSiteUser(s, userID)
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			if !canSeeReviewed(user, constant.CreatedBy, constant.Review) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "constant not found"})
+				return
+			}
+			status, editAction, ok := reviewForEdit(user, constant.CreatedBy, constant.Review)
+			if !ok {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only curators can change published global constants"})
+				return
+			}
+			constant.ReviewStatus = status
+			action = editAction
+		} else if constant.CreatedBy != userID {
 			c.JSON(http.StatusForbidden, gin.H{"error": "only creator can update constant"})
 			return
 		}
@@ -177,14 +243,21 @@ func UpdateConstant(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		if err := parseConstantValue(constant, &req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
 		constant.Name = req.Name
 		constant.Symbol = req.Symbol
-		constant.Value = req.Value
-		constant.Unit = req.Unit
 		constant.Description = req.Description
 		constant.UpdatedAt = time.Now()
 
-		if err := s.UpdateConstant(constant); err != nil {
+		var event *models.ModerationEvent
+		if action != "" {
+			event = store.NewModerationEvent(userID, action, models.ModerationConstant, constant.ID, "", constant)
+		}
+
+		if err := s.UpdateConstant(constant, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update constant"})
 			return
 		}
@@ -217,22 +290,39 @@ func DeleteConstant(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
 		isSystem, err := s.IsSystemUser(constant.CreatedBy)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
-		if isSystem {
+		if isSystem && user.SiteRole != models.SiteRoleAdmin {
 			c.JSON(http.StatusForbidden, gin.H{"error": "cannot delete system constants"})
 			return
 		}
 
-		if constant.CreatedBy != userID {
+		var event *models.ModerationEvent
+		if constant.Scope == models.ConstantScopeGlobal {
+			if !canSeeReviewed(user, constant.CreatedBy, constant.Review) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "constant not found"})
+				return
+			}
+			if !canDeleteGlobal(user, constant.CreatedBy, constant.Review) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only curators can delete published global constants"})
+				return
+			}
+			event = store.NewModerationEvent(userID, models.ModerationDeleted, models.ModerationConstant, constant.ID, "", constant)
+		} else if constant.CreatedBy != userID {
 			c.JSON(http.StatusForbidden, gin.H{"error": "only creator can delete constant"})
 			return
 		}
 
-		if err := s.DeleteConstant(constantID); err != nil {
+		if err := s.DeleteConstant(constantID, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete constant"})
 			return
 		}
@@ -240,3 +330,67 @@ func DeleteConstant(s *store.Store) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "constant deleted"})
 	}
 }
+
+// checkConstantAccess reports whether the user may read the constant:
+// project constants need membership, personal ones are private, and
+// unapproved global ones are hidden from all but their author and curators.
+func checkConstantAccess(s *store.Store, userID uuid.UUID, constant *models.Constant) (int, error) {
+	if constant.Scope == "project" && constant.ScopeID != nil {
+		isMember, err := s.IsProjectMember(*constant.ScopeID, userID)
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("internal server error")
+		}
+		if !isMember {
+			return http.StatusForbidden, errors.New("access denied")
+		}
+	} else if constant.Scope == "user" && constant.CreatedBy != userID {
+		return http.StatusForbidden, errors.New("access denied")
+	} else if constant.ReviewStatus != models.ReviewApproved {
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			return code, err
+		}
+		if !canSeeReviewed(user, constant.CreatedBy, constant.Review) {
+			return http.StatusNotFound, errors.New("constant not found")
+		}
+	}
+	return http.StatusOK, nil
+}
+
+// parseConstantValue sets the value and unit of constant from req, parsing
+// them into a number, its uncertainty and the unit's scale and dimension.
+func parseConstantValue(constant *models.Constant, req *models.CreateConstantRequest) error {
+	value, uncertainty, err := units.ParseValue(req.Value)
+	if err != nil {
+		return err
+	}
+	if req.Uncertainty != nil {
+		if uncertainty != 0 {
+			return errors.New("uncertainty is given both in the value and separately")
+		}
+		uncertainty = *req.Uncertainty
+	}
+	if req.Exact && uncertainty != 0 {
+		return errors.New("an exact constant cannot have an uncertainty")
+	}
+
+	unit, err := units.Parse(req.Unit)
+	if err != nil {
+		return err
+	}
+	if unit.Offset != 0 {
+		return errors.New("constants cannot use temperature scales with an offset, use K")
+	}
+
+	constant.Value = strings.TrimSpace(req.Value)
+	constant.Unit = strings.TrimSpace(req.Unit)
+	constant.NumericValue = &value
+	constant.Uncertainty = nil
+	if uncertainty != 0 {
+		constant.Uncertainty = &uncertainty
+	}
+	constant.Exact = req.Exact
+	constant.UnitScale = &unit.Scale
+	constant.Dimensions = &unit.Dim
+	return nil
+}
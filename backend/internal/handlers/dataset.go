@@ -0,0 +1,1310 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/config"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+func GetDatasets(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		datasets, err := s.GetDatasetsByProject(projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get datasets"})
+			return
+		}
+
+		c.JSON(http.StatusOK, datasets)
+	}
+}
+
+func CreateDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.CreateDatasetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+
+		dataset := &models.Dataset{
+			ID:          uuid.New(),
+			ProjectID:   projectID,
+			Name:        name,
+			Description: req.Description,
+			CreatedBy:   &userID,
+			CreatedAt:   time.Now(),
+			UpdatedAt:   time.Now(),
+			Columns:     []models.DatasetColumn{},
+		}
+		seen := map[string]bool{}
+		for i, colReq := range req.Columns {
+			col, err := newDatasetColumn(dataset.ID, colReq)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if seen[col.Name] {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "duplicate column " + col.Name})
+				return
+			}
+			seen[col.Name] = true
+			col.Position = i
+			dataset.Columns = append(dataset.Columns, *col)
+		}
+
+		activity := store.NewActivity(projectID, userID, models.ActivityCreated, "dataset", dataset.ID, nil, dataset)
+		if err := s.CreateDataset(dataset, nil, nil, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create dataset"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, dataset)
+	}
+}
+
+// ImportDataset creates a dataset from an uploaded CSV or TSV file. The file
+// goes through the same checks as other uploads and is kept as an
+// attachment of the dataset.
+func ImportDataset(s *store.Store, cfg *config.Config) gin.HandlerFunc {
+	fileService := services.NewFileService(cfg.UploadPath, cfg.AllowedFileTypes, cfg.MaxUploadSize)
+
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		fileHeader, err := c.FormFile("file")
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "file required"})
+			return
+		}
+		if fileHeader.Size > cfg.MaxUploadSize {
+			c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "file exceeds maximum upload size"})
+			return
+		}
+
+		file, err := fileHeader.Open()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "failed to read file"})
+			return
+		}
+		table, err := services.ParseDatasetTable(file, fileHeader.Filename)
+		file.Close()
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := strings.TrimSpace(c.PostForm("name"))
+		if name == "" {
+			name = strings.TrimSuffix(fileHeader.Filename, filepath.Ext(fileHeader.Filename))
+		}
+		if runes := []rune(name); len(runes) > 255 {
+			name = string(runes[:255])
+		}
+
+		now := time.Now()
+		dataset := &models.Dataset{
+			ID:          uuid.New(),
+			ProjectID:   projectID,
+			Name:        name,
+			Description: c.PostForm("description"),
+			CreatedBy:   &userID,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+			Columns:     table.Columns,
+		}
+		for i := range dataset.Columns {
+			dataset.Columns[i].DatasetID = dataset.ID
+		}
+		rows := make([]models.DatasetRow, len(table.Rows))
+		for i, cells := range table.Rows {
+			rows[i] = models.DatasetRow{
+				ID:        uuid.New(),
+				DatasetID: dataset.ID,
+				Position:  i,
+				Cells:     cells,
+				CreatedAt: now,
+				UpdatedAt: now,
+			}
+		}
+
+		filename, path, err := fileService.SaveFile(fileHeader)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		attachment := &models.Attachment{
+			ID:           uuid.New(),
+			Filename:     filename,
+			OriginalName: fileHeader.Filename,
+			FilePath:     path,
+			FileSize:     fileHeader.Size,
+			MimeType:     fileHeader.Header.Get("Content-Type"),
+			EntityType:   "dataset",
+			EntityID:     dataset.ID,
+			UploadedBy:   userID,
+			CreatedAt:    now,
+		}
+
+		activity := store.NewActivity(projectID, userID, models.ActivityCreated, "dataset", dataset.ID, nil, dataset)
+		if err := s.CreateDataset(dataset, rows, attachment, activity); err != nil {
+			fileService.DeleteFile(path)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create dataset"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, models.DatasetImport{Dataset: *dataset, Attachment: attachment})
+	}
+}
+
+func GetDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.LoadDatasetDetails(dataset); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dataset"})
+			return
+		}
+
+		c.JSON(http.StatusOK, dataset)
+	}
+}
+
+func UpdateDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.UpdateDatasetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *dataset
+		if name := strings.TrimSpace(req.Name); name != "" {
+			dataset.Name = name
+		}
+		if req.Description != nil {
+			dataset.Description = *req.Description
+		}
+		dataset.UpdatedAt = time.Now()
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityUpdated, "dataset", dataset.ID, &before, dataset)
+		if err := s.UpdateDataset(dataset, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update dataset"})
+			return
+		}
+
+		c.JSON(http.StatusOK, dataset)
+	}
+}
+
+func DeleteDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if dataset.CreatedBy == nil || *dataset.CreatedBy != userID {
+			role, err := s.GetMemberRole(dataset.ProjectID, userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if role != "owner" && role != "admin" {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only dataset creator, admin or owner can delete datasets"})
+				return
+			}
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityDeleted, "dataset", dataset.ID, dataset, nil)
+		if err := s.DeleteDataset(dataset.ID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete dataset"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "dataset deleted"})
+	}
+}
+
+func CreateDatasetColumn(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.DatasetColumnRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		col, err := newDatasetColumn(dataset.ID, req)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityCreated, "dataset_column", col.ID, nil, col)
+		if err := s.CreateDatasetColumn(col, activity); err != nil {
+			if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
+				c.JSON(http.StatusConflict, gin.H{"error": "column with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create column"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, col)
+	}
+}
+
+func UpdateDatasetColumn(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		col, code, err := loadDatasetColumn(s, dataset, c.Param("columnId"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.UpdateDatasetColumnRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *col
+		if name := strings.TrimSpace(req.Name); name != "" {
+			col.Name = name
+		}
+		if req.Position != nil {
+			col.Position = *req.Position
+		}
+		if req.Uncertainty != nil {
+			col.Uncertainty = req.Uncertainty
+			if *req.Uncertainty == 0 {
+				col.Uncertainty = nil
+			}
+		}
+		if col.Kind == models.ColumnText && (col.Uncertainty != nil || (req.Unit != nil && *req.Unit != "")) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "text columns have no unit or uncertainty"})
+			return
+		}
+
+		// A new unit converts the column's numbers, so they keep measuring
+		// the same thing.
+		var converted []models.DatasetRow
+		if req.Unit != nil && strings.TrimSpace(*req.Unit) != col.Unit {
+			from, err := units.Parse(col.Unit)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			to, err := units.Parse(*req.Unit)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			if from.Dim != to.Dim {
+				c.JSON(http.StatusBadRequest, gin.H{"error": (&units.IncompatibleError{From: from.Dim, To: to.Dim}).Error()})
+				return
+			}
+
+			rows, err := s.GetDatasetRows(dataset.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rows"})
+				return
+			}
+			for _, row := range rows {
+				cell, ok := row.Cells[col.ID]
+				if !ok || cell.Value == nil {
+					continue
+				}
+				row.Cells[col.ID] = convertCell(cell, from, to)
+				converted = append(converted, row)
+			}
+			if col.Uncertainty != nil {
+				sigma, _ := units.ConvertUncertainty(*col.Uncertainty, from, to)
+				col.Uncertainty = &sigma
+			}
+			col.Unit = strings.TrimSpace(*req.Unit)
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityUpdated, "dataset_column", col.ID, &before, col)
+		if err := s.UpdateDatasetColumn(col, converted, activity); err != nil {
+			if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
+				c.JSON(http.StatusConflict, gin.H{"error": "column with this name already exists"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update column"})
+			return
+		}
+
+		c.JSON(http.StatusOK, col)
+	}
+}
+
+func DeleteDatasetColumn(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		col, code, err := loadDatasetColumn(s, dataset, c.Param("columnId"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityDeleted, "dataset_column", col.ID, col, nil)
+		if err := s.DeleteDatasetColumn(col, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete column"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "column deleted"})
+	}
+}
+
+func GetDatasetRows(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		rows, err := s.GetDatasetRows(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rows"})
+			return
+		}
+
+		c.JSON(http.StatusOK, rows)
+	}
+}
+
+func CreateDatasetRow(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.DatasetRowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		columns, err := s.GetDatasetColumns(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get columns"})
+			return
+		}
+		cells, err := applyDatasetCells(columns, models.DatasetCells{}, req.Cells)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		row := &models.DatasetRow{
+			ID:        uuid.New(),
+			DatasetID: dataset.ID,
+			Cells:     cells,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+		if err := s.CreateDatasetRow(row, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create row"})
+			return
+		}
+		if req.Position != nil && *req.Position < row.Position {
+			row.Position = *req.Position
+			if err := s.UpdateDatasetRow(row, nil); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move row"})
+				return
+			}
+		}
+
+		c.JSON(http.StatusCreated, row)
+	}
+}
+
+func UpdateDatasetRow(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		row, code, err := loadDatasetRow(s, dataset, c.Param("rowId"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.DatasetRowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		columns, err := s.GetDatasetColumns(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get columns"})
+			return
+		}
+		cells, err := applyDatasetCells(columns, row.Cells, req.Cells)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		row.Cells = cells
+		if req.Position != nil {
+			row.Position = *req.Position
+		}
+		row.UpdatedAt = time.Now()
+
+		if err := s.UpdateDatasetRow(row, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update row"})
+			return
+		}
+
+		c.JSON(http.StatusOK, row)
+	}
+}
+
+func DeleteDatasetRow(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		row, code, err := loadDatasetRow(s, dataset, c.Param("rowId"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.DeleteDatasetRow(row.ID, nil); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete row"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "row deleted"})
+	}
+}
+
+func GetDatasetStats(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		columns, err := s.GetDatasetColumns(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get columns"})
+			return
+		}
+		rows, err := s.GetDatasetRows(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rows"})
+			return
+		}
+
+		c.JSON(http.StatusOK, services.DatasetStats(columns, rows))
+	}
+}
+
+// EvaluateDataset evaluates a formula for every row of a dataset and stores
+// the results in a derived column.
+func EvaluateDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.EvaluateDatasetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		name := strings.TrimSpace(req.Column)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "column cannot be empty"})
+			return
+		}
+
+		formula, code, err := loadReadableFormula(s, userID, req.FormulaID.String())
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		scope, code, err := evaluationScope(s, userID, formula, &dataset.ProjectID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		columns, err := s.GetDatasetColumns(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get columns"})
+			return
+		}
+		rows, err := s.GetDatasetRows(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rows"})
+			return
+		}
+
+		// Measured columns are never overwritten; derived ones are
+		// re-evaluated in place.
+		col := &models.DatasetColumn{ID: uuid.New(), DatasetID: dataset.ID, Name: name}
+		create := true
+		for _, existing := range columns {
+			if existing.Name != name {
+				continue
+			}
+			if existing.FormulaID == nil {
+				c.JSON(http.StatusConflict, gin.H{"error": "column " + name + " holds measurements"})
+				return
+			}
+			existing := existing
+			col, create = &existing, false
+		}
+
+		derived, err := services.EvaluateDataset(formula.Latex, scope, columns, rows, req.Variables, req.Unit, col.ID)
+		if err != nil {
+			var columnErr *services.DatasetColumnError
+			if errors.As(err, &columnErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			writeEvaluationError(c, err)
+			return
+		}
+
+		before := *col
+		col.Kind = models.ColumnNumber
+		col.Unit = derived.Unit
+		col.Uncertainty = nil
+		col.FormulaID = &formula.ID
+
+		action, b := models.ActivityUpdated, interface{}(&before)
+		if create {
+			action, b = models.ActivityCreated, nil
+		}
+		activity := store.NewActivity(dataset.ProjectID, userID, action, "dataset_column", col.ID, b, col)
+		if err := s.SaveDerivedColumn(col, derived.Cells, create, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save column"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.EvaluateDatasetResponse{
+			Column:    *col,
+			Evaluated: len(derived.Cells),
+			Errors:    derived.Errors,
+		})
+	}
+}
+
+// FitDataset fits a model to two columns of a dataset and, if asked,
+// saves the fit as a project formula with its parameters as constants.
+func FitDataset(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.FitDatasetRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.Model == models.FitPolynomial && req.Degree == 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "degree required for a polynomial fit"})
+			return
+		}
+		hasFormula := req.FormulaID != nil || strings.TrimSpace(req.Latex) != ""
+		if req.Model == models.FitFormula && (req.FormulaID == nil) == (strings.TrimSpace(req.Latex) == "") {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "either formula_id or latex required for a formula fit"})
+			return
+		}
+		if req.Model != models.FitFormula && hasFormula {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "only formula fits take a formula"})
+			return
+		}
+
+		// Built-in models use no constants; formulas see the dataset's
+		// project.
+		var source *models.Formula
+		latex := req.Latex
+		scope := services.NewFormulaScope(nil, nil, nil)
+		if req.FormulaID != nil {
+			source, code, err = loadReadableFormula(s, userID, req.FormulaID.String())
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			scope, code, err = evaluationScope(s, userID, source, &dataset.ProjectID)
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			latex = source.Latex
+		} else if req.Model == models.FitFormula {
+			scope, err = formulaScope(s, userID, &dataset.ProjectID, nil, nil)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constants"})
+				return
+			}
+		}
+
+		columns, err := s.GetDatasetColumns(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get columns"})
+			return
+		}
+		rows, err := s.GetDatasetRows(dataset.ID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get rows"})
+			return
+		}
+
+		resp, err := services.FitDataset(req, latex, scope, columns, rows)
+		if err != nil {
+			var columnErr *services.DatasetColumnError
+			var paramErr *services.FitParameterError
+			if errors.As(err, &columnErr) || errors.As(err, &paramErr) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			writeEvaluationError(c, err)
+			return
+		}
+
+		if req.Save != nil {
+			if code, err := saveDatasetFit(s, userID, dataset, req, source, columns, resp); err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func GetTaskDatasets(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		datasets, err := s.GetDatasetsByTask(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get datasets"})
+			return
+		}
+
+		c.JSON(http.StatusOK, datasets)
+	}
+}
+
+func LinkDatasetTask(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.LinkDatasetTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		task, err := s.GetTaskByID(req.TaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil || task.ProjectID != dataset.ProjectID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "task not found in dataset project"})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityAdded, "dataset_task", dataset.ID,
+			nil, gin.H{"task_id": task.ID})
+		if err := s.AddDatasetTask(dataset.ID, task.ID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link task"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "task linked"})
+	}
+}
+
+func UnlinkDatasetTask(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("taskId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityRemoved, "dataset_task", dataset.ID,
+			gin.H{"task_id": taskID}, nil)
+		if err := s.RemoveDatasetTask(dataset.ID, taskID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlink task"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "task unlinked"})
+	}
+}
+
+func LinkDatasetFormula(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.LinkDatasetFormulaRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		formula, code, err := loadReadableFormula(s, userID, req.FormulaID.String())
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityAdded, "dataset_formula", dataset.ID,
+			nil, gin.H{"formula_id": formula.ID})
+		if err := s.AddDatasetFormula(dataset.ID, formula.ID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to link formula"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "formula linked"})
+	}
+}
+
+func UnlinkDatasetFormula(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		dataset, code, err := loadDataset(s, c.Param("id"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		formulaID, err := uuid.Parse(c.Param("formulaId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid formula id"})
+			return
+		}
+
+		activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityRemoved, "dataset_formula", dataset.ID,
+			gin.H{"formula_id": formulaID}, nil)
+		if err := s.RemoveDatasetFormula(dataset.ID, formulaID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to unlink formula"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "formula unlinked"})
+	}
+}
+
+// saveDatasetFit saves a fit as a project formula. Each parameter becomes
+// a project constant with the fitted value and uncertainty that the
+// formula's variable is linked to; the argument and the fitted variable
+// are declared in their columns' units, and the other variables of a
+// source formula keep their declarations.
+func saveDatasetFit(s *store.Store, userID uuid.UUID, dataset *models.Dataset, req models.FitDatasetRequest, source *models.Formula, columns []models.DatasetColumn, resp *models.FitDatasetResponse) (int, error) {
+	columnUnits := map[string]string{}
+	for _, col := range columns {
+		if col.Name == req.X {
+			columnUnits[resp.Argument] = col.Unit
+		}
+		if col.Name == req.Y && resp.Symbol != "" {
+			columnUnits[resp.Symbol] = col.Unit
+		}
+	}
+
+	now := time.Now()
+	description := fmt.Sprintf("Fitted to dataset %s (χ²/ndf = %.4g)", dataset.Name, resp.ReducedChiSquare)
+	fitted := map[string]bool{}
+	var variables []models.FormulaVariable
+	var constants []models.Constant
+	for _, param := range resp.Parameters {
+		fitted[param.Symbol] = true
+		creq := models.CreateConstantRequest{
+			Name:        req.Save.Title + ": " + param.Symbol,
+			Symbol:      param.Symbol,
+			Value:       strconv.FormatFloat(param.Value, 'g', -1, 64),
+			Unit:        param.Unit,
+			Description: description,
+			Scope:       models.ConstantScopeProject,
+			ScopeID:     &dataset.ProjectID,
+		}
+		if param.Uncertainty > 0 {
+			sigma := param.Uncertainty
+			creq.Uncertainty = &sigma
+		}
+		constant := models.Constant{}
+		if err := parseConstantValue(&constant, &creq); err != nil {
+			return http.StatusUnprocessableEntity, fmt.Errorf("cannot save %s: %w", param.Symbol, err)
+		}
+		constant.ID = uuid.New()
+		constant.Name = creq.Name
+		constant.Symbol = creq.Symbol
+		constant.Description = creq.Description
+		constant.Scope = creq.Scope
+		constant.ScopeID = creq.ScopeID
+		constant.CreatedBy = userID
+		constant.CreatedAt = now
+		constant.UpdatedAt = now
+		constant.ReviewStatus = models.ReviewApproved
+		constants = append(constants, constant)
+
+		id := constant.ID
+		variables = append(variables, models.FormulaVariable{Symbol: param.Symbol, ConstantID: &id})
+	}
+	for symbol, unit := range columnUnits {
+		variables = append(variables, models.FormulaVariable{Symbol: symbol, Unit: unit})
+	}
+	if source != nil {
+		declared, err := s.GetFormulaVariables(source.ID)
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("failed to get formula variables")
+		}
+		for _, v := range declared {
+			if _, ok := columnUnits[v.Symbol]; !ok && !fitted[v.Symbol] {
+				variables = append(variables, v)
+			}
+		}
+	}
+	sort.Slice(variables, func(i, j int) bool { return variables[i].Symbol < variables[j].Symbol })
+
+	formula := &models.Formula{
+		ID:          uuid.New(),
+		Title:       req.Save.Title,
+		Latex:       resp.Expression,
+		Description: req.Save.Description,
+		ProjectID:   &dataset.ProjectID,
+		CreatedBy:   userID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+		Review:      models.Review{ReviewStatus: models.ReviewApproved},
+		Variables:   variables,
+	}
+	if formula.Description == "" {
+		formula.Description = description
+	}
+	scope, err := formulaScope(s, userID, &dataset.ProjectID, variables, constants)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("failed to get constants")
+	}
+	formula.Check = services.CheckFormula(formula.Latex, scope)
+
+	activity := store.NewActivity(dataset.ProjectID, userID, models.ActivityAdded, "dataset_formula", dataset.ID,
+		nil, gin.H{"formula_id": formula.ID})
+	if err := s.SaveDatasetFit(dataset.ID, formula, constants, activity); err != nil {
+		return http.StatusInternalServerError, errors.New("failed to save fit")
+	}
+	resp.Formula = formula
+	resp.Constants = constants
+	return http.StatusOK, nil
+}
+
+// loadDataset fetches a dataset of a project the user is a member of.
+func loadDataset(s *store.Store, rawID string, userID uuid.UUID) (*models.Dataset, int, error) {
+	datasetID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid dataset id")
+	}
+
+	dataset, err := s.GetDatasetByID(datasetID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if dataset == nil {
+		return nil, http.StatusNotFound, errors.New("dataset not found")
+	}
+
+	isMember, err := s.IsProjectMember(dataset.ProjectID, userID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if !isMember {
+		return nil, http.StatusForbidden, errors.New("access denied")
+	}
+	return dataset, http.StatusOK, nil
+}
+
+func loadDatasetColumn(s *store.Store, dataset *models.Dataset, rawID string) (*models.DatasetColumn, int, error) {
+	columnID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid column id")
+	}
+	col, err := s.GetDatasetColumnByID(columnID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if col == nil || col.DatasetID != dataset.ID {
+		return nil, http.StatusNotFound, errors.New("column not found")
+	}
+	return col, http.StatusOK, nil
+}
+
+func loadDatasetRow(s *store.Store, dataset *models.Dataset, rawID string) (*models.DatasetRow, int, error) {
+	rowID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid row id")
+	}
+	row, err := s.GetDatasetRowByID(rowID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if row == nil || row.DatasetID != dataset.ID {
+		return nil, http.StatusNotFound, errors.New("row not found")
+	}
+	return row, http.StatusOK, nil
+}
+
+// newDatasetColumn validates a column request. Numeric columns need a unit
+// the unit parser understands; text columns take neither unit nor
+// uncertainty.
+func newDatasetColumn(datasetID uuid.UUID, req models.DatasetColumnRequest) (*models.DatasetColumn, error) {
+	col := &models.DatasetColumn{
+		ID:        uuid.New(),
+		DatasetID: datasetID,
+		Name:      strings.TrimSpace(req.Name),
+		Kind:      req.Kind,
+		Unit:      strings.TrimSpace(req.Unit),
+	}
+	if col.Name == "" {
+		return nil, errors.New("column name cannot be empty")
+	}
+	if col.Kind == "" {
+		col.Kind = models.ColumnNumber
+	}
+	if req.Uncertainty != nil && *req.Uncertainty > 0 {
+		col.Uncertainty = req.Uncertainty
+	}
+	if col.Kind == models.ColumnText {
+		if col.Unit != "" || col.Uncertainty != nil {
+			return nil, errors.New("text columns have no unit or uncertainty")
+		}
+		return col, nil
+	}
+	if _, err := units.Parse(col.Unit); err != nil {
+		return nil, err
+	}
+	return col, nil
+}
+
+// applyDatasetCells returns cells with the given changes, which are keyed
+// by column id or name. A change without value or text clears the cell.
+func applyDatasetCells(columns []models.DatasetColumn, cells models.DatasetCells, changes map[string]models.DatasetCell) (models.DatasetCells, error) {
+	byKey := make(map[string]models.DatasetColumn, 2*len(columns))
+	for _, col := range columns {
+		byKey[col.Name] = col
+		byKey[col.ID.String()] = col
+	}
+
+	out := make(models.DatasetCells, len(cells)+len(changes))
+	for id, cell := range cells {
+		out[id] = cell
+	}
+	for key, cell := range changes {
+		col, ok := byKey[key]
+		if !ok {
+			return nil, errors.New("unknown column " + key)
+		}
+		if cell.Value == nil && cell.Text == "" {
+			delete(out, col.ID)
+			continue
+		}
+		switch col.Kind {
+		case models.ColumnText:
+			if cell.Value != nil || cell.Uncertainty != nil {
+				return nil, errors.New(col.Name + " holds text")
+			}
+		default:
+			if cell.Value == nil {
+				return nil, errors.New(col.Name + " holds numbers")
+			}
+			if cell.Uncertainty != nil && *cell.Uncertainty < 0 {
+				return nil, errors.New(col.Name + ": uncertainty cannot be negative")
+			}
+			cell.Text = ""
+		}
+		out[col.ID] = cell
+	}
+	return out, nil
+}
+
+func convertCell(cell models.DatasetCell, from, to units.Unit) models.DatasetCell {
+	value, _ := units.Convert(*cell.Value, from, to)
+	cell.Value = &value
+	if cell.Uncertainty != nil {
+		sigma, _ := units.ConvertUncertainty(*cell.Uncertainty, from, to)
+		cell.Uncertainty = &sigma
+	}
+	return cell
+}
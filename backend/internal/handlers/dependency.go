@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetTaskDependencies(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		deps, err := s.GetTaskDependencies(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get dependencies"})
+			return
+		}
+
+		c.JSON(http.StatusOK, deps)
+	}
+}
+
+func AddTaskDependency(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.AddDependencyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if (req.BlockedBy == nil) == (req.Blocks == nil) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "exactly one of blocked_by or blocks is required"})
+			return
+		}
+
+		dep := &models.TaskDependency{
+			BlockerID: taskID,
+			CreatedBy: &userID,
+			CreatedAt: time.Now(),
+		}
+		otherID := req.Blocks
+		if req.BlockedBy != nil {
+			dep.BlockerID = *req.BlockedBy
+			dep.BlockedID = taskID
+			otherID = req.BlockedBy
+		} else {
+			dep.BlockedID = *req.Blocks
+		}
+
+		other, err := s.GetTaskByID(*otherID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if other == nil || other.ProjectID != task.ProjectID {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "linked task not found in this project"})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityAdded, "task_dependency", dep.BlockedID, nil, gin.H{
+			"blocker_id": dep.BlockerID,
+			"blocked_id": dep.BlockedID,
+		})
+		if err := s.AddTaskDependency(task.ProjectID, dep, activity); err != nil {
+			if errors.Is(err, store.ErrDependencyCycle) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add dependency"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, dep)
+	}
+}
+
+func RemoveTaskDependency(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		otherID, err := uuid.Parse(c.Param("otherId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid linked task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityRemoved, "task_dependency", taskID, gin.H{
+			"task_id":   taskID,
+			"linked_id": otherID,
+		}, nil)
+		if err := s.RemoveTaskDependency(taskID, otherID, activity); err != nil {
+			if errors.Is(err, store.ErrDependencyNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "dependency not found"})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove dependency"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "dependency removed"})
+	}
+}
+
+func GetTaskGraph(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		graph, err := s.GetTaskGraph(projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get task graph"})
+			return
+		}
+
+		c.JSON(http.StatusOK, graph)
+	}
+}
@@ -1,13 +1,19 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/expr"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
 	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
 )
 
 func GetFormulas(s *store.Store) gin.HandlerFunc {
@@ -53,6 +59,11 @@ func CreateFormula(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		if req.Global && req.ProjectID != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "a global formula cannot belong to a project"})
+			return
+		}
+
 		if req.ProjectID != nil {
 			isMember, err := s.IsProjectMember(*req.ProjectID, userID)
 			if err != nil {
@@ -65,18 +76,50 @@ func CreateFormula(s *store.Store) gin.HandlerFunc {
 			}
 		}
 
+		variables, linked, code, err := bindFormulaVariables(s, userID, req.Variables)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
 		formula := &models.Formula{
 			ID:          uuid.New(),
 			Title:       req.Title,
 			Latex:       req.Latex,
 			Description: req.Description,
 			ProjectID:   req.ProjectID,
+			Global:      req.Global,
 			CreatedBy:   userID,
 			CreatedAt:   time.Now(),
 			UpdatedAt:   time.Now(),
+			Review:      models.Review{ReviewStatus: models.ReviewApproved},
+			Variables:   variables,
 		}
 
-		if err := s.CreateFormula(formula); err != nil {
+		scope, err := formulaScope(s, userID, req.ProjectID, variables, linked)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constants"})
+			return
+		}
+		formula.Check = services.CheckFormula(formula.Latex, scope)
+		if formula.Check.HasErrors() {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "formula dimensions do not match", "issues": formula.Check.Issues})
+			return
+		}
+
+		var event *models.ModerationEvent
+		if req.Global {
+			user, code, err := loadSiteUser(s, userID)
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			status, action := reviewForCreate(user)
+			formula.ReviewStatus = status
+			event = store.NewModerationEvent(userID, action, models.ModerationFormula, formula.ID, "", formula)
+		}
+
+		if err := s.CreateFormula(formula, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create formula"})
 			return
 		}
@@ -109,18 +152,13 @@ func GetFormula(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if formula.ProjectID != nil {
-			isMember, err := s.IsProjectMember(*formula.ProjectID, userID)
-			if err != nil {
-				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
-				return
-			}
-			if !isMember {
-				c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
-				return
-			}
-		} else if formula.CreatedBy != userID {
-			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+		if code, err := checkFormulaAccess(s, userID, formula); err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if formula.Variables, err = s.GetFormulaVariables(formula.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get formula variables"})
 			return
 		}
 
@@ -152,7 +190,25 @@ func UpdateFormula(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if formula.CreatedBy != userID {
+		action := ""
+		if formula.Global {
+			user, code, err := loadSiteUser(s, userID)
+			if err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+			if !canSeeReviewed(user, formula.CreatedBy, formula.Review) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "formula not found"})
+				return
+			}
+			status, editAction, ok := reviewForEdit(user, formula.CreatedBy, formula.Review)
+			if !ok {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only curators can change published global formulas"})
+				return
+			}
+			formula.ReviewStatus = status
+			action = editAction
+		} else if formula.CreatedBy != userID {
 			c.JSON(http.StatusForbidden, gin.H{"error": "only creator can update formula"})
 			return
 		}
@@ -163,12 +219,48 @@ func UpdateFormula(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		var variables []models.FormulaVariable
+		var linked []models.Constant
+		if req.Variables != nil {
+			var code int
+			if variables, linked, code, err = bindFormulaVariables(s, userID, req.Variables); err != nil {
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+		} else {
+			if variables, err = s.GetFormulaVariables(formula.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get formula variables"})
+				return
+			}
+			if linked, err = s.GetLinkedConstants(formula.ID); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get formula variables"})
+				return
+			}
+		}
+
 		formula.Title = req.Title
 		formula.Latex = req.Latex
 		formula.Description = req.Description
+		formula.Variables = variables
 		formula.UpdatedAt = time.Now()
 
-		if err := s.UpdateFormula(formula); err != nil {
+		scope, err := formulaScope(s, userID, formula.ProjectID, variables, linked)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constants"})
+			return
+		}
+		formula.Check = services.CheckFormula(formula.Latex, scope)
+		if formula.Check.HasErrors() {
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "formula dimensions do not match", "issues": formula.Check.Issues})
+			return
+		}
+
+		var event *models.ModerationEvent
+		if action != "" {
+			event = store.NewModerationEvent(userID, action, models.ModerationFormula, formula.ID, "", formula)
+		}
+
+		if err := s.UpdateFormula(formula, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update formula"})
 			return
 		}
@@ -201,22 +293,39 @@ func DeleteFormula(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
 		isSystem, err := s.IsSystemUser(formula.CreatedBy)
 		if err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
 			return
 		}
-		if isSystem {
+		if isSystem && user.SiteRole != models.SiteRoleAdmin {
 			c.JSON(http.StatusForbidden, gin.H{"error": "cannot delete system formulas"})
 			return
 		}
 
-		if formula.CreatedBy != userID {
+		var event *models.ModerationEvent
+		if formula.Global {
+			if !canSeeReviewed(user, formula.CreatedBy, formula.Review) {
+				c.JSON(http.StatusNotFound, gin.H{"error": "formula not found"})
+				return
+			}
+			if !canDeleteGlobal(user, formula.CreatedBy, formula.Review) {
+				c.JSON(http.StatusForbidden, gin.H{"error": "only curators can delete published global formulas"})
+				return
+			}
+			event = store.NewModerationEvent(userID, models.ModerationDeleted, models.ModerationFormula, formula.ID, "", formula)
+		} else if formula.CreatedBy != userID {
 			c.JSON(http.StatusForbidden, gin.H{"error": "only creator can delete formula"})
 			return
 		}
 
-		if err := s.DeleteFormula(formulaID); err != nil {
+		if err := s.DeleteFormula(formulaID, event); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete formula"})
 			return
 		}
@@ -224,3 +333,226 @@ func DeleteFormula(s *store.Store) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "formula deleted"})
 	}
 }
+
+func EvaluateFormula(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		formula, code, err := loadReadableFormula(s, userID, c.Param("id"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.EvaluateFormulaRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		scope, code, err := evaluationScope(s, userID, formula, req.ProjectID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := services.EvaluateFormula(formula.Latex, req.Variables, scope, req.Unit)
+		if err != nil {
+			writeEvaluationError(c, err)
+			return
+		}
+		resp.FormulaID = formula.ID
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+func PropagateUncertainty(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		formula, code, err := loadReadableFormula(s, userID, c.Param("id"))
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.PropagateUncertaintyRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		scope, code, err := evaluationScope(s, userID, formula, req.ProjectID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp, err := services.PropagateUncertainty(formula.Latex, req, scope)
+		if err != nil {
+			writeEvaluationError(c, err)
+			return
+		}
+		resp.FormulaID = formula.ID
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// loadReadableFormula loads the formula with the given id if the user may
+// read it.
+func loadReadableFormula(s *store.Store, userID uuid.UUID, id string) (*models.Formula, int, error) {
+	formulaID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid formula id")
+	}
+	formula, err := s.GetFormulaByID(formulaID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to get formula")
+	}
+	if formula == nil {
+		return nil, http.StatusNotFound, errors.New("formula not found")
+	}
+	if code, err := checkFormulaAccess(s, userID, formula); err != nil {
+		return nil, code, err
+	}
+	return formula, http.StatusOK, nil
+}
+
+// evaluationScope loads what the formula's variables resolve to: its
+// declared variables and the constants visible in its project, or in
+// projectID when the caller evaluates it in another project they belong to.
+func evaluationScope(s *store.Store, userID uuid.UUID, formula *models.Formula, projectID *uuid.UUID) (*services.FormulaScope, int, error) {
+	if projectID != nil {
+		isMember, err := s.IsProjectMember(*projectID, userID)
+		if err != nil {
+			return nil, http.StatusInternalServerError, errors.New("internal server error")
+		}
+		if !isMember {
+			return nil, http.StatusForbidden, errors.New("access denied")
+		}
+	} else {
+		projectID = formula.ProjectID
+	}
+
+	variables, err := s.GetFormulaVariables(formula.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to get formula variables")
+	}
+	linked, err := s.GetLinkedConstants(formula.ID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to get formula variables")
+	}
+	scope, err := formulaScope(s, userID, projectID, variables, linked)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("failed to get constants")
+	}
+	return scope, http.StatusOK, nil
+}
+
+// writeEvaluationError reports why a formula could not be evaluated: a bad
+// unit in the request is the caller's error, everything else is a problem
+// with the formula or its values.
+func writeEvaluationError(c *gin.Context, err error) {
+	var unitErr *units.Error
+	var missing *services.MissingVariablesError
+	switch {
+	case errors.As(err, &unitErr):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.As(err, &missing):
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error(), "missing": missing.Names})
+	default:
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+	}
+}
+
+// checkFormulaAccess reports whether the user may read the formula: project
+// formulas need membership, unapproved global ones are hidden from all but
+// their author and curators, and personal ones are private.
+func checkFormulaAccess(s *store.Store, userID uuid.UUID, formula *models.Formula) (int, error) {
+	if formula.ProjectID != nil {
+		isMember, err := s.IsProjectMember(*formula.ProjectID, userID)
+		if err != nil {
+			return http.StatusInternalServerError, errors.New("internal server error")
+		}
+		if !isMember {
+			return http.StatusForbidden, errors.New("access denied")
+		}
+	} else if formula.Global {
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			return code, err
+		}
+		if !canSeeReviewed(user, formula.CreatedBy, formula.Review) {
+			return http.StatusNotFound, errors.New("formula not found")
+		}
+	} else if formula.CreatedBy != userID {
+		return http.StatusForbidden, errors.New("access denied")
+	}
+	return http.StatusOK, nil
+}
+
+// bindFormulaVariables validates declared variables: symbols must be
+// unique once normalized, units must parse, and linked constants must
+// exist and be visible to the user. It also returns the linked constants.
+func bindFormulaVariables(s *store.Store, userID uuid.UUID, reqs []models.FormulaVariableRequest) ([]models.FormulaVariable, []models.Constant, int, error) {
+	variables := []models.FormulaVariable{}
+	var linked []models.Constant
+	seen := map[string]bool{}
+	for _, req := range reqs {
+		symbol := expr.Symbol(req.Symbol)
+		if seen[symbol] {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("variable %s is declared twice", symbol)
+		}
+		seen[symbol] = true
+
+		if req.ConstantID != nil {
+			if req.Unit != "" {
+				return nil, nil, http.StatusBadRequest, fmt.Errorf("variable %s has both a unit and a constant", symbol)
+			}
+			constant, err := s.GetConstantByID(*req.ConstantID)
+			if err != nil {
+				return nil, nil, http.StatusInternalServerError, errors.New("failed to get constant")
+			}
+			if constant == nil {
+				return nil, nil, http.StatusBadRequest, fmt.Errorf("constant for variable %s not found", symbol)
+			}
+			if code, err := checkConstantAccess(s, userID, constant); err != nil {
+				if code == http.StatusInternalServerError {
+					return nil, nil, code, err
+				}
+				return nil, nil, http.StatusBadRequest, fmt.Errorf("constant for variable %s not found", symbol)
+			}
+			linked = append(linked, *constant)
+		} else if _, err := units.Parse(req.Unit); err != nil {
+			return nil, nil, http.StatusBadRequest, fmt.Errorf("variable %s: %w", symbol, err)
+		}
+
+		variables = append(variables, models.FormulaVariable{
+			Symbol:      symbol,
+			Unit:        strings.TrimSpace(req.Unit),
+			ConstantID:  req.ConstantID,
+			Description: req.Description,
+		})
+	}
+	return variables, linked, http.StatusOK, nil
+}
+
+// formulaScope resolves a formula's variables through its declared ones and
+// the constants the user sees in projectID.
+func formulaScope(s *store.Store, userID uuid.UUID, projectID *uuid.UUID, variables []models.FormulaVariable, linked []models.Constant) (*services.FormulaScope, error) {
+	constants, err := s.GetEffectiveConstants(userID, projectID)
+	if err != nil {
+		return nil, err
+	}
+	return services.NewFormulaScope(variables, linked, constants), nil
+}
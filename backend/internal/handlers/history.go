@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/markup"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetTaskHistory(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		timeline, err := s.GetTaskTimeline(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get task history"})
+			return
+		}
+
+		c.JSON(http.StatusOK, timeline)
+	}
+}
+
+func RevertTask(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.RevertTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		revision, err := s.GetTaskRevision(taskID, req.Revision)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if revision == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "revision not found"})
+			return
+		}
+
+		before := *task
+		revision.Snapshot.ApplyTo(task)
+		if task.Description != before.Description {
+			task.DescriptionHTML = markup.Safe(task.Description)
+		}
+
+		// The restored assignee may have left the project since.
+		if task.AssignedTo != nil && (before.AssignedTo == nil || *before.AssignedTo != *task.AssignedTo) {
+			if code, err := checkAssignees(s, task.ProjectID, []uuid.UUID{*task.AssignedTo}); err != nil {
+				if code == http.StatusInternalServerError {
+					c.JSON(code, gin.H{"error": "internal server error"})
+					return
+				}
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		if task.Status != before.Status {
+			workflow, err := s.GetWorkflow(task.ProjectID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if code, err := checkStatusChange(s, workflow, task, before.Status); err != nil {
+				if code == http.StatusInternalServerError {
+					c.JSON(code, gin.H{"error": "internal server error"})
+					return
+				}
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		task.UpdatedAt = time.Now()
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityUpdated, "task", task.ID, before, task)
+		if err := s.RevertTask(task, revision.Revision, activity); err != nil {
+			writeTaskSaveError(c, err, "failed to revert task")
+			return
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
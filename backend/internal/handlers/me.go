@@ -0,0 +1,126 @@
+package handlers
+
+import (
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func UpdateMe(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, err := s.GetUserByID(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		var req models.UpdateMeRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		if name := strings.TrimSpace(req.Name); name != "" {
+			user.Name = name
+		}
+		if req.Timezone != "" {
+			if _, err := time.LoadLocation(req.Timezone); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "unknown timezone"})
+				return
+			}
+			user.Timezone = req.Timezone
+		}
+		user.UpdatedAt = time.Now()
+
+		if err := s.UpdateUserProfile(user); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update user"})
+			return
+		}
+
+		c.JSON(http.StatusOK, user)
+	}
+}
+
+// GetMyTasks lists the open tasks the current user is assigned to or watches
+// across all of their projects. Days are counted in the user's timezone, which
+// ?tz= overrides for a single request.
+func GetMyTasks(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, err := s.GetUserByID(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if user == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		tz := c.DefaultQuery("tz", user.Timezone)
+		loc, err := time.LoadLocation(tz)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "unknown timezone"})
+			return
+		}
+
+		projects, err := s.GetProjectsByUser(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get projects"})
+			return
+		}
+
+		now := time.Now().In(loc)
+		// A day of slack covers date-only deadlines, which are stored at
+		// 23:59:59 UTC and may sit past the week's local end.
+		withinHours := int(math.Ceil(services.EndOfWeek(now).Sub(now).Hours())) + 24
+
+		var tasks []models.MyTask
+		var refs []*models.Task
+		for _, p := range projects {
+			dated, err := s.GetTasksWithUpcomingDeadlines(p.ID, withinHours, &userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+				return
+			}
+			undated, err := s.GetUndatedTasksForUser(p.ID, userID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+				return
+			}
+			for _, t := range append(dated, undated...) {
+				tasks = append(tasks, models.MyTask{Task: t, ProjectName: p.Name})
+			}
+		}
+		for i := range tasks {
+			refs = append(refs, &tasks[i].Task)
+		}
+		if err := s.AttachTaskDetails(refs...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, services.GroupMyTasks(tasks, now))
+	}
+}
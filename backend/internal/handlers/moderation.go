@@ -0,0 +1,350 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/notify"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetModerationQueue(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if !user.CanCurate() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "curator role required"})
+			return
+		}
+
+		constants, err := s.GetPendingConstants()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get moderation queue"})
+			return
+		}
+		formulas, err := s.GetPendingFormulas()
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get moderation queue"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.ModerationQueue{Constants: constants, Formulas: formulas})
+	}
+}
+
+// ReviewConstant approves or rejects a proposed global constant, depending
+// on status, and tells its author.
+func ReviewConstant(s *store.Store, n *notify.Notifier, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		constantID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid constant id"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if !user.CanCurate() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "curator role required"})
+			return
+		}
+
+		constant, err := s.GetConstantByID(constantID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if constant == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "constant not found"})
+			return
+		}
+
+		note, code, err := bindReviewNote(c, status)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.ReviewConstant(constantID, userID, status, note); err != nil {
+			if errors.Is(err, store.ErrNotPending) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to review constant"})
+			return
+		}
+
+		constant, err = s.GetConstantByID(constantID)
+		if err != nil || constant == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get constant"})
+			return
+		}
+		n.EntryReviewed(userID, constant.CreatedBy, models.ModerationConstant, constant.Name, status, note)
+
+		c.JSON(http.StatusOK, constant)
+	}
+}
+
+// ReviewFormula approves or rejects a proposed global formula, depending on
+// status, and tells its author.
+func ReviewFormula(s *store.Store, n *notify.Notifier, status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		formulaID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid formula id"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if !user.CanCurate() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "curator role required"})
+			return
+		}
+
+		formula, err := s.GetFormulaByID(formulaID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if formula == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "formula not found"})
+			return
+		}
+
+		note, code, err := bindReviewNote(c, status)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.ReviewFormula(formulaID, userID, status, note); err != nil {
+			if errors.Is(err, store.ErrNotPending) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to review formula"})
+			return
+		}
+
+		formula, err = s.GetFormulaByID(formulaID)
+		if err != nil || formula == nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get formula"})
+			return
+		}
+		n.EntryReviewed(userID, formula.CreatedBy, models.ModerationFormula, formula.Title, status, note)
+
+		c.JSON(http.StatusOK, formula)
+	}
+}
+
+func GetModerationLog(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if !user.CanCurate() {
+			c.JSON(http.StatusForbidden, gin.H{"error": "curator role required"})
+			return
+		}
+
+		entityType := c.Query("entity_type")
+		if entityType != "" && entityType != models.ModerationConstant &&
+			entityType != models.ModerationFormula && entityType != models.ModerationUser {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity_type"})
+			return
+		}
+
+		var entityID *uuid.UUID
+		if raw := c.Query("entity_id"); raw != "" {
+			id, err := uuid.Parse(raw)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid entity id"})
+				return
+			}
+			entityID = &id
+		}
+
+		limit := 50
+		if raw := c.Query("limit"); raw != "" {
+			l, err := strconv.Atoi(raw)
+			if err != nil || l < 1 || l > 200 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+				return
+			}
+			limit = l
+		}
+
+		events, err := s.GetModerationLog(entityType, entityID, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get moderation log"})
+			return
+		}
+
+		c.JSON(http.StatusOK, events)
+	}
+}
+
+func SetUserSiteRole(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		targetID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid user id"})
+			return
+		}
+
+		user, code, err := loadSiteUser(s, userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if user.SiteRole != models.SiteRoleAdmin {
+			c.JSON(http.StatusForbidden, gin.H{"error": "admin role required"})
+			return
+		}
+
+		target, err := s.GetUserByID(targetID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if target == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "user not found"})
+			return
+		}
+
+		var req models.SetSiteRoleRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if !models.ValidSiteRole(req.SiteRole) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid site role"})
+			return
+		}
+		if targetID == userID && req.SiteRole != models.SiteRoleAdmin {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "admins cannot demote themselves"})
+			return
+		}
+
+		if target.SiteRole != req.SiteRole {
+			event := store.NewModerationEvent(userID, models.ModerationRoleSet, models.ModerationUser, targetID, "",
+				gin.H{"from": target.SiteRole, "to": req.SiteRole})
+			if err := s.SetUserSiteRole(targetID, req.SiteRole, event); err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to set site role"})
+				return
+			}
+			target.SiteRole = req.SiteRole
+		}
+
+		c.JSON(http.StatusOK, target)
+	}
+}
+
+// loadSiteUser fetches the current user to check their site role.
+func loadSiteUser(s *store.Store, userID uuid.UUID) (*models.User, int, error) {
+	user, err := s.GetUserByID(userID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if user == nil {
+		return nil, http.StatusUnauthorized, errors.New("unauthorized")
+	}
+	return user, http.StatusOK, nil
+}
+
+// bindReviewNote reads the optional review note. Rejections must explain
+// what to fix.
+func bindReviewNote(c *gin.Context, status string) (string, int, error) {
+	var req models.ReviewRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			return "", http.StatusBadRequest, err
+		}
+	}
+	note := strings.TrimSpace(req.Note)
+	if status == models.ReviewRejected && note == "" {
+		return "", http.StatusBadRequest, errors.New("a note is required to reject an entry")
+	}
+	return note, http.StatusOK, nil
+}
+
+// canSeeReviewed reports whether user may see an entry: unpublished global
+// entries are visible only to their author and to curators.
+func canSeeReviewed(user *models.User, createdBy uuid.UUID, review models.Review) bool {
+	return review.ReviewStatus == models.ReviewApproved || createdBy == user.ID || user.CanCurate()
+}
+
+// reviewForCreate returns the review status and audit action of a new global
+// entry: curators publish directly, everyone else submits a proposal.
+func reviewForCreate(user *models.User) (string, string) {
+	if user.CanCurate() {
+		return models.ReviewApproved, models.ModerationPublished
+	}
+	return models.ReviewPending, models.ModerationSubmitted
+}
+
+// reviewForEdit decides how an edit of a global entry is moderated. Curators
+// may edit any entry without changing its status; authors may only edit
+// their unpublished proposals, which goes back into the queue.
+func reviewForEdit(user *models.User, createdBy uuid.UUID, review models.Review) (status, action string, ok bool) {
+	if user.CanCurate() {
+		return review.ReviewStatus, models.ModerationUpdated, true
+	}
+	if createdBy == user.ID && review.ReviewStatus != models.ReviewApproved {
+		return models.ReviewPending, models.ModerationSubmitted, true
+	}
+	return "", "", false
+}
+
+// canDeleteGlobal allows curators to remove any global entry and authors to
+// withdraw their unpublished proposals.
+func canDeleteGlobal(user *models.User, createdBy uuid.UUID, review models.Review) bool {
+	return user.CanCurate() || (createdBy == user.ID && review.ReviewStatus != models.ReviewApproved)
+}
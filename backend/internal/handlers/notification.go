@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+// GetNotifications lists the current user's notifications, newest first.
+// ?unread=true hides read ones and ?before= (RFC3339) pages back.
+func GetNotifications(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		unreadOnly := c.Query("unread") == "true"
+
+		limit := 50
+		if limitParam := c.Query("limit"); limitParam != "" {
+			limit, err = strconv.Atoi(limitParam)
+			if err != nil || limit < 1 || limit > 200 {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be between 1 and 200"})
+				return
+			}
+		}
+
+		var before *time.Time
+		if beforeParam := c.Query("before"); beforeParam != "" {
+			parsed, err := time.Parse(time.RFC3339Nano, beforeParam)
+			if err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid before, use RFC3339"})
+				return
+			}
+			before = &parsed
+		}
+
+		notifications, err := s.GetNotifications(userID, unreadOnly, before, limit)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notifications"})
+			return
+		}
+
+		unread, err := s.CountUnreadNotifications(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, models.NotificationList{
+			Notifications: notifications,
+			UnreadCount:   unread,
+		})
+	}
+}
+
+func GetUnreadNotificationCount(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		unread, err := s.CountUnreadNotifications(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+	}
+}
+
+func MarkNotificationsRead(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		var req models.MarkNotificationsReadRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		if req.All == (len(req.IDs) > 0) {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "either ids or all is required"})
+			return
+		}
+
+		if err := s.MarkNotificationsRead(userID, req.IDs); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to mark notifications read"})
+			return
+		}
+
+		unread, err := s.CountUnreadNotifications(userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to count notifications"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"unread_count": unread})
+	}
+}
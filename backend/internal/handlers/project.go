@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"errors"
 	"net/http"
 	"time"
 
@@ -305,7 +306,8 @@ func AddProjectMember(s *store.Store) gin.HandlerFunc {
 			JoinedAt:  time.Now(),
 		}
 
-		if err := s.AddProjectMember(member); err != nil {
+		activity := store.NewActivity(projectID, userID, models.ActivityAdded, "member", memberUserID, nil, gin.H{"role": member.Role})
+		if err := s.AddProjectMember(member, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add member"})
 			return
 		}
@@ -349,7 +351,12 @@ func RemoveProjectMember(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.RemoveProjectMember(projectID, memberUserID); err != nil {
+		activity := store.NewActivity(projectID, userID, models.ActivityRemoved, "member", memberUserID, nil, nil)
+		if err := s.RemoveProjectMember(projectID, memberUserID, activity); err != nil {
+			if errors.Is(err, store.ErrMemberNotFound) {
+				c.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove member"})
 			return
 		}
@@ -406,7 +413,9 @@ func TransferOwnership(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.TransferOwnership(projectID, userID, newOwnerID); err != nil {
+		activity := store.NewActivity(projectID, userID, models.ActivityTransferred, "project", projectID,
+			gin.H{"owner_id": userID}, gin.H{"owner_id": newOwnerID})
+		if err := s.TransferOwnership(projectID, userID, newOwnerID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -468,7 +477,15 @@ func UpdateMemberRole(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.UpdateMemberRole(projectID, memberUserID, req.Role); err != nil {
+		previousRole, err := s.GetMemberRole(projectID, memberUserID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		activity := store.NewActivity(projectID, userID, models.ActivityRoleChanged, "member", memberUserID,
+			gin.H{"role": previousRole}, gin.H{"role": req.Role})
+		if err := s.UpdateMemberRole(projectID, memberUserID, req.Role, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 			return
 		}
@@ -0,0 +1,60 @@
+package handlers
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// parseTaskQuery reads a task query from the URL. List parameters are comma
+// separated, e.g. ?status=todo,in_progress&assignee=me&sort=-due_date.
+func parseTaskQuery(c *gin.Context) (*models.TaskQuery, error) {
+	q := &models.TaskQuery{
+		Statuses:   splitParam(c.Query("status")),
+		Priorities: splitParam(c.Query("priority")),
+		Assignees:  splitParam(c.Query("assignee")),
+		Creators:   splitParam(c.Query("creator")),
+		DueFrom:    strings.TrimSpace(c.Query("due_from")),
+		DueTo:      strings.TrimSpace(c.Query("due_to")),
+		TagMatch:   c.Query("tag_match"),
+		Search:     strings.TrimSpace(c.Query("q")),
+		Sort:       c.Query("sort"),
+	}
+
+	for _, tag := range splitParam(c.Query("tags")) {
+		tagID, err := uuid.Parse(tag)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid tag id %q", models.ErrInvalidTaskQuery, tag)
+		}
+		q.Tags = append(q.Tags, tagID)
+	}
+
+	for name, flag := range map[string]*bool{"overdue": &q.Overdue, "no_due": &q.NoDue} {
+		if raw := c.Query(name); raw != "" {
+			value, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %s must be true or false", models.ErrInvalidTaskQuery, name)
+			}
+			*flag = value
+		}
+	}
+
+	if err := q.Validate(); err != nil {
+		return nil, err
+	}
+	return q, nil
+}
+
+func splitParam(raw string) []string {
+	var values []string
+	for _, v := range strings.Split(raw, ",") {
+		if v = strings.TrimSpace(v); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
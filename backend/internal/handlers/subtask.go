@@ -0,0 +1,292 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetSubtasks(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		subtasks, err := s.GetSubtasks(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get subtasks"})
+			return
+		}
+
+		refs := make([]*models.Task, len(subtasks))
+		for i := range subtasks {
+			refs[i] = &subtasks[i]
+		}
+		if err := s.AttachTaskDetails(refs...); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get subtasks"})
+			return
+		}
+
+		c.JSON(http.StatusOK, subtasks)
+	}
+}
+
+func GetChecklist(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		items, err := s.GetChecklistItems(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get checklist"})
+			return
+		}
+
+		c.JSON(http.StatusOK, items)
+	}
+}
+
+func CreateChecklistItem(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.CreateChecklistItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		content := strings.TrimSpace(req.Content)
+		if content == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "content cannot be empty"})
+			return
+		}
+
+		item := &models.ChecklistItem{
+			ID:        uuid.New(),
+			TaskID:    taskID,
+			Content:   content,
+			CreatedBy: &userID,
+			CreatedAt: time.Now(),
+			UpdatedAt: time.Now(),
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityCreated, "checklist_item", item.ID, nil, item)
+		if err := s.CreateChecklistItem(item, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create checklist item"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, item)
+	}
+}
+
+func UpdateChecklistItem(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		itemID, err := uuid.Parse(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checklist item id"})
+			return
+		}
+
+		item, err := s.GetChecklistItemByID(itemID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if item == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "checklist item not found"})
+			return
+		}
+
+		task, err := s.GetTaskByID(item.TaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.UpdateChecklistItemRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *item
+		if content := strings.TrimSpace(req.Content); content != "" {
+			item.Content = content
+		}
+		if req.Done != nil {
+			item.Done = *req.Done
+		}
+		if req.Position != nil {
+			item.Position = *req.Position
+		}
+		item.UpdatedAt = time.Now()
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityUpdated, "checklist_item", item.ID, before, item)
+		if err := s.UpdateChecklistItem(item, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update checklist item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, item)
+	}
+}
+
+func DeleteChecklistItem(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		itemID, err := uuid.Parse(c.Param("itemId"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid checklist item id"})
+			return
+		}
+
+		item, err := s.GetChecklistItemByID(itemID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if item == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "checklist item not found"})
+			return
+		}
+
+		task, err := s.GetTaskByID(item.TaskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityDeleted, "checklist_item", item.ID, item, nil)
+		if err := s.DeleteChecklistItem(itemID, activity); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete checklist item"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "checklist item deleted"})
+	}
+}
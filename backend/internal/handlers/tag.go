@@ -89,7 +89,8 @@ func CreateTag(s *store.Store) gin.HandlerFunc {
 			CreatedAt: time.Now(),
 		}
 
-		if err := s.CreateTag(tag); err != nil {
+		activity := store.NewActivity(projectID, userID, models.ActivityCreated, "tag", tag.ID, nil, tag)
+		if err := s.CreateTag(tag, activity); err != nil {
 			if strings.Contains(err.Error(), "unique") || strings.Contains(err.Error(), "duplicate") {
 				c.JSON(http.StatusConflict, gin.H{"error": "tag with this name already exists"})
 				return
@@ -142,6 +143,7 @@ func UpdateTag(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		before := *tag
 		if req.Name != "" {
 			tag.Name = strings.TrimSpace(req.Name)
 		}
@@ -153,7 +155,8 @@ func UpdateTag(s *store.Store) gin.HandlerFunc {
 			tag.Color = req.Color
 		}
 
-		if err := s.UpdateTag(tag); err != nil {
+		activity := store.NewActivity(tag.ProjectID, userID, models.ActivityUpdated, "tag", tag.ID, before, tag)
+		if err := s.UpdateTag(tag, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update tag"})
 			return
 		}
@@ -196,7 +199,8 @@ func DeleteTag(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.DeleteTag(tagID); err != nil {
+		activity := store.NewActivity(tag.ProjectID, userID, models.ActivityDeleted, "tag", tag.ID, tag, nil)
+		if err := s.DeleteTag(tagID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete tag"})
 			return
 		}
@@ -309,7 +313,9 @@ func AddTagToTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.AddTagToTask(taskID, tagID); err != nil {
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityAdded, "task_tag", taskID,
+			nil, gin.H{"tag_id": tag.ID, "tag_name": tag.Name})
+		if err := s.AddTagToTask(taskID, tagID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to add tag to task"})
 			return
 		}
@@ -358,7 +364,13 @@ func RemoveTagFromTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.RemoveTagFromTask(taskID, tagID); err != nil {
+		removed := gin.H{"tag_id": tagID}
+		if tag, err := s.GetTagByID(tagID); err == nil && tag != nil {
+			removed["tag_name"] = tag.Name
+		}
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityRemoved, "task_tag", taskID, removed, nil)
+		if err := s.RemoveTagFromTask(taskID, tagID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to remove tag from task"})
 			return
 		}
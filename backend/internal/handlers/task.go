@@ -1,13 +1,18 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
-	"strings"
+	"strconv"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/markup"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/notify"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
 	"github.com/itmo-pride/student-taskboard/backend/internal/store"
 )
 
@@ -35,27 +40,39 @@ func GetTasks(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		var filterTagIDs []uuid.UUID
-		if tagsParam := c.Query("tags"); tagsParam != "" {
-			tagStrings := strings.Split(tagsParam, ",")
-			for _, tagStr := range tagStrings {
-				if tagID, err := uuid.Parse(strings.TrimSpace(tagStr)); err == nil {
-					filterTagIDs = append(filterTagIDs, tagID)
-				}
+		query, err := parseTaskQuery(c)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		limit := 0
+		if limitParam := c.Query("limit"); limitParam != "" {
+			limit, err = strconv.Atoi(limitParam)
+			if err != nil || limit < 1 || limit > models.MaxTaskPageSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", models.MaxTaskPageSize)})
+				return
 			}
 		}
 
-		tasks, err := s.GetTasksWithTags(projectID, filterTagIDs)
+		tasks, next, err := s.QueryTasks(projectID, userID, query, limit, c.Query("cursor"))
 		if err != nil {
+			if errors.Is(err, models.ErrInvalidCursor) || errors.Is(err, models.ErrInvalidTaskQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
 			return
 		}
 
+		if next != "" {
+			c.Header("X-Next-Cursor", next)
+		}
 		c.JSON(http.StatusOK, tasks)
 	}
 }
 
-func CreateTask(s *store.Store) gin.HandlerFunc {
+func CreateTask(s *store.Store, n *notify.Notifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := getUserID(c)
 		if err != nil {
@@ -85,8 +102,14 @@ func CreateTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		workflow, err := s.GetWorkflow(projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
 		if req.Status == "" {
-			req.Status = "todo"
+			req.Status = workflow.InitialStatus()
 		}
 		if req.Priority == "" {
 			req.Priority = "medium"
@@ -94,36 +117,69 @@ func CreateTask(s *store.Store) gin.HandlerFunc {
 
 		var dueDate *time.Time
 		if req.DueDate != nil && *req.DueDate != "" {
-			parsed, err := time.Parse(time.RFC3339, *req.DueDate)
+			parsed, err := services.ParseDueDate(*req.DueDate)
 			if err != nil {
-				parsed, err = time.Parse("2006-01-02", *req.DueDate)
-				if err != nil {
-					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date format, use RFC3339 or YYYY-MM-DD"})
-					return
-				}
-				parsed = parsed.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date format, use RFC3339 or YYYY-MM-DD"})
+				return
 			}
 			dueDate = &parsed
 		}
 
+		assignees := req.Assignees
+		if req.AssignedTo != nil {
+			assignees = append([]uuid.UUID{*req.AssignedTo}, assignees...)
+		}
+		assignees = uniqueUUIDs(assignees)
+		if code, err := checkAssignees(s, projectID, assignees); err != nil {
+			if code == http.StatusInternalServerError {
+				c.JSON(code, gin.H{"error": "internal server error"})
+				return
+			}
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+		if req.AssignedTo == nil && len(assignees) > 0 {
+			req.AssignedTo = &assignees[0]
+		}
+
+		descriptionHTML, err := markup.Render(req.Description)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
 		task := &models.Task{
-			ID:          uuid.New(),
-			ProjectID:   projectID,
-			Title:       req.Title,
-			Description: req.Description,
-			Status:      req.Status,
-			Priority:    req.Priority,
-			DueDate:     dueDate,
-			AssignedTo:  req.AssignedTo,
-			CreatedBy:   userID,
-			CreatedAt:   time.Now(),
-			UpdatedAt:   time.Now(),
+			ID:              uuid.New(),
+			ProjectID:       projectID,
+			Title:           req.Title,
+			Description:     req.Description,
+			DescriptionHTML: descriptionHTML,
+			Status:          req.Status,
+			Priority:        req.Priority,
+			DueDate:         dueDate,
+			AssignedTo:      req.AssignedTo,
+			Assignees:       assignees,
+			ParentID:        req.ParentID,
+			CreatedBy:       userID,
+			CreatedAt:       time.Now(),
+			UpdatedAt:       time.Now(),
+		}
+
+		if code, err := checkStatusChange(s, workflow, task, ""); err != nil {
+			if code == http.StatusInternalServerError {
+				c.JSON(code, gin.H{"error": "internal server error"})
+				return
+			}
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
 		}
 
-		if err := s.CreateTask(task); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create task"})
+		activity := store.NewActivity(projectID, userID, models.ActivityCreated, "task", task.ID, nil, task)
+		if err := s.CreateTask(task, activity); err != nil {
+			writeTaskSaveError(c, err, "failed to create task")
 			return
 		}
+		n.TaskAssigned(task, userID, task.Assignees)
 
 		c.JSON(http.StatusCreated, task)
 	}
@@ -163,11 +219,16 @@ func GetTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		if err := s.AttachTaskDetails(task); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get task"})
+			return
+		}
+
 		c.JSON(http.StatusOK, task)
 	}
 }
 
-func UpdateTask(s *store.Store) gin.HandlerFunc {
+func UpdateTask(s *store.Store, n *notify.Notifier) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		userID, err := getUserID(c)
 		if err != nil {
@@ -207,11 +268,16 @@ func UpdateTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
+		before := *task
 		if req.Title != "" {
 			task.Title = req.Title
 		}
-		if req.Description != "" {
+		if req.Description != "" && req.Description != task.Description {
 			task.Description = req.Description
+			if task.DescriptionHTML, err = markup.Render(req.Description); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
 		}
 		if req.Status != "" {
 			task.Status = req.Status
@@ -219,31 +285,144 @@ func UpdateTask(s *store.Store) gin.HandlerFunc {
 		if req.Priority != "" {
 			task.Priority = req.Priority
 		}
-		if req.AssignedTo != nil {
+		if req.AssignedTo != nil && (task.AssignedTo == nil || *task.AssignedTo != *req.AssignedTo) {
+			if code, err := checkAssignees(s, task.ProjectID, []uuid.UUID{*req.AssignedTo}); err != nil {
+				if code == http.StatusInternalServerError {
+					c.JSON(code, gin.H{"error": "internal server error"})
+					return
+				}
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
 			task.AssignedTo = req.AssignedTo
 		}
+		if req.ParentID != nil {
+			if *req.ParentID == "" || *req.ParentID == "null" {
+				task.ParentID = nil
+			} else {
+				parentID, err := uuid.Parse(*req.ParentID)
+				if err != nil {
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid parent_id"})
+					return
+				}
+				task.ParentID = &parentID
+			}
+		}
 
 		if req.DueDate != nil {
 			if *req.DueDate == "" || *req.DueDate == "null" {
 				task.DueDate = nil
 			} else {
-				parsed, err := time.Parse(time.RFC3339, *req.DueDate)
+				parsed, err := services.ParseDueDate(*req.DueDate)
 				if err != nil {
-					parsed, err = time.Parse("2006-01-02", *req.DueDate)
-					if err != nil {
-						c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date format"})
-						return
-					}
-					parsed = parsed.Add(23*time.Hour + 59*time.Minute + 59*time.Second)
+					c.JSON(http.StatusBadRequest, gin.H{"error": "invalid due_date format"})
+					return
 				}
 				task.DueDate = &parsed
 			}
 		}
 
+		if task.Status != before.Status {
+			workflow, err := s.GetWorkflow(task.ProjectID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if code, err := checkStatusChange(s, workflow, task, before.Status); err != nil {
+				if code == http.StatusInternalServerError {
+					c.JSON(code, gin.H{"error": "internal server error"})
+					return
+				}
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
+		task.UpdatedAt = time.Now()
+
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityUpdated, "task", task.ID, before, task)
+		if err := s.UpdateTask(task, activity); err != nil {
+			writeTaskSaveError(c, err, "failed to update task")
+			return
+		}
+		if task.AssignedTo != nil && (before.AssignedTo == nil || *before.AssignedTo != *task.AssignedTo) {
+			n.TaskAssigned(task, userID, []uuid.UUID{*task.AssignedTo})
+		}
+
+		c.JSON(http.StatusOK, task)
+	}
+}
+
+func MoveTask(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		taskID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid task id"})
+			return
+		}
+
+		task, err := s.GetTaskByID(taskID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if task == nil {
+			c.JSON(http.StatusNotFound, gin.H{"error": "task not found"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(task.ProjectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.MoveTaskRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *task
+		task.Status = req.Status
+		if task.Status != before.Status {
+			workflow, err := s.GetWorkflow(task.ProjectID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+				return
+			}
+			if code, err := checkStatusChange(s, workflow, task, before.Status); err != nil {
+				if code == http.StatusInternalServerError {
+					c.JSON(code, gin.H{"error": "internal server error"})
+					return
+				}
+				c.JSON(code, gin.H{"error": err.Error()})
+				return
+			}
+		}
+
 		task.UpdatedAt = time.Now()
 
-		if err := s.UpdateTask(task); err != nil {
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update task"})
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityMoved, "task", task.ID, before, task)
+		if err := s.MoveTask(task, req.BeforeID, req.AfterID, activity); err != nil {
+			switch {
+			case errors.Is(err, store.ErrNeighborNotFound):
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			case errors.Is(err, store.ErrStaleNeighbors), errors.Is(err, models.ErrWIPLimitReached):
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			default:
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to move task"})
+			}
 			return
 		}
 
@@ -298,7 +477,8 @@ func DeleteTask(s *store.Store) gin.HandlerFunc {
 			return
 		}
 
-		if err := s.DeleteTask(taskID); err != nil {
+		activity := store.NewActivity(task.ProjectID, userID, models.ActivityDeleted, "task", task.ID, task, nil)
+		if err := s.DeleteTask(taskID, activity); err != nil {
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete task"})
 			return
 		}
@@ -306,3 +486,22 @@ func DeleteTask(s *store.Store) gin.HandlerFunc {
 		c.JSON(http.StatusOK, gin.H{"message": "task deleted"})
 	}
 }
+
+// writeTaskSaveError reports a failed save: invalid parents are the caller's
+// error, a full status column is a conflict.
+func writeTaskSaveError(c *gin.Context, err error, message string) {
+	switch {
+	case isTaskParentError(err):
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+	case errors.Is(err, models.ErrWIPLimitReached):
+		c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		c.JSON(http.StatusInternalServerError, gin.H{"error": message})
+	}
+}
+
+func isTaskParentError(err error) bool {
+	return errors.Is(err, store.ErrParentNotFound) ||
+		errors.Is(err, store.ErrTaskCycle) ||
+		errors.Is(err, store.ErrTaskDepthExceeded)
+}
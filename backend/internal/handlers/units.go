@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+func ConvertUnits() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.ConvertUnitsRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		from, err := units.Parse(req.From)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		to, err := units.Parse(req.To)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		value, err := units.Convert(*req.Value, from, to)
+		if err != nil {
+			writeConversionError(c, err)
+			return
+		}
+		resp := models.ConvertUnitsResponse{
+			Value:     value,
+			From:      req.From,
+			To:        req.To,
+			Dimension: to.Dim,
+		}
+		if req.Uncertainty != nil {
+			uncertainty, err := units.ConvertUncertainty(*req.Uncertainty, from, to)
+			if err != nil {
+				writeConversionError(c, err)
+				return
+			}
+			resp.Uncertainty = &uncertainty
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// writeConversionError answers 400 for a result too large to represent and
+// 422 for units of different dimensions.
+func writeConversionError(c *gin.Context, err error) {
+	if errors.Is(err, units.ErrOutOfRange) {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "result out of range"})
+		return
+	}
+	c.JSON(http.StatusUnprocessableEntity, gin.H{"error": err.Error()})
+}
+
+// CheckUnit reports whether a unit parses and what it is made of. Invalid
+// units are not a request error: the response says why they are invalid.
+func CheckUnit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		var req models.CheckUnitRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		resp := models.CheckUnitResponse{Unit: req.Unit}
+		u, err := units.Parse(req.Unit)
+		if err != nil {
+			resp.Error = err.Error()
+			c.JSON(http.StatusOK, resp)
+			return
+		}
+		resp.Valid = true
+		resp.Scale = u.Scale
+		resp.Offset = u.Offset
+		resp.Dimension = &u.Dim
+		resp.SI = u.Dim.String()
+		resp.Named = u.Dim.Name()
+
+		if req.CompatibleWith != nil {
+			other, err := units.Parse(*req.CompatibleWith)
+			if err != nil {
+				resp.Valid = false
+				resp.Error = err.Error()
+				c.JSON(http.StatusOK, resp)
+				return
+			}
+			compatible := other.Dim == u.Dim
+			resp.Compatible = &compatible
+		}
+
+		c.JSON(http.StatusOK, resp)
+	}
+}
+
+// convertConstant shows the constant's value in another unit.
+func convertConstant(constant *models.Constant, unit string) error {
+	if constant.NumericValue == nil || constant.UnitScale == nil || constant.Dimensions == nil {
+		return errors.New("constant has no parsed value to convert")
+	}
+	to, err := units.Parse(unit)
+	if err != nil {
+		return err
+	}
+	from := units.Unit{Scale: *constant.UnitScale, Dim: *constant.Dimensions}
+
+	value, err := units.Convert(*constant.NumericValue, from, to)
+	if err != nil {
+		return err
+	}
+	converted := &models.ConvertedValue{Unit: strings.TrimSpace(unit), Value: value}
+	if constant.Uncertainty != nil {
+		uncertainty, err := units.ConvertUncertainty(*constant.Uncertainty, from, to)
+		if err != nil {
+			return err
+		}
+		converted.Uncertainty = &uncertainty
+	}
+	constant.Converted = converted
+	return nil
+}
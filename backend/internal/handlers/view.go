@@ -0,0 +1,347 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetViews(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		views, err := s.GetViewsForUser(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get views"})
+			return
+		}
+
+		c.JSON(http.StatusOK, views)
+	}
+}
+
+func CreateView(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		var req models.CreateViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		name := strings.TrimSpace(req.Name)
+		if name == "" {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "name cannot be empty"})
+			return
+		}
+		if err := req.Query.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		visibility := req.Visibility
+		if visibility == "" {
+			visibility = models.ViewVisibilityPrivate
+		}
+
+		view := &models.SavedView{
+			ID:         uuid.New(),
+			ProjectID:  projectID,
+			OwnerID:    userID,
+			Name:       name,
+			Query:      req.Query,
+			GroupBy:    req.GroupBy,
+			Visibility: visibility,
+			CreatedAt:  time.Now(),
+			UpdatedAt:  time.Now(),
+		}
+
+		if err := s.CreateView(view, viewActivity(userID, models.ActivityCreated, nil, view)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to create view"})
+			return
+		}
+
+		c.JSON(http.StatusCreated, view)
+	}
+}
+
+func GetView(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		view, code, err := loadView(s, c.Param("viewId"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+func UpdateView(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		view, code, err := loadView(s, c.Param("viewId"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if code, err := checkViewOwner(s, view, userID); err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		var req models.UpdateViewRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		before := *view
+		if name := strings.TrimSpace(req.Name); name != "" {
+			view.Name = name
+		}
+		if req.Query != nil {
+			if err := req.Query.Validate(); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			view.Query = *req.Query
+		}
+		if req.GroupBy != nil {
+			if !models.ValidGroupBy(*req.GroupBy) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "group_by must be status, assignee, priority, tag or empty"})
+				return
+			}
+			view.GroupBy = *req.GroupBy
+		}
+		if req.Visibility != "" {
+			view.Visibility = req.Visibility
+		}
+		view.UpdatedAt = time.Now()
+
+		if err := s.UpdateView(view, viewActivity(userID, models.ActivityUpdated, &before, view)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to update view"})
+			return
+		}
+
+		c.JSON(http.StatusOK, view)
+	}
+}
+
+func DeleteView(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		view, code, err := loadView(s, c.Param("viewId"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if code, err := checkViewOwner(s, view, userID); err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		if err := s.DeleteView(view.ID, viewActivity(userID, models.ActivityDeleted, view, nil)); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to delete view"})
+			return
+		}
+
+		c.JSON(http.StatusOK, gin.H{"message": "view deleted"})
+	}
+}
+
+// GetViewTasks runs a view for the current user, so "me" in its query is
+// whoever opens it. It pages like GET /projects/:id/tasks.
+func GetViewTasks(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		view, code, err := loadView(s, c.Param("viewId"), userID)
+		if err != nil {
+			c.JSON(code, gin.H{"error": err.Error()})
+			return
+		}
+
+		limit := 0
+		if limitParam := c.Query("limit"); limitParam != "" {
+			limit, err = strconv.Atoi(limitParam)
+			if err != nil || limit < 1 || limit > models.MaxTaskPageSize {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("limit must be between 1 and %d", models.MaxTaskPageSize)})
+				return
+			}
+		}
+
+		tasks, next, err := s.QueryTasks(view.ProjectID, userID, &view.Query, limit, c.Query("cursor"))
+		if err != nil {
+			if errors.Is(err, models.ErrInvalidCursor) || errors.Is(err, models.ErrInvalidTaskQuery) {
+				c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+			return
+		}
+
+		result := models.ViewResult{View: *view, Tasks: tasks, NextCursor: next}
+		if view.GroupBy != models.GroupByNone {
+			var order []string
+			switch view.GroupBy {
+			case models.GroupByStatus:
+				workflow, err := s.GetWorkflow(view.ProjectID)
+				if err != nil {
+					c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get tasks"})
+					return
+				}
+				for _, st := range workflow.Statuses {
+					order = append(order, st.Key)
+				}
+			case models.GroupByPriority:
+				order = []string{"high", "medium", "low"}
+			}
+			result.Groups = models.GroupTasks(tasks, view.GroupBy, order)
+		}
+
+		if next != "" {
+			c.Header("X-Next-Cursor", next)
+		}
+		c.JSON(http.StatusOK, result)
+	}
+}
+
+// loadView fetches a view the user is allowed to see. Private views of other
+// users are reported as missing.
+func loadView(s *store.Store, rawID string, userID uuid.UUID) (*models.SavedView, int, error) {
+	viewID, err := uuid.Parse(rawID)
+	if err != nil {
+		return nil, http.StatusBadRequest, errors.New("invalid view id")
+	}
+
+	view, err := s.GetViewByID(viewID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if view == nil || (view.OwnerID != userID && view.Visibility != models.ViewVisibilityProject) {
+		return nil, http.StatusNotFound, errors.New("view not found")
+	}
+
+	isMember, err := s.IsProjectMember(view.ProjectID, userID)
+	if err != nil {
+		return nil, http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if !isMember {
+		return nil, http.StatusForbidden, errors.New("access denied")
+	}
+	return view, http.StatusOK, nil
+}
+
+// checkViewOwner allows changes to a view by its owner, and to shared views
+// by project owners and admins.
+func checkViewOwner(s *store.Store, view *models.SavedView, userID uuid.UUID) (int, error) {
+	if view.OwnerID == userID {
+		return http.StatusOK, nil
+	}
+	role, err := s.GetMemberRole(view.ProjectID, userID)
+	if err != nil {
+		return http.StatusInternalServerError, errors.New("internal server error")
+	}
+	if role != "owner" && role != "admin" {
+		return http.StatusForbidden, errors.New("only the view owner or project admins can change this view")
+	}
+	return http.StatusOK, nil
+}
+
+// viewActivity records changes to views shared with the project; private
+// views stay out of the project feed.
+func viewActivity(userID uuid.UUID, action string, before, after *models.SavedView) *models.ActivityEvent {
+	view, shared := after, false
+	if view == nil {
+		view = before
+	}
+	for _, v := range []*models.SavedView{before, after} {
+		if v != nil && v.Visibility == models.ViewVisibilityProject {
+			shared = true
+		}
+	}
+	if !shared {
+		return nil
+	}
+
+	var b, a interface{}
+	if before != nil {
+		b = before
+	}
+	if after != nil {
+		a = after
+	}
+	return store.NewActivity(view.ProjectID, userID, action, "view", view.ID, b, a)
+}
@@ -0,0 +1,172 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+func GetWorkflow(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		isMember, err := s.IsProjectMember(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if !isMember {
+			c.JSON(http.StatusForbidden, gin.H{"error": "access denied"})
+			return
+		}
+
+		workflow, err := s.GetWorkflow(projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to get workflow"})
+			return
+		}
+
+		c.JSON(http.StatusOK, workflow)
+	}
+}
+
+func UpdateWorkflow(s *store.Store) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, err := getUserID(c)
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": "unauthorized"})
+			return
+		}
+
+		projectID, err := uuid.Parse(c.Param("id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid project id"})
+			return
+		}
+
+		role, err := s.GetMemberRole(projectID, userID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+		if role != "owner" && role != "admin" {
+			c.JSON(http.StatusForbidden, gin.H{"error": "only owner or admin can change workflow"})
+			return
+		}
+
+		var req models.UpdateWorkflowRequest
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		current, err := s.GetWorkflow(projectID)
+		if err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "internal server error"})
+			return
+		}
+
+		workflow := &models.Workflow{
+			ProjectID:           projectID,
+			Statuses:            req.Statuses,
+			Transitions:         req.Transitions,
+			BlockOpenSubtasks:   req.BlockOpenSubtasks,
+			BlockOnDependencies: req.BlockOnDependencies,
+			UpdatedAt:           time.Now(),
+		}
+		if workflow.Transitions == nil {
+			workflow.Transitions = models.WorkflowTransitions{}
+		}
+		if err := workflow.Validate(); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+
+		for from, to := range req.Renames {
+			if from == to {
+				continue
+			}
+			if _, ok := workflow.Status(to); !ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("rename target %q is not a status of the workflow", to)})
+				return
+			}
+			if _, ok := workflow.Status(from); ok {
+				c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("renamed status %q is still part of the workflow", from)})
+				return
+			}
+		}
+
+		activity := store.NewActivity(projectID, userID, models.ActivityUpdated, "workflow", projectID, current, workflow)
+		if err := s.SaveWorkflow(workflow, req.Renames, activity); err != nil {
+			if errors.Is(err, store.ErrStatusInUse) {
+				c.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+				return
+			}
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "failed to save workflow"})
+			return
+		}
+
+		c.JSON(http.StatusOK, workflow)
+	}
+}
+
+// checkStatusChange validates that a task may enter its current status coming
+// from the given one (empty for new tasks). On rejection it returns the HTTP
+// status to respond with together with the error. WIP limits are checked by
+// the store when the task is saved.
+func checkStatusChange(s *store.Store, workflow *models.Workflow, task *models.Task, from string) (int, error) {
+	if from != "" && from == task.Status {
+		return http.StatusOK, nil
+	}
+
+	var err error
+	if from == "" {
+		err = workflow.CheckStatus(task.Status)
+	} else {
+		err = workflow.CheckTransition(from, task.Status)
+	}
+	if err != nil {
+		if errors.Is(err, models.ErrTransitionNotAllowed) {
+			return http.StatusConflict, err
+		}
+		return http.StatusBadRequest, err
+	}
+
+	completing := from != "" && workflow.IsDone(task.Status) && !workflow.IsDone(from)
+	if completing && workflow.BlockOpenSubtasks {
+		open, err := s.CountOpenSubtasks(task.ID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if open > 0 {
+			return http.StatusConflict, fmt.Errorf("task has %d open subtasks", open)
+		}
+	}
+	if completing && workflow.BlockOnDependencies {
+		open, err := s.CountOpenBlockers(task.ID)
+		if err != nil {
+			return http.StatusInternalServerError, err
+		}
+		if open > 0 {
+			return http.StatusConflict, fmt.Errorf("task is blocked by %d open tasks", open)
+		}
+	}
+
+	return http.StatusOK, nil
+}
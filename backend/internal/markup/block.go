@@ -0,0 +1,289 @@
+package markup
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	headingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ ]+(.*?))?(?:[ ]+#+)?[ ]*$`)
+	ruleRe    = regexp.MustCompile(`^ {0,3}(?:(?:-[ ]*){3,}|(?:\*[ ]*){3,}|(?:_[ ]*){3,})$`)
+	fenceRe   = regexp.MustCompile("^( {0,3})(`{3,}|~{3,})[ ]*([^`]*?)[ ]*$")
+	bulletRe  = regexp.MustCompile(`^( {0,3})([-*+])( +|$)`)
+	orderedRe = regexp.MustCompile(`^( {0,3})([0-9]{1,9})([.)])( +|$)`)
+	langRe    = regexp.MustCompile(`^[A-Za-z0-9_+-]+$`)
+)
+
+// renderBlocks renders lines as a sequence of blocks. In tight lists
+// paragraphs are written without <p> tags.
+func renderBlocks(out *strings.Builder, lines []string, tight bool) error {
+	for i := 0; i < len(lines); {
+		line := lines[i]
+		trimmed := strings.TrimSpace(line)
+
+		switch {
+		case trimmed == "":
+			i++
+
+		case fenceRe.MatchString(line):
+			i = renderFence(out, lines, i)
+
+		case isMathBlock(line):
+			next, err := renderDisplayMath(out, lines, i)
+			if err != nil {
+				return err
+			}
+			i = next
+
+		case headingRe.MatchString(line):
+			m := headingRe.FindStringSubmatch(line)
+			text, err := renderInline(m[2])
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "<h%d>%s</h%d>\n", len(m[1]), text, len(m[1]))
+			i++
+
+		case ruleRe.MatchString(line):
+			out.WriteString("<hr>\n")
+			i++
+
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			var quoted []string
+			for ; i < len(lines) && strings.HasPrefix(strings.TrimLeft(lines[i], " "), ">"); i++ {
+				q := strings.TrimPrefix(strings.TrimLeft(lines[i], " "), ">")
+				quoted = append(quoted, strings.TrimPrefix(q, " "))
+			}
+			out.WriteString("<blockquote>\n")
+			if err := renderBlocks(out, quoted, false); err != nil {
+				return err
+			}
+			out.WriteString("</blockquote>\n")
+
+		case bulletRe.MatchString(line) || orderedRe.MatchString(line):
+			next, err := renderList(out, lines, i)
+			if err != nil {
+				return err
+			}
+			i = next
+
+		default:
+			start := i
+			for i++; i < len(lines) && !startsBlock(lines[i]); i++ {
+			}
+			if err := renderParagraph(out, lines[start:i], tight); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// startsBlock reports whether line ends a paragraph.
+func startsBlock(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" ||
+		fenceRe.MatchString(line) ||
+		isMathBlock(line) ||
+		headingRe.MatchString(line) ||
+		ruleRe.MatchString(line) ||
+		strings.HasPrefix(strings.TrimLeft(line, " "), ">") ||
+		bulletRe.MatchString(line) || orderedRe.MatchString(line)
+}
+
+func renderParagraph(out *strings.Builder, lines []string, tight bool) error {
+	// Two trailing spaces make a hard line break, which the inline parser
+	// handles as a backslash before the newline.
+	parts := make([]string, len(lines))
+	for i, line := range lines {
+		line = strings.TrimLeft(line, " ")
+		if i < len(lines)-1 && strings.HasSuffix(line, "  ") {
+			line = strings.TrimRight(line, " ") + `\`
+		}
+		parts[i] = strings.TrimRight(line, " ")
+	}
+
+	text, err := renderInline(strings.Join(parts, "\n"))
+	if err != nil {
+		return err
+	}
+	if tight {
+		out.WriteString(text + "\n")
+	} else {
+		out.WriteString("<p>" + text + "</p>\n")
+	}
+	return nil
+}
+
+// renderFence renders a fenced code block starting at lines[start] and
+// returns the index after it. An unclosed fence runs to the end.
+func renderFence(out *strings.Builder, lines []string, start int) int {
+	m := fenceRe.FindStringSubmatch(lines[start])
+	indent, fence, info := len(m[1]), m[2], m[3]
+
+	lang := strings.Fields(info + " ")
+	out.WriteString("<pre><code")
+	if len(lang) > 0 && langRe.MatchString(lang[0]) {
+		fmt.Fprintf(out, ` class="language-%s"`, lang[0])
+	}
+	out.WriteString(">")
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimSpace(lines[i])
+		if strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == "" {
+			i++
+			break
+		}
+		line := lines[i]
+		for n := 0; n < indent && strings.HasPrefix(line, " "); n++ {
+			line = line[1:]
+		}
+		out.WriteString(html.EscapeString(line) + "\n")
+	}
+	out.WriteString("</code></pre>\n")
+	return i
+}
+
+// mathDelimiters returns the display math delimiters line opens with.
+func mathDelimiters(line string) (open, close string) {
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case strings.HasPrefix(trimmed, "$$"):
+		return "$$", "$$"
+	case strings.HasPrefix(trimmed, `\[`):
+		return `\[`, `\]`
+	}
+	return "", ""
+}
+
+// isMathBlock reports whether line starts a display math block: it opens
+// with $$ or \[ and the formula either ends the line or continues on the
+// next ones. "$$x$$ is…" is a paragraph with inline display math.
+func isMathBlock(line string) bool {
+	open, close := mathDelimiters(line)
+	if open == "" {
+		return false
+	}
+	rest := strings.TrimPrefix(strings.TrimSpace(line), open)
+	i := strings.Index(rest, close)
+	return i < 0 || i == len(rest)-len(close)
+}
+
+// renderDisplayMath renders the display math block starting at lines[start]
+// and returns the index after it.
+func renderDisplayMath(out *strings.Builder, lines []string, start int) (int, error) {
+	open, close := mathDelimiters(lines[start])
+	body := strings.TrimPrefix(strings.TrimSpace(lines[start]), open)
+
+	i := start + 1
+	if !strings.HasSuffix(body, close) {
+		for {
+			if i >= len(lines) {
+				return 0, &Error{Formula: open + body, Message: "unterminated display math"}
+			}
+			line := strings.TrimRight(lines[i], " ")
+			i++
+			body += "\n" + line
+			if strings.HasSuffix(line, close) {
+				break
+			}
+		}
+	}
+	tex := strings.TrimSpace(strings.TrimSuffix(body, close))
+
+	if err := ValidateLaTeX(tex); err != nil {
+		return 0, err
+	}
+	out.WriteString(`<div class="math math-display">` + html.EscapeString(tex) + "</div>\n")
+	return i, nil
+}
+
+type listItem struct {
+	lines []string
+}
+
+// renderList renders a bullet or ordered list starting at lines[start] and
+// returns the index after it.
+func renderList(out *strings.Builder, lines []string, start int) (int, error) {
+	ordered := orderedRe.MatchString(lines[start]) && !bulletRe.MatchString(lines[start])
+	marker := func(line string) (delim string, contentIndent int, rest string, ok bool) {
+		if ordered {
+			m := orderedRe.FindStringSubmatch(line)
+			if m == nil {
+				return "", 0, "", false
+			}
+			return m[3], len(m[0]), line[len(m[0]):], true
+		}
+		m := bulletRe.FindStringSubmatch(line)
+		if m == nil {
+			return "", 0, "", false
+		}
+		return m[2], len(m[0]), line[len(m[0]):], true
+	}
+
+	delim, indent, rest, _ := marker(lines[start])
+	items := []listItem{{lines: []string{rest}}}
+	tight := true
+	blank := false
+
+	i := start + 1
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if strings.TrimSpace(line) == "" {
+			blank = true
+			continue
+		}
+		leading := len(line) - len(strings.TrimLeft(line, " "))
+		d, n, r, isItem := marker(line)
+		switch {
+		case leading < indent && isItem && d == delim && !ruleRe.MatchString(line):
+			if blank {
+				tight = false
+			}
+			items = append(items, listItem{lines: []string{r}})
+			indent = n
+		case leading >= indent:
+			if blank {
+				tight = false
+				items[len(items)-1].lines = append(items[len(items)-1].lines, "")
+			}
+			items[len(items)-1].lines = append(items[len(items)-1].lines, line[indent:])
+		case !blank && !startsBlock(line):
+			// A lazy continuation of the item's last paragraph.
+			items[len(items)-1].lines = append(items[len(items)-1].lines, line)
+		default:
+			return i, writeList(out, lines[start], ordered, items, tight)
+		}
+		blank = false
+	}
+	return i, writeList(out, lines[start], ordered, items, tight)
+}
+
+func writeList(out *strings.Builder, first string, ordered bool, items []listItem, tight bool) error {
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+	}
+	out.WriteString("<" + tag)
+	if m := orderedRe.FindStringSubmatch(first); ordered && strings.TrimLeft(m[2], "0") != "1" {
+		n := strings.TrimLeft(m[2], "0")
+		if n == "" {
+			n = "0"
+		}
+		fmt.Fprintf(out, ` start="%s"`, n)
+	}
+	out.WriteString(">\n")
+
+	for _, item := range items {
+		var body strings.Builder
+		if err := renderBlocks(&body, item.lines, tight); err != nil {
+			return err
+		}
+		out.WriteString("<li>" + strings.TrimSuffix(body.String(), "\n") + "</li>\n")
+	}
+	out.WriteString("</" + tag + ">\n")
+	return nil
+}
@@ -0,0 +1,387 @@
+package markup
+
+import (
+	"html"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const escapable = "\\`*_{}[]()#+-.!|~<>$&\"'"
+
+// renderInline renders the inline content of a paragraph or heading. Spans
+// are rendered as they are met; runs of * and _ are collected and paired up
+// afterwards in a single pass, as CommonMark's "process emphasis" does.
+func renderInline(s string) (string, error) {
+	var nodes []inlineNode
+	var runs []*delimiterRun
+	var text strings.Builder
+	flush := func() {
+		if text.Len() > 0 {
+			nodes = append(nodes, inlineNode{html: text.String()})
+			text.Reset()
+		}
+	}
+	for i := 0; i < len(s); {
+		if s[i] == '*' || s[i] == '_' {
+			run := scanDelimiterRun(s, i)
+			flush()
+			nodes = append(nodes, inlineNode{run: run})
+			runs = append(runs, run)
+			i += run.length
+			continue
+		}
+		n, err := renderSpan(&text, s, i)
+		if err != nil {
+			return "", err
+		}
+		if n > 0 {
+			i += n
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		text.WriteString(html.EscapeString(string(r)))
+		i += size
+	}
+	flush()
+
+	processEmphasis(runs)
+	var out strings.Builder
+	for _, node := range nodes {
+		if node.run == nil {
+			out.WriteString(node.html)
+			continue
+		}
+		r := node.run
+		for _, tag := range r.closes {
+			out.WriteString("</" + tag + ">")
+		}
+		out.WriteString(strings.Repeat(string(r.char), r.count))
+		for j := len(r.opens) - 1; j >= 0; j-- {
+			out.WriteString("<" + r.opens[j] + ">")
+		}
+	}
+	return out.String(), nil
+}
+
+// renderSpan renders the inline element starting at s[i], if any, and
+// returns the number of bytes it consumed.
+func renderSpan(out *strings.Builder, s string, i int) (int, error) {
+	rest := s[i:]
+	switch rest[0] {
+	case '\\':
+		return renderEscape(out, s, i)
+
+	case '`':
+		run := len(rest) - len(strings.TrimLeft(rest, "`"))
+		end := findRun(rest[run:], "`", run)
+		if end < 0 {
+			out.WriteString(rest[:run])
+			return run, nil
+		}
+		code := rest[run : run+end]
+		if len(code) > 1 && code[0] == ' ' && code[len(code)-1] == ' ' && strings.TrimSpace(code) != "" {
+			code = code[1 : len(code)-1]
+		}
+		out.WriteString("<code>" + html.EscapeString(strings.ReplaceAll(code, "\n", " ")) + "</code>")
+		return 2*run + end, nil
+
+	case '$':
+		if strings.HasPrefix(rest, "$$") {
+			end := strings.Index(rest[2:], "$$")
+			if end < 0 {
+				return 0, nil
+			}
+			return 4 + end, writeMath(out, rest[2:2+end], true)
+		}
+		end := closingDollar(rest)
+		if end < 0 {
+			return 0, nil
+		}
+		return end + 1, writeMath(out, rest[1:end], false)
+
+	case '[':
+		return renderLink(out, rest)
+
+	case '<':
+		end := strings.IndexByte(rest, '>')
+		if end < 0 {
+			return 0, nil
+		}
+		url := rest[1:end]
+		if strings.ContainsAny(url, " \n<") || !safeURL(url) || strings.HasPrefix(url, "/") || strings.HasPrefix(url, "#") {
+			return 0, nil
+		}
+		writeLink(out, url, html.EscapeString(url))
+		return end + 1, nil
+	}
+	return 0, nil
+}
+
+func renderEscape(out *strings.Builder, s string, i int) (int, error) {
+	if i+1 >= len(s) {
+		return 0, nil
+	}
+	switch next := s[i+1]; {
+	case next == '(' || next == '[':
+		open, close := s[i:i+2], `\)`
+		if next == '[' {
+			close = `\]`
+		}
+		end := strings.Index(s[i+2:], close)
+		if end < 0 {
+			return 0, &Error{Formula: open + s[i+2:], Message: "unterminated math"}
+		}
+		return end + 4, writeMath(out, s[i+2:i+2+end], next == '[')
+	case next == '\n':
+		out.WriteString("<br>\n")
+		return 2, nil
+	case strings.IndexByte(escapable, next) >= 0:
+		out.WriteString(html.EscapeString(string(next)))
+		return 2, nil
+	}
+	return 0, nil
+}
+
+// closingDollar finds the $ closing the inline formula opened at s[0], using
+// Pandoc's rules so that prices like "$5 and $10" stay text: the formula may
+// not start or end with a space and the closing $ may not precede a digit.
+func closingDollar(s string) int {
+	if len(s) < 3 || s[1] == ' ' || s[1] == '\n' {
+		return -1
+	}
+	for j := 2; j < len(s); j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '$':
+			// A formula cannot contain a bare $, so one that cannot close
+			// it means the opening $ was just a dollar sign.
+			if s[j-1] == ' ' || s[j-1] == '\n' || j+1 < len(s) && s[j+1] >= '0' && s[j+1] <= '9' {
+				return -1
+			}
+			return j
+		}
+	}
+	return -1
+}
+
+func writeMath(out *strings.Builder, tex string, display bool) error {
+	tex = strings.TrimSpace(tex)
+	if err := ValidateLaTeX(tex); err != nil {
+		return err
+	}
+	class := "math math-inline"
+	if display {
+		class = "math math-display"
+	}
+	out.WriteString(`<span class="` + class + `">` + html.EscapeString(tex) + "</span>")
+	return nil
+}
+
+// inlineNode is a piece of rendered inline content or a delimiter run whose
+// rendering depends on what it is paired with.
+type inlineNode struct {
+	html string
+	run  *delimiterRun
+}
+
+// delimiterRun is a run of * or _. Count is the number of its characters not
+// yet used for emphasis; opens and closes are the tags the others became,
+// innermost first.
+// Unpaired runs are linked through prev and next, indexes into the runs of
+// the paragraph.
+type delimiterRun struct {
+	char              byte
+	length, count     int
+	canOpen, canClose bool
+	prev, next        int
+	opens, closes     []string
+}
+
+// scanDelimiterRun reads the run of * or _ at s[i] and decides by the
+// characters around it whether it can open or close emphasis. Underscores
+// inside words do neither, so snake_case names stay intact.
+func scanDelimiterRun(s string, i int) *delimiterRun {
+	j := i
+	for j < len(s) && s[j] == s[i] {
+		j++
+	}
+	before, after := ' ', ' '
+	if i > 0 {
+		before, _ = utf8.DecodeLastRuneInString(s[:i])
+	}
+	if j < len(s) {
+		after, _ = utf8.DecodeRuneInString(s[j:])
+	}
+	left := !unicode.IsSpace(after) && (!isPunct(after) || unicode.IsSpace(before) || isPunct(before))
+	right := !unicode.IsSpace(before) && (!isPunct(before) || unicode.IsSpace(after) || isPunct(after))
+
+	run := &delimiterRun{char: s[i], length: j - i, count: j - i, canOpen: left, canClose: right}
+	if run.char == '_' {
+		run.canOpen = left && (!right || isPunct(before))
+		run.canClose = right && (!left || isPunct(after))
+	}
+	return run
+}
+
+// processEmphasis pairs closing runs with the nearest matching opener.
+// Runs between a pair can no longer match and are unlinked, and a failed
+// search records where the next one for the same kind of closer may stop,
+// so each run is visited a bounded number of times.
+func processEmphasis(runs []*delimiterRun) {
+	for i, r := range runs {
+		r.prev, r.next = i-1, i+1
+	}
+	bottom := make(map[[3]int]int)
+	for c := 0; c < len(runs); {
+		closer := runs[c]
+		if !closer.canClose {
+			c = closer.next
+			continue
+		}
+		key := [3]int{int(closer.char), 0, closer.length % 3}
+		if closer.canOpen {
+			key[1] = 1
+		}
+		floor, ok := bottom[key]
+		if !ok {
+			floor = -1
+		}
+		o := closer.prev
+		for ; o > floor; o = runs[o].prev {
+			if opener := runs[o]; opener.char == closer.char && opener.canOpen && !ruleOfThree(opener, closer) {
+				break
+			}
+		}
+		if o <= floor {
+			bottom[key] = closer.prev
+			next := closer.next
+			if !closer.canOpen {
+				unlinkRun(runs, c)
+			}
+			c = next
+			continue
+		}
+
+		opener := runs[o]
+		use, tag := 1, "em"
+		if opener.count >= 2 && closer.count >= 2 {
+			use, tag = 2, "strong"
+		}
+		opener.count -= use
+		closer.count -= use
+		opener.opens = append(opener.opens, tag)
+		closer.closes = append(closer.closes, tag)
+
+		opener.next, closer.prev = c, o
+		if opener.count == 0 {
+			unlinkRun(runs, o)
+		}
+		if closer.count == 0 {
+			next := closer.next
+			unlinkRun(runs, c)
+			c = next
+		}
+	}
+}
+
+// ruleOfThree is CommonMark's rule that a run which can both open and close
+// only pairs with one whose combined length is not a multiple of three,
+// unless both lengths are, so that *foo**bar**baz* nests the strong run.
+func ruleOfThree(opener, closer *delimiterRun) bool {
+	return (opener.canClose || closer.canOpen) &&
+		(opener.length+closer.length)%3 == 0 &&
+		!(opener.length%3 == 0 && closer.length%3 == 0)
+}
+
+func unlinkRun(runs []*delimiterRun, i int) {
+	prev, next := runs[i].prev, runs[i].next
+	if prev >= 0 {
+		runs[prev].next = next
+	}
+	if next < len(runs) {
+		runs[next].prev = prev
+	}
+}
+
+// renderLink handles [text](url). Links to unsafe URLs keep only their text.
+func renderLink(out *strings.Builder, s string) (int, error) {
+	depth, close := 0, -1
+	for j := 0; j < len(s) && close < 0; j++ {
+		switch s[j] {
+		case '\\':
+			j++
+		case '[':
+			depth++
+		case ']':
+			depth--
+			if depth == 0 {
+				close = j
+			}
+		}
+	}
+	if close < 0 || close+1 >= len(s) || s[close+1] != '(' {
+		return 0, nil
+	}
+	// URLs may contain balanced parentheses, as Wikipedia links often do.
+	end := -1
+	for j, depth := close+2, 0; j < len(s) && end < 0; j++ {
+		switch s[j] {
+		case '(':
+			depth++
+		case ')':
+			if depth == 0 {
+				end = j - close - 2
+			}
+			depth--
+		}
+	}
+	if end < 0 {
+		return 0, nil
+	}
+	url := strings.TrimSpace(s[close+2 : close+2+end])
+	if strings.ContainsAny(url, " \n") {
+		return 0, nil
+	}
+
+	text, err := renderInline(s[1:close])
+	if err != nil {
+		return 0, err
+	}
+	if safeURL(url) {
+		writeLink(out, url, text)
+	} else {
+		out.WriteString(text)
+	}
+	return close + 3 + end, nil
+}
+
+func writeLink(out *strings.Builder, url, text string) {
+	out.WriteString(`<a href="` + html.EscapeString(url) + `" rel="nofollow noopener noreferrer">` + text + "</a>")
+}
+
+// findRun returns the index of the first run of exactly n delim characters
+// in s, or -1.
+func findRun(s, delim string, n int) int {
+	for j := 0; j < len(s); {
+		k := strings.Index(s[j:], delim)
+		if k < 0 {
+			return -1
+		}
+		start := j + k
+		end := start
+		for end < len(s) && s[end] == delim[0] {
+			end++
+		}
+		if end-start == n {
+			return start
+		}
+		j = end
+	}
+	return -1
+}
+
+func isPunct(r rune) bool {
+	return unicode.IsPunct(r) || unicode.IsSymbol(r)
+}
@@ -0,0 +1,362 @@
+package markup
+
+import (
+	"strings"
+	"unicode"
+)
+
+// commands maps the LaTeX commands allowed in formulas to the number of
+// arguments they take. It is the subset KaTeX renders, minus anything that
+// could load resources or define macros.
+var commands = map[string]int{}
+
+// textCommands take a text-mode argument that is not parsed as math.
+var textCommands = map[string]bool{
+	"text": true, "textrm": true, "textbf": true, "textit": true, "textsf": true,
+	"texttt": true, "textnormal": true, "mbox": true, "operatorname": true,
+}
+
+// environments lists the allowed \begin{…} environments; array-like ones
+// take a column specification.
+var environments = map[string]bool{
+	"matrix": false, "pmatrix": false, "bmatrix": false, "Bmatrix": false,
+	"vmatrix": false, "Vmatrix": false, "smallmatrix": false, "cases": false,
+	"aligned": false, "gathered": false, "split": false, "array": true,
+}
+
+var delimiters = map[string]bool{
+	"(": true, ")": true, "[": true, "]": true, "|": true, ".": true, "/": true, "<": true, ">": true,
+	`\{`: true, `\}`: true, `\|`: true, `\langle`: true, `\rangle`: true, `\lvert`: true, `\rvert`: true,
+	`\lVert`: true, `\rVert`: true, `\lfloor`: true, `\rfloor`: true, `\lceil`: true, `\rceil`: true,
+	`\vert`: true, `\Vert`: true, `\uparrow`: true, `\downarrow`: true, `\updownarrow`: true, `\backslash`: true,
+}
+
+func init() {
+	symbols := []string{
+		// Greek letters
+		"alpha", "beta", "gamma", "delta", "epsilon", "varepsilon", "zeta", "eta", "theta", "vartheta",
+		"iota", "kappa", "lambda", "mu", "nu", "xi", "pi", "varpi", "rho", "varrho", "sigma", "varsigma",
+		"tau", "upsilon", "phi", "varphi", "chi", "psi", "omega",
+		"Gamma", "Delta", "Theta", "Lambda", "Xi", "Pi", "Sigma", "Upsilon", "Phi", "Psi", "Omega",
+		// Binary operators and relations
+		"pm", "mp", "times", "div", "cdot", "ast", "star", "circ", "bullet", "oplus", "ominus", "otimes",
+		"odot", "cap", "cup", "wedge", "vee", "setminus", "land", "lor",
+		"leq", "le", "geq", "ge", "neq", "ne", "ll", "gg", "approx", "sim", "simeq", "cong", "equiv",
+		"propto", "subset", "supset", "subseteq", "supseteq", "in", "notin", "ni", "perp", "parallel",
+		"mid", "nmid", "prec", "succ", "preceq", "succeq", "models", "vdash", "lesssim", "gtrsim",
+		"leqslant", "geqslant", "doteq", "coloneqq",
+		// Arrows
+		"to", "gets", "leftarrow", "rightarrow", "leftrightarrow", "Leftarrow", "Rightarrow",
+		"Leftrightarrow", "implies", "impliedby", "iff", "mapsto", "longrightarrow", "longleftarrow",
+		"Longrightarrow", "Longleftarrow", "uparrow", "downarrow", "updownarrow", "rightleftharpoons",
+		"hookrightarrow", "nearrow", "searrow",
+		// Big operators and functions
+		"sum", "prod", "coprod", "int", "iint", "iiint", "oint", "bigcup", "bigcap", "bigoplus",
+		"bigotimes", "lim", "limsup", "liminf", "sup", "inf", "max", "min", "arg", "argmax", "argmin",
+		"sin", "cos", "tan", "cot", "sec", "csc", "arcsin", "arccos", "arctan", "sinh", "cosh", "tanh",
+		"coth", "exp", "log", "ln", "lg", "det", "dim", "ker", "deg", "gcd", "Pr", "hom", "bmod",
+		// Miscellaneous symbols
+		"infty", "partial", "nabla", "hbar", "ell", "Re", "Im", "aleph", "forall", "exists", "nexists",
+		"neg", "lnot", "emptyset", "varnothing", "angle", "triangle", "square", "prime", "degree",
+		"dagger", "ddagger", "top", "bot", "cdots", "ldots", "dots", "vdots", "ddots", "therefore",
+		"because", "checkmark", "langle", "rangle", "lvert", "rvert", "lVert", "rVert", "vert", "Vert",
+		"lfloor", "rfloor", "lceil", "rceil", "backslash", "colon",
+		// Spacing and style
+		"quad", "qquad", "enspace", "thinspace", "medspace", "thickspace", "negthinspace",
+		"displaystyle", "textstyle", "scriptstyle", "limits", "nolimits",
+		"big", "Big", "bigg", "Bigg", "bigl", "bigr", "Bigl", "Bigr", "biggl", "biggr",
+	}
+	for _, name := range symbols {
+		commands[name] = 0
+	}
+
+	unary := []string{
+		"sqrt", "hat", "widehat", "bar", "overline", "underline", "vec", "overrightarrow",
+		"overleftarrow", "dot", "ddot", "tilde", "widetilde", "acute", "grave", "breve", "check",
+		"overbrace", "underbrace", "mathrm", "mathbf", "mathit", "mathsf", "mathtt", "mathcal",
+		"mathbb", "mathfrak", "mathscr", "boldsymbol", "bm", "pmod", "cancel", "boxed", "phantom",
+	}
+	for _, name := range unary {
+		commands[name] = 1
+	}
+	for name := range textCommands {
+		commands[name] = 1
+	}
+
+	for _, name := range []string{"frac", "dfrac", "tfrac", "cfrac", "binom", "dbinom", "tbinom", "overset", "underset", "stackrel"} {
+		commands[name] = 2
+	}
+}
+
+// ValidateLaTeX checks that tex is a well-formed formula built from the
+// allowed commands: braces, \left/\right and environments must balance,
+// commands must get their arguments and scripts need an operand.
+func ValidateLaTeX(tex string) error {
+	if strings.TrimSpace(tex) == "" {
+		return &Error{Formula: tex, Message: "empty formula"}
+	}
+	p := &latexParser{src: tex}
+	if err := p.parseGroup("", 0); err != nil {
+		return &Error{Formula: tex, Message: err.Error()}
+	}
+	return nil
+}
+
+type latexError string
+
+func (e latexError) Error() string { return string(e) }
+
+type latexParser struct {
+	src string
+	pos int
+}
+
+// next returns the next token: a command with its backslash, a control
+// symbol such as \, or a single character. Whitespace is skipped.
+func (p *latexParser) next() string {
+	for p.pos < len(p.src) && unicode.IsSpace(rune(p.src[p.pos])) {
+		p.pos++
+	}
+	if p.pos >= len(p.src) {
+		return ""
+	}
+	start := p.pos
+	if p.src[p.pos] == '\\' {
+		p.pos++
+		for p.pos < len(p.src) && isASCIILetter(p.src[p.pos]) {
+			p.pos++
+		}
+		if p.pos == start+1 && p.pos < len(p.src) {
+			p.pos++
+		}
+		return p.src[start:p.pos]
+	}
+	p.pos++
+	for p.pos < len(p.src) && p.src[p.pos]&0xC0 == 0x80 {
+		p.pos++
+	}
+	return p.src[start:p.pos]
+}
+
+func (p *latexParser) peek() string {
+	pos := p.pos
+	tok := p.next()
+	p.pos = pos
+	return tok
+}
+
+// parseGroup parses tokens until the closing brace ("}") or the \end of env,
+// or to the end of input when end is empty.
+func (p *latexParser) parseGroup(end string, depth int) error {
+	if depth > 50 {
+		return latexError("formula is nested too deeply")
+	}
+	lefts := 0
+	hasSup, hasSub := false, false
+
+	for {
+		tok := p.next()
+		if end == "]" && tok == "]" {
+			return nil
+		}
+		switch tok {
+		case "":
+			if end != "" {
+				return latexError("missing " + end)
+			}
+			if lefts > 0 {
+				return latexError(`\left without matching \right`)
+			}
+			return nil
+
+		case "}":
+			if end != "}" {
+				return latexError("unexpected }")
+			}
+			if lefts > 0 {
+				return latexError(`\left without matching \right`)
+			}
+			return nil
+
+		case "{":
+			if err := p.parseGroup("}", depth+1); err != nil {
+				return err
+			}
+			hasSup, hasSub = false, false
+
+		case "^", "_":
+			if tok == "^" && hasSup {
+				return latexError("double superscript")
+			}
+			if tok == "_" && hasSub {
+				return latexError("double subscript")
+			}
+			if next := p.peek(); next == "" || next == "}" || next == "^" || next == "_" || next == "&" || next == `\\` {
+				return latexError("missing argument for " + tok)
+			}
+			if err := p.parseArg(depth); err != nil {
+				return err
+			}
+			if tok == "^" {
+				hasSup = true
+			} else {
+				hasSub = true
+			}
+
+		case `\\`:
+			hasSup, hasSub = false, false
+
+		case "&":
+			if !strings.HasPrefix(end, `\end`) {
+				return latexError("& outside of an environment")
+			}
+			hasSup, hasSub = false, false
+
+		case "#", "%", "$":
+			return latexError("unsupported character " + tok)
+
+		case `\left`, `\middle`, `\right`:
+			if !delimiters[p.next()] {
+				return latexError("missing delimiter after " + tok)
+			}
+			switch tok {
+			case `\left`:
+				lefts++
+			case `\right`:
+				if lefts == 0 {
+					return latexError(`\right without matching \left`)
+				}
+				lefts--
+			default:
+				if lefts == 0 {
+					return latexError(`\middle outside of \left…\right`)
+				}
+			}
+			hasSup, hasSub = false, false
+
+		case `\begin`:
+			env, err := p.braced()
+			if err != nil {
+				return latexError(`\begin needs an environment name`)
+			}
+			needsSpec, ok := environments[env]
+			if !ok {
+				return latexError("unknown environment " + env)
+			}
+			if needsSpec {
+				if _, err := p.braced(); err != nil {
+					return latexError(env + " needs a column specification")
+				}
+			}
+			if err := p.parseGroup(`\end{`+env+`}`, depth+1); err != nil {
+				return err
+			}
+			hasSup, hasSub = false, false
+
+		case `\end`:
+			env, err := p.braced()
+			if err != nil {
+				return latexError(`\end needs an environment name`)
+			}
+			if end != `\end{`+env+`}` {
+				return latexError(`unexpected \end{` + env + "}")
+			}
+			if lefts > 0 {
+				return latexError(`\left without matching \right`)
+			}
+			return nil
+
+		default:
+			if err := p.parseAtom(tok, depth); err != nil {
+				return err
+			}
+			hasSup, hasSub = false, false
+		}
+	}
+}
+
+// parseAtom checks a command or character and consumes its arguments.
+func (p *latexParser) parseAtom(tok string, depth int) error {
+	if tok[0] != '\\' {
+		return nil
+	}
+	if len(tok) == 2 && !isASCIILetter(tok[1]) {
+		if strings.ContainsRune(`,;:! {}$%&#_|`, rune(tok[1])) {
+			return nil
+		}
+		return latexError("unknown command " + tok)
+	}
+
+	name := tok[1:]
+	arity, ok := commands[name]
+	if !ok {
+		return latexError("unknown command " + tok)
+	}
+	if textCommands[name] {
+		if _, err := p.braced(); err != nil {
+			return latexError(tok + " needs a braced argument")
+		}
+		return nil
+	}
+	if name == "sqrt" && p.peek() == "[" {
+		p.next()
+		if err := p.parseGroup("]", depth+1); err != nil {
+			return err
+		}
+	}
+	for i := 0; i < arity; i++ {
+		if err := p.parseArg(depth); err != nil {
+			return latexError(tok + " needs " + argCount(arity))
+		}
+	}
+	return nil
+}
+
+// parseArg consumes one argument: a braced group or a single token.
+func (p *latexParser) parseArg(depth int) error {
+	tok := p.next()
+	switch tok {
+	case "", "}", "^", "_", "&", `\\`:
+		return latexError("missing argument")
+	case "{":
+		return p.parseGroup("}", depth+1)
+	}
+	if tok == `\left` || tok == `\right` || tok == `\begin` || tok == `\end` {
+		return latexError("missing argument")
+	}
+	return p.parseAtom(tok, depth)
+}
+
+// braced reads a {…} group verbatim, allowing nested braces.
+func (p *latexParser) braced() (string, error) {
+	if p.next() != "{" {
+		return "", latexError("missing {")
+	}
+	start, depth := p.pos, 1
+	for ; p.pos < len(p.src); p.pos++ {
+		switch p.src[p.pos] {
+		case '\\':
+			p.pos++
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				p.pos++
+				return p.src[start : p.pos-1], nil
+			}
+		}
+	}
+	return "", latexError("missing }")
+}
+
+func argCount(n int) string {
+	if n == 1 {
+		return "an argument"
+	}
+	return "2 arguments"
+}
+
+func isASCIILetter(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z'
+}
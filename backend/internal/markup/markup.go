@@ -0,0 +1,79 @@
+// Package markup renders the Markdown used in comments and task descriptions
+// to HTML.
+//
+// The renderer never passes raw HTML through: all source text is escaped and
+// only the tags the renderer emits itself reach the output, so the result is
+// safe to embed as is. Inline ($…$, \(…\)) and display ($$…$$, \[…\]) LaTeX
+// is validated and emitted as escaped source inside elements with the "math"
+// class, which clients typeset with KaTeX.
+package markup
+
+import (
+	"fmt"
+	"html"
+	"strings"
+)
+
+// Error reports a formula that is not valid LaTeX.
+type Error struct {
+	Formula string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid LaTeX in %q: %s", e.Formula, e.Message)
+}
+
+// Render converts Markdown source to sanitized HTML. It fails with *Error if
+// a formula is malformed.
+func Render(src string) (string, error) {
+	if strings.TrimSpace(src) == "" {
+		return "", nil
+	}
+	src = strings.ReplaceAll(src, "\r\n", "\n")
+	src = strings.ReplaceAll(src, "\r", "\n")
+	src = strings.ReplaceAll(src, "\t", "    ")
+
+	var out strings.Builder
+	if err := renderBlocks(&out, strings.Split(src, "\n"), false); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+// Plain renders src as a single paragraph of escaped text with line breaks,
+// without any Markdown. Migration 000024 backfills existing rows the same way.
+func Plain(src string) string {
+	if src == "" {
+		return ""
+	}
+	text := html.EscapeString(src)
+	return "<p>" + strings.ReplaceAll(text, "\n", "<br>\n") + "</p>\n"
+}
+
+// Safe renders src, falling back to Plain for content that does not render,
+// such as text written before Markdown was supported.
+func Safe(src string) string {
+	rendered, err := Render(src)
+	if err != nil {
+		return Plain(src)
+	}
+	return rendered
+}
+
+// safeURL reports whether a link target may be emitted. Only web and mail
+// links and same-site paths are allowed, which rules out javascript: and
+// data: URLs. Backslashes are rejected outright, since browsers read them as
+// slashes and /\host would be a link to another site.
+func safeURL(url string) bool {
+	if strings.Contains(url, `\`) || strings.HasPrefix(url, "//") {
+		return false
+	}
+	lower := strings.ToLower(url)
+	for _, prefix := range []string{"http://", "https://", "mailto:", "/", "#"} {
+		if strings.HasPrefix(lower, prefix) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package markup
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRender(t *testing.T) {
+	const rel = ` rel="nofollow noopener noreferrer"`
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"raw html", "<script>alert(1)</script>", "<p>&lt;script&gt;alert(1)&lt;/script&gt;</p>\n"},
+		{"link", "[site](https://example.com/a?b=1&c=2)", `<p><a href="https://example.com/a?b=1&amp;c=2"` + rel + ">site</a></p>\n"},
+		{"link with parentheses", "[x](https://en.wikipedia.org/wiki/Go_(game))", `<p><a href="https://en.wikipedia.org/wiki/Go_(game)"` + rel + ">x</a></p>\n"},
+		{"unsafe link", "[x](javascript:alert(1))", "<p>x</p>\n"},
+		{"backslash link", `[x](/\evil.example)`, "<p>x</p>\n"},
+		{"autolink", "<https://example.com>", `<p><a href="https://example.com"` + rel + ">https://example.com</a></p>\n"},
+		{"emphasis", "*a* and **b**", "<p><em>a</em> and <strong>b</strong></p>\n"},
+		{"triple emphasis", "***a***", "<p><em><strong>a</strong></em></p>\n"},
+		{"nested emphasis", "*a **b** c*", "<p><em>a <strong>b</strong> c</em></p>\n"},
+		{"rule of three", "*foo**bar**baz*", "<p><em>foo<strong>bar</strong>baz</em></p>\n"},
+		{"unbalanced emphasis", "**a*", "<p>*<em>a</em></p>\n"},
+		{"spaced asterisks", "a * b * c", "<p>a * b * c</p>\n"},
+		{"snake case", "snake_case_name", "<p>snake_case_name</p>\n"},
+		{"underscore emphasis", "_a_ b", "<p><em>a</em> b</p>\n"},
+		{"escaped asterisk", `\*a*`, "<p>*a*</p>\n"},
+		{"code span", "`a *b* <c>`", "<p><code>a *b* &lt;c&gt;</code></p>\n"},
+		{"bullet list", "- one\n- two", "<ul>\n<li>one</li>\n<li>two</li>\n</ul>\n"},
+		{"ordered list", "1. one\n2. two", "<ol>\n<li>one</li>\n<li>two</li>\n</ol>\n"},
+		{"fence", "```go\nx := 1 < 2\n```", `<pre><code class="language-go">x := 1 &lt; 2` + "\n</code></pre>\n"},
+		{"prices", "$5 and $10", "<p>$5 and $10</p>\n"},
+		{"inline math", "$x^2$", `<p><span class="math math-inline">x^2</span></p>` + "\n"},
+		{"display math", `\[a < b\]`, `<div class="math math-display">a &lt; b</div>` + "\n"},
+		{"blank", "  \n", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(tt.in)
+			if err != nil {
+				t.Fatalf("Render(%q): %v", tt.in, err)
+			}
+			if got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRejectsLaTeX(t *testing.T) {
+	for _, in := range []string{
+		`$\href{javascript:alert(1)}{x}$`,
+		`\(\def\x{1}\)`,
+		`$$\url{https://example.com}$$`,
+		`\(x`,
+	} {
+		_, err := Render(in)
+		var latexErr *Error
+		if !errors.As(err, &latexErr) {
+			t.Errorf("Render(%q) error = %v, want *Error", in, err)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/a?b=1", true},
+		{"http://example.com", true},
+		{"mailto:someone@example.com", true},
+		{"/projects/1", true},
+		{"#section", true},
+		{"javascript:alert(1)", false},
+		{"JaVaScRiPt:alert(1)", false},
+		{"data:text/html;base64,PHNjcmlwdD4=", false},
+		{"vbscript:msgbox(1)", false},
+		{"//evil.example", false},
+		{`/\evil.example`, false},
+		{`https://example.com\@evil.example`, false},
+		{"jav&#97;script:alert(1)", false},
+		{"&#106;avascript:alert(1)", false},
+		{"&#x2F;&#x2F;evil.example", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
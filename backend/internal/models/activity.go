@@ -0,0 +1,52 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ActivityCreated     = "created"
+	ActivityUpdated     = "updated"
+	ActivityDeleted     = "deleted"
+	ActivityAdded       = "added"
+	ActivityRemoved     = "removed"
+	ActivityCleared     = "cleared"
+	ActivityRoleChanged = "role_changed"
+	ActivityTransferred = "ownership_transferred"
+	ActivityMoved       = "moved"
+)
+
+type ActivityEvent struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	ProjectID  uuid.UUID       `json:"project_id" db:"project_id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	Before     json.RawMessage `json:"before,omitempty" db:"before"`
+	After      json.RawMessage `json:"after,omitempty" db:"after"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type ActivityEventWithUser struct {
+	ActivityEvent
+	ActorName  *string `json:"actor_name,omitempty" db:"actor_name"`
+	ActorEmail *string `json:"actor_email,omitempty" db:"actor_email"`
+}
+
+type ActivityFilter struct {
+	UserID     *uuid.UUID
+	EntityType string
+	From       *time.Time
+	To         *time.Time
+	Cursor     string
+	Limit      int
+}
+
+type ActivityPage struct {
+	Events     []ActivityEventWithUser `json:"events"`
+	NextCursor string                  `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,95 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// ArchiveSchemaVersion is bumped whenever the layout of the exported JSON
+// manifests changes in a way older importers cannot read.
+const ArchiveSchemaVersion = 1
+
+type ArchiveManifest struct {
+	SchemaVersion   int       `json:"schema_version"`
+	ExportedAt      time.Time `json:"exported_at"`
+	ExportedBy      uuid.UUID `json:"exported_by"`
+	SourceProjectID uuid.UUID `json:"source_project_id"`
+}
+
+type ArchiveMember struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Name   string    `json:"name"`
+	Role   string    `json:"role"`
+}
+
+type ArchiveTask struct {
+	Task
+	TagIDs    []uuid.UUID     `json:"tag_ids"`
+	Checklist []ChecklistItem `json:"checklist,omitempty"`
+	BlockedBy []uuid.UUID     `json:"blocked_by,omitempty"`
+	Watchers  []uuid.UUID     `json:"watchers,omitempty"`
+}
+
+type ArchiveBoard struct {
+	ID        uuid.UUID       `json:"id"`
+	Name      string          `json:"name"`
+	Data      json.RawMessage `json:"data"`
+	Settings  json.RawMessage `json:"settings"`
+	CreatedAt time.Time       `json:"created_at"`
+	UpdatedAt time.Time       `json:"updated_at"`
+}
+
+type ArchiveAttachment struct {
+	Attachment
+	// ArchivePath is the location of the file inside the archive, empty when
+	// the file was missing on disk at export time.
+	ArchivePath string `json:"archive_path,omitempty"`
+}
+
+// ProjectArchive is the exported content of a project. Workflow is only
+// set for projects that defined their own, and is optional on import.
+type ProjectArchive struct {
+	Manifest    ArchiveManifest     `json:"manifest"`
+	Project     Project             `json:"project"`
+	Workflow    *Workflow           `json:"workflow,omitempty"`
+	Members     []ArchiveMember     `json:"members"`
+	Tasks       []ArchiveTask       `json:"tasks"`
+	Tags        []Tag               `json:"tags"`
+	Comments    []TaskComment       `json:"comments"`
+	Boards      []ArchiveBoard      `json:"boards"`
+	Constants   []Constant          `json:"constants"`
+	Formulas    []Formula           `json:"formulas"`
+	Attachments []ArchiveAttachment `json:"attachments"`
+}
+
+type ImportConflict struct {
+	EntityType string    `json:"entity_type"`
+	SourceID   uuid.UUID `json:"source_id"`
+	Reason     string    `json:"reason"`
+}
+
+type ImportReport struct {
+	Project          Project           `json:"project"`
+	Counts           map[string]int    `json:"counts"`
+	Conflicts        []ImportConflict  `json:"conflicts"`
+	SuggestedMembers []SuggestedMember `json:"suggested_members"`
+}
+
+// SuggestedMember is a local user who was a member of the exported project,
+// with the role they had there. The importer may add them.
+type SuggestedMember struct {
+	UserID uuid.UUID `json:"user_id"`
+	Email  string    `json:"email"`
+	Role   string    `json:"role"`
+}
+
+func (r *ImportReport) AddConflict(entityType string, sourceID uuid.UUID, reason string) {
+	r.Conflicts = append(r.Conflicts, ImportConflict{
+		EntityType: entityType,
+		SourceID:   sourceID,
+		Reason:     reason,
+	})
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskUser is a user linked to a task as an assignee or a watcher.
+type TaskUser struct {
+	TaskID    uuid.UUID `json:"task_id" db:"task_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	UserName  string    `json:"user_name" db:"user_name"`
+	UserEmail string    `json:"user_email" db:"user_email"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+type TaskUserLink struct {
+	TaskID uuid.UUID `db:"task_id"`
+	UserID uuid.UUID `db:"user_id"`
+}
+
+type AddTaskAssigneeRequest struct {
+	UserID uuid.UUID `json:"user_id" binding:"required"`
+}
+
+// AddTaskWatcherRequest watches a task for another member; without UserID the
+// current user starts watching.
+type AddTaskWatcherRequest struct {
+	UserID *uuid.UUID `json:"user_id"`
+}
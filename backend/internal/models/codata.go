@@ -0,0 +1,73 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+const DatasetSourceCODATA = "CODATA"
+
+// ConstantDataset records an import of reference values into the global
+// constants library.
+type ConstantDataset struct {
+	ID         uuid.UUID  `json:"id" db:"id"`
+	Source     string     `json:"source" db:"source"`
+	Version    string     `json:"version" db:"version"`
+	Added      int        `json:"added" db:"added"`
+	Updated    int        `json:"updated" db:"updated"`
+	Unchanged  int        `json:"unchanged" db:"unchanged"`
+	ImportedBy *uuid.UUID `json:"imported_by,omitempty" db:"imported_by"`
+	ImportedAt time.Time  `json:"imported_at" db:"imported_at"`
+}
+
+// CODATAEntry is one parsed row of a CODATA table.
+type CODATAEntry struct {
+	Name        string
+	Value       string
+	Number      float64
+	Uncertainty float64
+	Exact       bool
+	Unit        string
+	ParsedUnit  units.Unit
+}
+
+type CODATATable struct {
+	Version string
+	Entries []CODATAEntry
+	Skipped []CODATASkipped
+}
+
+// CODATAChange describes a constant the import adds or updates. The old
+// fields are nil for added constants.
+type CODATAChange struct {
+	ConstantID     uuid.UUID `json:"constant_id"`
+	Name           string    `json:"name"`
+	Symbol         string    `json:"symbol"`
+	Unit           string    `json:"unit"`
+	OldValue       *float64  `json:"old_value,omitempty"`
+	NewValue       float64   `json:"new_value"`
+	OldUncertainty *float64  `json:"old_uncertainty,omitempty"`
+	NewUncertainty *float64  `json:"new_uncertainty,omitempty"`
+	OldExact       *bool     `json:"old_exact,omitempty"`
+	NewExact       bool      `json:"new_exact"`
+}
+
+type CODATASkipped struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
+}
+
+// CODATAReport is the diff between a CODATA table and the global constants.
+// It is returned before applying, and again with Applied set afterwards.
+type CODATAReport struct {
+	Version         string          `json:"version"`
+	PreviousVersion string          `json:"previous_version,omitempty"`
+	Added           []CODATAChange  `json:"added"`
+	Updated         []CODATAChange  `json:"updated"`
+	Unchanged       int             `json:"unchanged"`
+	Skipped         []CODATASkipped `json:"skipped"`
+	Applied         bool            `json:"applied"`
+	DatasetID       *uuid.UUID      `json:"dataset_id,omitempty"`
+}
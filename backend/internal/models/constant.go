@@ -0,0 +1,20 @@
+package models
+
+const (
+	ConstantScopeGlobal  = "global"
+	ConstantScopeProject = "project"
+	ConstantScopeUser    = "user"
+)
+
+// ConstantScopePrecedence ranks constant scopes for resolving constants that
+// share a symbol: the more specific scope wins.
+var ConstantScopePrecedence = map[string]int{
+	ConstantScopeGlobal:  1,
+	ConstantScopeProject: 2,
+	ConstantScopeUser:    3,
+}
+
+func ValidConstantScope(scope string) bool {
+	_, ok := ConstantScopePrecedence[scope]
+	return ok
+}
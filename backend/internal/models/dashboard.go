@@ -0,0 +1,25 @@
+package models
+
+const DefaultTimezone = "UTC"
+
+// MyTask is a task listed on a user's cross-project dashboard.
+type MyTask struct {
+	Task
+	ProjectName string `json:"project_name"`
+}
+
+// MyTasks groups the open tasks a user is assigned to or watches by when they
+// are due, counted in days of the user's timezone. Tasks due after the end of
+// the current week are not listed.
+type MyTasks struct {
+	Timezone string   `json:"timezone"`
+	Overdue  []MyTask `json:"overdue"`
+	DueToday []MyTask `json:"due_today"`
+	ThisWeek []MyTask `json:"this_week"`
+	NoDate   []MyTask `json:"no_date"`
+}
+
+type UpdateMeRequest struct {
+	Name     string `json:"name" binding:"max=255"`
+	Timezone string `json:"timezone" binding:"max=64"`
+}
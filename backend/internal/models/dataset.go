@@ -0,0 +1,169 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ColumnNumber = "number"
+	ColumnText   = "text"
+)
+
+// Dataset is a table of measurements in a project. Columns, TaskIDs and
+// FormulaIDs are filled in when a single dataset is loaded.
+type Dataset struct {
+	ID          uuid.UUID       `json:"id" db:"id"`
+	ProjectID   uuid.UUID       `json:"project_id" db:"project_id"`
+	Name        string          `json:"name" db:"name"`
+	Description string          `json:"description" db:"description"`
+	CreatedBy   *uuid.UUID      `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt   time.Time       `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time       `json:"updated_at" db:"updated_at"`
+	RowCount    int             `json:"row_count" db:"row_count"`
+	Columns     []DatasetColumn `json:"columns,omitempty" db:"-"`
+	TaskIDs     []uuid.UUID     `json:"task_ids,omitempty" db:"-"`
+	FormulaIDs  []uuid.UUID     `json:"formula_ids,omitempty" db:"-"`
+}
+
+// DatasetColumn describes one column of a dataset. Numbers are stored in
+// the column's unit; Uncertainty is the standard uncertainty of cells that
+// do not give their own, such as an instrument's resolution. Derived
+// columns name the formula they were evaluated with.
+type DatasetColumn struct {
+	ID          uuid.UUID  `json:"id" db:"id"`
+	DatasetID   uuid.UUID  `json:"dataset_id" db:"dataset_id"`
+	Name        string     `json:"name" db:"name"`
+	Kind        string     `json:"kind" db:"kind"`
+	Unit        string     `json:"unit" db:"unit"`
+	Uncertainty *float64   `json:"uncertainty,omitempty" db:"uncertainty"`
+	FormulaID   *uuid.UUID `json:"formula_id,omitempty" db:"formula_id"`
+	Position    int        `json:"position" db:"position"`
+}
+
+// DatasetCell is a value in a row: a number with an optional standard
+// uncertainty, or text.
+type DatasetCell struct {
+	Value       *float64 `json:"value,omitempty"`
+	Uncertainty *float64 `json:"uncertainty,omitempty"`
+	Text        string   `json:"text,omitempty"`
+}
+
+// DatasetCells holds a row's values by column id. Columns without a value
+// are left out.
+type DatasetCells map[uuid.UUID]DatasetCell
+
+func (c DatasetCells) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("{}"), nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *DatasetCells) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+type DatasetRow struct {
+	ID        uuid.UUID    `json:"id" db:"id"`
+	DatasetID uuid.UUID    `json:"dataset_id" db:"dataset_id"`
+	Position  int          `json:"position" db:"position"`
+	Cells     DatasetCells `json:"cells" db:"cells"`
+	CreatedAt time.Time    `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time    `json:"updated_at" db:"updated_at"`
+}
+
+type CreateDatasetRequest struct {
+	Name        string                 `json:"name" binding:"required,max=255"`
+	Description string                 `json:"description"`
+	Columns     []DatasetColumnRequest `json:"columns" binding:"omitempty,dive"`
+}
+
+type UpdateDatasetRequest struct {
+	Name        string  `json:"name" binding:"max=255"`
+	Description *string `json:"description"`
+}
+
+type DatasetColumnRequest struct {
+	Name        string   `json:"name" binding:"required,max=100"`
+	Kind        string   `json:"kind" binding:"omitempty,oneof=number text"`
+	Unit        string   `json:"unit" binding:"max=100"`
+	Uncertainty *float64 `json:"uncertainty" binding:"omitempty,gte=0"`
+}
+
+// UpdateDatasetColumnRequest changes the fields that are set. A new unit
+// must measure the same quantity; the column's numbers are converted to it.
+// An Uncertainty of zero removes the column's default uncertainty.
+type UpdateDatasetColumnRequest struct {
+	Name        string   `json:"name" binding:"max=100"`
+	Unit        *string  `json:"unit" binding:"omitempty,max=100"`
+	Uncertainty *float64 `json:"uncertainty" binding:"omitempty,gte=0"`
+	Position    *int     `json:"position" binding:"omitempty,min=0"`
+}
+
+// DatasetRowRequest gives a row's cells keyed by column name or id. On
+// update only the given cells change; a cell without value or text clears
+// it.
+type DatasetRowRequest struct {
+	Cells    map[string]DatasetCell `json:"cells"`
+	Position *int                   `json:"position" binding:"omitempty,min=0"`
+}
+
+type LinkDatasetTaskRequest struct {
+	TaskID uuid.UUID `json:"task_id" binding:"required"`
+}
+
+type LinkDatasetFormulaRequest struct {
+	FormulaID uuid.UUID `json:"formula_id" binding:"required"`
+}
+
+// ColumnStats summarizes a numeric column. StdDev is the sample standard
+// deviation and StdError that of the mean. The weighted mean uses weights
+// 1/σ² and is only given when every value has an uncertainty.
+type ColumnStats struct {
+	ColumnID            uuid.UUID `json:"column_id"`
+	Name                string    `json:"name"`
+	Unit                string    `json:"unit"`
+	Count               int       `json:"count"`
+	Mean                *float64  `json:"mean,omitempty"`
+	StdDev              *float64  `json:"std_dev,omitempty"`
+	StdError            *float64  `json:"std_error,omitempty"`
+	Min                 *float64  `json:"min,omitempty"`
+	Max                 *float64  `json:"max,omitempty"`
+	WeightedMean        *float64  `json:"weighted_mean,omitempty"`
+	WeightedUncertainty *float64  `json:"weighted_uncertainty,omitempty"`
+}
+
+// EvaluateDatasetRequest evaluates a formula for every row into the derived
+// column Column, which is created or overwritten. Variables maps formula
+// symbols to column names; a symbol without a mapping is read from the
+// column with that name, or else from the constants of the project. Cell
+// uncertainties are propagated linearly.
+type EvaluateDatasetRequest struct {
+	FormulaID uuid.UUID         `json:"formula_id" binding:"required"`
+	Column    string            `json:"column" binding:"required,max=100"`
+	Unit      string            `json:"unit"`
+	Variables map[string]string `json:"variables"`
+}
+
+type DatasetRowError struct {
+	RowID    uuid.UUID `json:"row_id"`
+	Position int       `json:"position"`
+	Error    string    `json:"error"`
+}
+
+type EvaluateDatasetResponse struct {
+	Column    DatasetColumn     `json:"column"`
+	Evaluated int               `json:"evaluated"`
+	Errors    []DatasetRowError `json:"errors"`
+}
+
+// DatasetImport is the result of importing a CSV or TSV file. The file is
+// kept as an attachment of the dataset.
+type DatasetImport struct {
+	Dataset    Dataset     `json:"dataset"`
+	Attachment *Attachment `json:"attachment,omitempty"`
+}
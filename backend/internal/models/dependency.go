@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskDependency means BlockerID has to be finished before BlockedID.
+type TaskDependency struct {
+	BlockerID uuid.UUID  `json:"blocker_id" db:"blocker_id"`
+	BlockedID uuid.UUID  `json:"blocked_id" db:"blocked_id"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+// AddDependencyRequest links the task from the URL with another one; exactly
+// one of the fields must be set.
+type AddDependencyRequest struct {
+	BlockedBy *uuid.UUID `json:"blocked_by"`
+	Blocks    *uuid.UUID `json:"blocks"`
+}
+
+type TaskDependencies struct {
+	BlockedBy []Task `json:"blocked_by"`
+	Blocks    []Task `json:"blocks"`
+}
+
+type TaskGraphNode struct {
+	ID      uuid.UUID  `json:"id"`
+	Title   string     `json:"title"`
+	Status  string     `json:"status"`
+	DueDate *time.Time `json:"due_date,omitempty"`
+	Done    bool       `json:"done"`
+	Blocked bool       `json:"blocked"`
+	// EarliestFinish is the latest due date among the task and everything it
+	// transitively waits for; Late is set when that is after the task's own
+	// due date.
+	EarliestFinish *time.Time `json:"earliest_finish,omitempty"`
+	Late           bool       `json:"late"`
+}
+
+type TaskGraph struct {
+	Nodes        []TaskGraphNode  `json:"nodes"`
+	Edges        []TaskDependency `json:"edges"`
+	CriticalPath []uuid.UUID      `json:"critical_path"`
+	FinishDate   *time.Time       `json:"finish_date,omitempty"`
+}
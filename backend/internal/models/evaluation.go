@@ -0,0 +1,151 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+const (
+	SubstitutionVariable = "variable"
+	SubstitutionConstant = "constant"
+)
+
+const (
+	IssueError   = "error"
+	IssueWarning = "warning"
+)
+
+// VariableValue is a value given for a formula variable, with its standard
+// uncertainty if it was measured.
+type VariableValue struct {
+	Value       *float64 `json:"value" binding:"required"`
+	Uncertainty *float64 `json:"uncertainty" binding:"omitempty,gte=0"`
+	Unit        string   `json:"unit"`
+}
+
+// EvaluateFormulaRequest gives values for a formula's variables, keyed by
+// symbol as written in the formula ("v_0", "\alpha" or "α"). Variables
+// without a value are looked up among the constants visible in ProjectID,
+// which defaults to the formula's project. Unit sets the unit of the result.
+type EvaluateFormulaRequest struct {
+	Variables map[string]VariableValue `json:"variables" binding:"dive"`
+	ProjectID *uuid.UUID               `json:"project_id"`
+	Unit      string                   `json:"unit"`
+}
+
+// Substitution records where the value of one variable came from.
+type Substitution struct {
+	Symbol      string          `json:"symbol"`
+	Source      string          `json:"source"`
+	ConstantID  *uuid.UUID      `json:"constant_id,omitempty"`
+	Name        string          `json:"name,omitempty"`
+	Scope       string          `json:"scope,omitempty"`
+	Value       float64         `json:"value"`
+	Uncertainty *float64        `json:"uncertainty,omitempty"`
+	Unit        string          `json:"unit"`
+	SIValue     float64         `json:"si_value"`
+	Dimension   units.Dimension `json:"dimension"`
+}
+
+type EvaluationResult struct {
+	Symbol    string          `json:"symbol,omitempty"`
+	Value     float64         `json:"value"`
+	Unit      string          `json:"unit"`
+	SIValue   float64         `json:"si_value"`
+	Dimension units.Dimension `json:"dimension"`
+}
+
+// EvaluateFormulaResponse is the result of a formula with the substitutions
+// that produced it. Unused lists given variables the formula does not use.
+type EvaluateFormulaResponse struct {
+	FormulaID     uuid.UUID        `json:"formula_id"`
+	Expression    string           `json:"expression"`
+	Result        EvaluationResult `json:"result"`
+	Substitutions []Substitution   `json:"substitutions"`
+	Unused        []string         `json:"unused,omitempty"`
+}
+
+// FormulaIssue is a problem found when checking a formula. Term is the part
+// of the formula it concerns.
+type FormulaIssue struct {
+	Severity string `json:"severity"`
+	Term     string `json:"term,omitempty"`
+	Message  string `json:"message"`
+}
+
+// FormulaCheck is the result of checking a formula's dimensions. It is
+// Consistent when every variable has a known dimension and no errors were
+// found; Dimension is the formula's dimension where it is known.
+type FormulaCheck struct {
+	Consistent bool             `json:"consistent"`
+	Dimension  *units.Dimension `json:"dimension,omitempty"`
+	Issues     []FormulaIssue   `json:"issues"`
+}
+
+// HasErrors reports whether the check found errors rather than warnings.
+func (c *FormulaCheck) HasErrors() bool {
+	for _, issue := range c.Issues {
+		if issue.Severity == IssueError {
+			return true
+		}
+	}
+	return false
+}
+
+const (
+	PropagationLinear     = "linear"
+	PropagationMonteCarlo = "monte_carlo"
+)
+
+// PropagateUncertaintyRequest evaluates a formula like EvaluateFormulaRequest
+// and propagates the uncertainties of the values and constants. Method is
+// "linear" (the default) or "monte_carlo", which draws Samples normally
+// distributed inputs; Seed makes a Monte Carlo run repeatable.
+type PropagateUncertaintyRequest struct {
+	EvaluateFormulaRequest
+	Method  string `json:"method" binding:"omitempty,oneof=linear monte_carlo"`
+	Samples int    `json:"samples" binding:"omitempty,min=100,max=100000"`
+	Seed    *int64 `json:"seed"`
+}
+
+// UncertaintyContribution is one variable's part in the uncertainty of the
+// result. Uncertainty is the variable's own, in its unit. Sensitivity is
+// the partial derivative of the result by the variable in SI units, given
+// by linear propagation with the symbolic Derivative. Contribution is the
+// uncertainty the variable alone causes, in the result's unit, and Share
+// its fraction of the variance.
+type UncertaintyContribution struct {
+	Symbol       string   `json:"symbol"`
+	Uncertainty  float64  `json:"uncertainty"`
+	Unit         string   `json:"unit"`
+	Derivative   string   `json:"derivative,omitempty"`
+	Sensitivity  *float64 `json:"sensitivity,omitempty"`
+	Contribution float64  `json:"contribution"`
+	Share        float64  `json:"share"`
+}
+
+// UncertaintyResult is a result with its standard uncertainty. Monte Carlo
+// runs also describe the distribution of the result: its Mean, Median and
+// the Interval holding the central 95% of samples.
+type UncertaintyResult struct {
+	EvaluationResult
+	Uncertainty         float64     `json:"uncertainty"`
+	SIUncertainty       float64     `json:"si_uncertainty"`
+	RelativeUncertainty *float64    `json:"relative_uncertainty,omitempty"`
+	Mean                *float64    `json:"mean,omitempty"`
+	Median              *float64    `json:"median,omitempty"`
+	Interval            *[2]float64 `json:"interval_95,omitempty"`
+}
+
+type PropagateUncertaintyResponse struct {
+	FormulaID     uuid.UUID                 `json:"formula_id"`
+	Expression    string                    `json:"expression"`
+	Method        string                    `json:"method"`
+	Samples       int                       `json:"samples,omitempty"`
+	FailedSamples int                       `json:"failed_samples,omitempty"`
+	Seed          *int64                    `json:"seed,omitempty"`
+	Result        UncertaintyResult         `json:"result"`
+	Contributions []UncertaintyContribution `json:"contributions"`
+	Substitutions []Substitution            `json:"substitutions"`
+	Unused        []string                  `json:"unused,omitempty"`
+}
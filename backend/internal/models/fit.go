@@ -0,0 +1,93 @@
+package models
+
+import (
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+const (
+	FitLinear      = "linear"
+	FitPolynomial  = "polynomial"
+	FitExponential = "exponential"
+	FitFormula     = "formula"
+)
+
+// FitDatasetRequest fits a model y(x) to two numeric columns of a dataset,
+// weighting each point by the uncertainty of its y value.
+//
+// The built-in models are y = a + b x, the polynomial y = c_0 + c_1 x + ...
+// of the given Degree, and y = A exp(k x). A formula model is the LaTeX of
+// a formula, given directly or by FormulaID, solved for y; XSymbol names
+// its argument, by default the symbol of the x column. Its free parameters
+// are the ones listed in Parameters, or else every variable that is
+// neither the argument nor a constant.
+type FitDatasetRequest struct {
+	X          string                `json:"x" binding:"required"`
+	Y          string                `json:"y" binding:"required"`
+	Model      string                `json:"model" binding:"required,oneof=linear polynomial exponential formula"`
+	Degree     int                   `json:"degree" binding:"omitempty,min=1,max=6"`
+	FormulaID  *uuid.UUID            `json:"formula_id"`
+	Latex      string                `json:"latex"`
+	XSymbol    string                `json:"x_symbol"`
+	Parameters []FitParameterRequest `json:"parameters" binding:"omitempty,dive"`
+	Save       *SaveFitRequest       `json:"save"`
+}
+
+// FitParameterRequest sets the unit a parameter is reported in and the
+// value, in that unit, the fit starts from. Parameters of a formula model
+// without a unit take the formula's declared one or are dimensionless.
+type FitParameterRequest struct {
+	Symbol  string   `json:"symbol" binding:"required"`
+	Unit    string   `json:"unit"`
+	Initial *float64 `json:"initial"`
+}
+
+// SaveFitRequest saves the fit as a project formula whose parameters are
+// linked to new project constants holding the fitted values.
+type SaveFitRequest struct {
+	Title       string `json:"title" binding:"required,max=255"`
+	Description string `json:"description"`
+}
+
+type FitParameter struct {
+	Symbol      string          `json:"symbol"`
+	Value       float64         `json:"value"`
+	Uncertainty float64         `json:"uncertainty"`
+	Unit        string          `json:"unit"`
+	Dimension   units.Dimension `json:"dimension"`
+}
+
+// FitResidual compares a point with the fitted curve, in the units of the
+// columns. Normalized is the residual in units of the point's uncertainty.
+type FitResidual struct {
+	RowID      uuid.UUID `json:"row_id"`
+	Position   int       `json:"position"`
+	X          float64   `json:"x"`
+	Y          float64   `json:"y"`
+	Fitted     float64   `json:"fitted"`
+	Residual   float64   `json:"residual"`
+	Normalized *float64  `json:"normalized,omitempty"`
+}
+
+// FitDatasetResponse is the result of a fit. When not every point has an
+// uncertainty the fit is unweighted and the parameter uncertainties are
+// scaled by the scatter of the points, so ReducedChiSquare is the residual
+// variance in the y column's unit squared. Covariance is in the parameters'
+// units.
+type FitDatasetResponse struct {
+	Model            string            `json:"model"`
+	Expression       string            `json:"expression"`
+	Symbol           string            `json:"symbol"`
+	Argument         string            `json:"argument"`
+	Parameters       []FitParameter    `json:"parameters"`
+	Covariance       [][]float64       `json:"covariance"`
+	ChiSquare        float64           `json:"chi_square"`
+	NDF              int               `json:"ndf"`
+	ReducedChiSquare float64           `json:"reduced_chi_square"`
+	Weighted         bool              `json:"weighted"`
+	Iterations       int               `json:"iterations"`
+	Residuals        []FitResidual     `json:"residuals"`
+	Skipped          []DatasetRowError `json:"skipped"`
+	Formula          *Formula          `json:"formula,omitempty"`
+	Constants        []Constant        `json:"constants,omitempty"`
+}
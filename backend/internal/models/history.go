@@ -0,0 +1,136 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// TaskSnapshot holds the user-editable fields of a task that are tracked by
+// the revision history.
+type TaskSnapshot struct {
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      string     `json:"status"`
+	Priority    string     `json:"priority"`
+	DueDate     *time.Time `json:"due_date"`
+	AssignedTo  *uuid.UUID `json:"assigned_to"`
+}
+
+func SnapshotOf(t *Task) TaskSnapshot {
+	return TaskSnapshot{
+		Title:       t.Title,
+		Description: t.Description,
+		Status:      t.Status,
+		Priority:    t.Priority,
+		DueDate:     t.DueDate,
+		AssignedTo:  t.AssignedTo,
+	}
+}
+
+// ApplyTo copies the snapshot fields onto a task.
+func (s TaskSnapshot) ApplyTo(t *Task) {
+	t.Title = s.Title
+	t.Description = s.Description
+	t.Status = s.Status
+	t.Priority = s.Priority
+	t.DueDate = s.DueDate
+	t.AssignedTo = s.AssignedTo
+}
+
+func (s TaskSnapshot) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *TaskSnapshot) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+type FieldChange struct {
+	Field string          `json:"field"`
+	Old   json.RawMessage `json:"old"`
+	New   json.RawMessage `json:"new"`
+}
+
+type FieldChanges []FieldChange
+
+func (c FieldChanges) Value() (driver.Value, error) {
+	if c == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(c)
+}
+
+func (c *FieldChanges) Scan(src interface{}) error {
+	return scanJSON(src, c)
+}
+
+// DiffSnapshots lists the fields that differ between two snapshots, in a
+// stable order.
+func DiffSnapshots(before, after TaskSnapshot) FieldChanges {
+	changes := FieldChanges{}
+	add := func(field string, old, new interface{}) {
+		oldJSON, _ := json.Marshal(old)
+		newJSON, _ := json.Marshal(new)
+		if string(oldJSON) != string(newJSON) {
+			changes = append(changes, FieldChange{Field: field, Old: oldJSON, New: newJSON})
+		}
+	}
+
+	add("title", before.Title, after.Title)
+	add("description", before.Description, after.Description)
+	add("status", before.Status, after.Status)
+	add("priority", before.Priority, after.Priority)
+	add("due_date", before.DueDate, after.DueDate)
+	add("assigned_to", before.AssignedTo, after.AssignedTo)
+
+	return changes
+}
+
+type TaskRevision struct {
+	ID           uuid.UUID    `json:"id" db:"id"`
+	TaskID       uuid.UUID    `json:"task_id" db:"task_id"`
+	Revision     int          `json:"revision" db:"revision"`
+	ChangedBy    *uuid.UUID   `json:"changed_by,omitempty" db:"changed_by"`
+	Changes      FieldChanges `json:"changes" db:"changes"`
+	Snapshot     TaskSnapshot `json:"snapshot" db:"snapshot"`
+	RevertedFrom *int         `json:"reverted_from,omitempty" db:"reverted_from"`
+	CreatedAt    time.Time    `json:"created_at" db:"created_at"`
+}
+
+const (
+	TimelineRevision   = "revision"
+	TimelineComment    = "comment"
+	TimelineTagAdded   = "tag_added"
+	TimelineTagRemoved = "tag_removed"
+)
+
+type TimelineEntry struct {
+	Type      string               `json:"type"`
+	At        time.Time            `json:"at"`
+	ActorID   *uuid.UUID           `json:"actor_id,omitempty"`
+	ActorName string               `json:"actor_name,omitempty"`
+	Revision  *TaskRevision        `json:"revision,omitempty"`
+	Comment   *TaskCommentWithUser `json:"comment,omitempty"`
+	Tag       json.RawMessage      `json:"tag,omitempty"`
+}
+
+type RevertTaskRequest struct {
+	Revision int `json:"revision" binding:"required,min=1"`
+}
+
+func scanJSON(src interface{}, dest interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return json.Unmarshal(v, dest)
+	case string:
+		return json.Unmarshal([]byte(v), dest)
+	case nil:
+		return nil
+	default:
+		return fmt.Errorf("unsupported type %T for JSON column", src)
+	}
+}
@@ -0,0 +1,14 @@
+package models
+
+import "github.com/google/uuid"
+
+// CommentMention is an @mention of a project member inside a comment. Start
+// and Length count UTF-16 code units of the content, as JavaScript does, and
+// cover the "@".
+type CommentMention struct {
+	CommentID uuid.UUID `json:"-" db:"comment_id"`
+	UserID    uuid.UUID `json:"user_id" db:"user_id"`
+	UserName  string    `json:"user_name" db:"user_name"`
+	Start     int       `json:"start" db:"start"`
+	Length    int       `json:"length" db:"length"`
+}
@@ -4,13 +4,17 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
 )
 
 type User struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	Email     string    `json:"email" db:"email"`
-	Password  string    `json:"-" db:"password"`
-	Name      string    `json:"name" db:"name"`
+	ID       uuid.UUID `json:"id" db:"id"`
+	Email    string    `json:"email" db:"email"`
+	Password string    `json:"-" db:"password"`
+	Name     string    `json:"name" db:"name"`
+	// Timezone is an IANA zone name used to tell which day it is for the user.
+	Timezone  string    `json:"timezone" db:"timezone"`
+	SiteRole  string    `json:"site_role" db:"site_role"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
 }
@@ -33,32 +37,55 @@ type ProjectMember struct {
 }
 
 type Task struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	ProjectID   uuid.UUID  `json:"project_id" db:"project_id"`
-	Title       string     `json:"title" db:"title"`
-	Description string     `json:"description" db:"description"`
-	Status      string     `json:"status" db:"status"`
-	Priority    string     `json:"priority" db:"priority"`
-	DueDate     *time.Time `json:"due_date,omitempty" db:"due_date"`
-	AssignedTo  *uuid.UUID `json:"assigned_to,omitempty" db:"assigned_to"`
-	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
-	Tags        []Tag      `json:"tags,omitempty" db:"-"`
+	ID              uuid.UUID     `json:"id" db:"id"`
+	ProjectID       uuid.UUID     `json:"project_id" db:"project_id"`
+	Title           string        `json:"title" db:"title"`
+	Description     string        `json:"description" db:"description"`
+	DescriptionHTML string        `json:"description_html" db:"description_html"`
+	Status          string        `json:"status" db:"status"`
+	Priority        string        `json:"priority" db:"priority"`
+	DueDate         *time.Time    `json:"due_date,omitempty" db:"due_date"`
+	AssignedTo      *uuid.UUID    `json:"assigned_to,omitempty" db:"assigned_to"`
+	Rank            string        `json:"rank" db:"rank"`
+	ParentID        *uuid.UUID    `json:"parent_id,omitempty" db:"parent_id"`
+	CreatedBy       uuid.UUID     `json:"created_by" db:"created_by"`
+	CreatedAt       time.Time     `json:"created_at" db:"created_at"`
+	UpdatedAt       time.Time     `json:"updated_at" db:"updated_at"`
+	Tags            []Tag         `json:"tags,omitempty" db:"-"`
+	Progress        *TaskProgress `json:"progress,omitempty" db:"-"`
+	Blocked         bool          `json:"blocked" db:"-"`
+	// Assignees lists everyone working on the task; AssignedTo is the primary
+	// assignee and is always one of them.
+	Assignees []uuid.UUID `json:"assignees,omitempty" db:"-"`
 }
 
+// Constant keeps Value and Unit as entered, next to the parsed number and
+// unit. The parsed fields are nil for older constants that migration 000026
+// could not parse.
 type Constant struct {
-	ID          uuid.UUID  `json:"id" db:"id"`
-	Name        string     `json:"name" db:"name"`
-	Symbol      string     `json:"symbol" db:"symbol"`
-	Value       string     `json:"value" db:"value"`
-	Unit        string     `json:"unit" db:"unit"`
-	Description string     `json:"description" db:"description"`
-	Scope       string     `json:"scope" db:"scope"`
-	ScopeID     *uuid.UUID `json:"scope_id,omitempty" db:"scope_id"`
-	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
-	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
-	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	ID           uuid.UUID        `json:"id" db:"id"`
+	Name         string           `json:"name" db:"name"`
+	Symbol       string           `json:"symbol" db:"symbol"`
+	Value        string           `json:"value" db:"value"`
+	Unit         string           `json:"unit" db:"unit"`
+	NumericValue *float64         `json:"numeric_value" db:"numeric_value"`
+	Uncertainty  *float64         `json:"uncertainty,omitempty" db:"uncertainty"`
+	Exact        bool             `json:"exact" db:"is_exact"`
+	UnitScale    *float64         `json:"unit_scale" db:"unit_scale"`
+	Dimensions   *units.Dimension `json:"dimensions" db:"dimensions"`
+	Description  string           `json:"description" db:"description"`
+	Scope        string           `json:"scope" db:"scope"`
+	ScopeID      *uuid.UUID       `json:"scope_id,omitempty" db:"scope_id"`
+	DatasetID    *uuid.UUID       `json:"dataset_id,omitempty" db:"dataset_id"`
+	CreatedBy    uuid.UUID        `json:"created_by" db:"created_by"`
+	CreatedAt    time.Time        `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time        `json:"updated_at" db:"updated_at"`
+	Review
+	// Overrides lists the constants with the same symbol from less specific
+	// scopes that this one hides in a merged listing.
+	Overrides []uuid.UUID `json:"overrides,omitempty" db:"-"`
+	// Converted is the value in the unit a client asked for with ?unit=.
+	Converted *ConvertedValue `json:"converted,omitempty" db:"-"`
 }
 
 type Formula struct {
@@ -67,9 +94,24 @@ type Formula struct {
 	Latex       string     `json:"latex" db:"latex"`
 	Description string     `json:"description" db:"description"`
 	ProjectID   *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+	Global      bool       `json:"global" db:"is_global"`
 	CreatedBy   uuid.UUID  `json:"created_by" db:"created_by"`
 	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	Review
+	Variables []FormulaVariable `json:"variables,omitempty" db:"-"`
+	// Check is the dimensional check done when the formula was saved.
+	Check *FormulaCheck `json:"check,omitempty" db:"-"`
+}
+
+// FormulaVariable declares the unit of a formula variable or links it to a
+// constant. Symbol is normalized as in parsed formulas, e.g. "E_k" or "α".
+type FormulaVariable struct {
+	FormulaID   uuid.UUID  `json:"-" db:"formula_id"`
+	Symbol      string     `json:"symbol" db:"symbol"`
+	Unit        string     `json:"unit" db:"unit"`
+	ConstantID  *uuid.UUID `json:"constant_id,omitempty" db:"constant_id"`
+	Description string     `json:"description" db:"description"`
 }
 
 type Attachment struct {
@@ -112,27 +154,44 @@ type UpdateProjectRequest struct {
 }
 
 type CreateTaskRequest struct {
-	Title       string     `json:"title" binding:"required"`
-	Description string     `json:"description"`
-	Status      string     `json:"status"`
-	Priority    string     `json:"priority"`
-	DueDate     *string    `json:"due_date"`
-	AssignedTo  *uuid.UUID `json:"assigned_to"`
+	Title       string      `json:"title" binding:"required"`
+	Description string      `json:"description" binding:"max=20000"`
+	Status      string      `json:"status"`
+	Priority    string      `json:"priority"`
+	DueDate     *string     `json:"due_date"`
+	AssignedTo  *uuid.UUID  `json:"assigned_to"`
+	Assignees   []uuid.UUID `json:"assignees"`
+	ParentID    *uuid.UUID  `json:"parent_id"`
 }
 
 type UpdateTaskRequest struct {
 	Title       string     `json:"title"`
-	Description string     `json:"description"`
+	Description string     `json:"description" binding:"max=20000"`
 	Status      string     `json:"status"`
 	Priority    string     `json:"priority"`
 	DueDate     *string    `json:"due_date"`
 	AssignedTo  *uuid.UUID `json:"assigned_to"`
+	// ParentID takes a task id, or "" / "null" to detach the task.
+	ParentID *string `json:"parent_id"`
+}
+
+// MoveTaskRequest places a task in a status column. BeforeID is the task that
+// should end up directly above the moved one and AfterID the one directly
+// below; with neither the task goes to the bottom of the column.
+type MoveTaskRequest struct {
+	Status   string     `json:"status" binding:"required"`
+	BeforeID *uuid.UUID `json:"before_id"`
+	AfterID  *uuid.UUID `json:"after_id"`
 }
 
+// CreateConstantRequest carries the value as text so that it can use the
+// concise notation "6.67430(15)e-11"; Uncertainty is an alternative to it.
 type CreateConstantRequest struct {
 	Name        string     `json:"name" binding:"required"`
 	Symbol      string     `json:"symbol" binding:"required"`
 	Value       string     `json:"value" binding:"required"`
+	Uncertainty *float64   `json:"uncertainty" binding:"omitempty,gt=0"`
+	Exact       bool       `json:"exact"`
 	Unit        string     `json:"unit"`
 	Description string     `json:"description"`
 	Scope       string     `json:"scope" binding:"required"`
@@ -144,30 +203,54 @@ type CreateFormulaRequest struct {
 	Latex       string     `json:"latex" binding:"required"`
 	Description string     `json:"description"`
 	ProjectID   *uuid.UUID `json:"project_id"`
+	Global      bool       `json:"global"`
+	// Variables replaces the declared variables; nil keeps them on update.
+	Variables []FormulaVariableRequest `json:"variables" binding:"omitempty,dive"`
+}
+
+type FormulaVariableRequest struct {
+	Symbol      string     `json:"symbol" binding:"required"`
+	Unit        string     `json:"unit"`
+	ConstantID  *uuid.UUID `json:"constant_id"`
+	Description string     `json:"description"`
 }
 
 type TaskComment struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	TaskID    uuid.UUID `json:"task_id" db:"task_id"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	Content   string    `json:"content" db:"content"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	ParentID    *uuid.UUID `json:"parent_id" db:"parent_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Content     string     `json:"content" db:"content"`
+	ContentHTML string     `json:"content_html" db:"content_html"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	// DeletedAt is set on tombstones: deleted comments kept for their replies.
+	DeletedAt *time.Time       `json:"deleted_at" db:"deleted_at"`
+	Mentions  []CommentMention `json:"mentions" db:"-"`
 }
 
 type TaskCommentWithUser struct {
-	ID        uuid.UUID `json:"id" db:"id"`
-	TaskID    uuid.UUID `json:"task_id" db:"task_id"`
-	UserID    uuid.UUID `json:"user_id" db:"user_id"`
-	Content   string    `json:"content" db:"content"`
-	CreatedAt time.Time `json:"created_at" db:"created_at"`
-	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
-	UserName  string    `json:"user_name" db:"user_name"`
-	UserEmail string    `json:"user_email" db:"user_email"`
+	ID          uuid.UUID  `json:"id" db:"id"`
+	TaskID      uuid.UUID  `json:"task_id" db:"task_id"`
+	ParentID    *uuid.UUID `json:"parent_id" db:"parent_id"`
+	UserID      uuid.UUID  `json:"user_id" db:"user_id"`
+	Content     string     `json:"content" db:"content"`
+	ContentHTML string     `json:"content_html" db:"content_html"`
+	CreatedAt   time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at" db:"updated_at"`
+	DeletedAt   *time.Time `json:"deleted_at" db:"deleted_at"`
+	UserName    string     `json:"user_name" db:"user_name"`
+	UserEmail   string     `json:"user_email" db:"user_email"`
+	// Mentions are the resolved @mention spans of Content.
+	Mentions  []CommentMention  `json:"mentions" db:"-"`
+	Reactions []CommentReaction `json:"reactions" db:"-"`
+	// Replies are only set on top-level comments.
+	Replies []TaskCommentWithUser `json:"replies,omitempty" db:"-"`
 }
 
 type CreateCommentRequest struct {
-	Content string `json:"content" binding:"required,min=1,max=2000"`
+	Content  string     `json:"content" binding:"required,min=1,max=2000"`
+	ParentID *uuid.UUID `json:"parent_id"`
 }
 
 type UpdateCommentRequest struct {
@@ -0,0 +1,86 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	SiteRoleUser    = "user"
+	SiteRoleCurator = "curator"
+	SiteRoleAdmin   = "admin"
+)
+
+func ValidSiteRole(role string) bool {
+	return role == SiteRoleUser || role == SiteRoleCurator || role == SiteRoleAdmin
+}
+
+// CanCurate reports whether the user publishes global constants and formulas
+// directly and reviews the ones others propose.
+func (u *User) CanCurate() bool {
+	return u.SiteRole == SiteRoleCurator || u.SiteRole == SiteRoleAdmin
+}
+
+const (
+	ReviewPending  = "pending"
+	ReviewApproved = "approved"
+	ReviewRejected = "rejected"
+)
+
+// Review is the moderation state of a constant or formula. Only global
+// entries go through review; everything else is approved on creation.
+type Review struct {
+	ReviewStatus string     `json:"review_status" db:"review_status"`
+	ReviewedBy   *uuid.UUID `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewedAt   *time.Time `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	ReviewNote   string     `json:"review_note,omitempty" db:"review_note"`
+}
+
+// Moderation audit actions. Entries created by curators are published
+// without review; the others are submitted and then approved or rejected.
+const (
+	ModerationSubmitted = "submitted"
+	ModerationPublished = "published"
+	ModerationApproved  = "approved"
+	ModerationRejected  = "rejected"
+	ModerationUpdated   = "updated"
+	ModerationDeleted   = "deleted"
+	ModerationRoleSet   = "role_changed"
+)
+
+const (
+	ModerationConstant = "constant"
+	ModerationFormula  = "formula"
+	ModerationUser     = "user"
+)
+
+type ModerationEvent struct {
+	ID         uuid.UUID       `json:"id" db:"id"`
+	EntityType string          `json:"entity_type" db:"entity_type"`
+	EntityID   uuid.UUID       `json:"entity_id" db:"entity_id"`
+	ActorID    *uuid.UUID      `json:"actor_id,omitempty" db:"actor_id"`
+	Action     string          `json:"action" db:"action"`
+	Note       string          `json:"note,omitempty" db:"note"`
+	Data       json.RawMessage `json:"data,omitempty" db:"data"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+}
+
+type ModerationEventWithUser struct {
+	ModerationEvent
+	ActorName *string `json:"actor_name,omitempty" db:"actor_name"`
+}
+
+type ModerationQueue struct {
+	Constants []Constant `json:"constants"`
+	Formulas  []Formula  `json:"formulas"`
+}
+
+type ReviewRequest struct {
+	Note string `json:"note" binding:"max=1000"`
+}
+
+type SetSiteRoleRequest struct {
+	SiteRole string `json:"site_role" binding:"required"`
+}
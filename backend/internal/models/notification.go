@@ -0,0 +1,41 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	NotificationDeadlineSoon = "deadline_soon"
+	NotificationOverdue      = "overdue"
+	NotificationAssigned     = "assigned"
+	NotificationMentioned    = "mentioned"
+	NotificationComment      = "comment"
+	NotificationReviewed     = "reviewed"
+)
+
+type Notification struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	UserID    uuid.UUID  `json:"user_id" db:"user_id"`
+	Kind      string     `json:"kind" db:"kind"`
+	ProjectID *uuid.UUID `json:"project_id,omitempty" db:"project_id"`
+	TaskID    *uuid.UUID `json:"task_id,omitempty" db:"task_id"`
+	ActorID   *uuid.UUID `json:"actor_id,omitempty" db:"actor_id"`
+	Title     string     `json:"title" db:"title"`
+	Body      string     `json:"body" db:"body"`
+	ReadAt    *time.Time `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+}
+
+type NotificationList struct {
+	Notifications []Notification `json:"notifications"`
+	UnreadCount   int            `json:"unread_count"`
+}
+
+// MarkNotificationsReadRequest marks the listed notifications read, or all of
+// the user's notifications with All.
+type MarkNotificationsReadRequest struct {
+	IDs []uuid.UUID `json:"ids"`
+	All bool        `json:"all"`
+}
@@ -0,0 +1,132 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	TagMatchAny = "any"
+	TagMatchAll = "all"
+
+	// QueryUserMe stands for the user running the query, so a saved query
+	// like "my tasks" works for everyone it is shared with.
+	QueryUserMe = "me"
+	// QueryUserNone matches tasks without any assignee.
+	QueryUserNone = "none"
+
+	DefaultTaskSort = "rank"
+	MaxTaskPageSize = 200
+)
+
+// TaskSortKeys lists the accepted sort keys. A leading "-" sorts descending.
+var TaskSortKeys = []string{
+	"rank",
+	"due_date", "-due_date",
+	"priority", "-priority",
+	"created_at", "-created_at",
+	"updated_at", "-updated_at",
+	"title", "-title",
+}
+
+var (
+	ErrInvalidTaskQuery = errors.New("invalid task query")
+	ErrInvalidCursor    = errors.New("invalid cursor")
+)
+
+// TaskQuery filters and sorts the tasks of a project. Empty fields do not
+// filter; values within a field are OR-ed and fields are AND-ed together.
+type TaskQuery struct {
+	Statuses   []string `json:"statuses,omitempty"`
+	Priorities []string `json:"priorities,omitempty"`
+	// Assignees holds user ids, "me" or "none".
+	Assignees []string `json:"assignees,omitempty"`
+	// Creators holds user ids or "me".
+	Creators []string `json:"creators,omitempty"`
+	// DueFrom and DueTo are inclusive dates in YYYY-MM-DD form.
+	DueFrom string `json:"due_from,omitempty"`
+	DueTo   string `json:"due_to,omitempty"`
+	// Overdue matches open tasks whose due date has passed.
+	Overdue bool        `json:"overdue,omitempty"`
+	NoDue   bool        `json:"no_due,omitempty"`
+	Tags    []uuid.UUID `json:"tags,omitempty"`
+	// TagMatch is "any" (default) or "all".
+	TagMatch string `json:"tag_match,omitempty"`
+	// Search is a full-text query over title and description in web search
+	// syntax: quoted phrases, "or" and "-word" are understood.
+	Search string `json:"search,omitempty"`
+	Sort   string `json:"sort,omitempty"`
+}
+
+func (q TaskQuery) Value() (driver.Value, error) {
+	return json.Marshal(q)
+}
+
+func (q *TaskQuery) Scan(src interface{}) error {
+	return scanJSON(src, q)
+}
+
+// SortKey returns the sort key, falling back to the board order.
+func (q *TaskQuery) SortKey() string {
+	if q.Sort == "" {
+		return DefaultTaskSort
+	}
+	return q.Sort
+}
+
+func (q *TaskQuery) Validate() error {
+	for _, a := range q.Assignees {
+		if a == QueryUserMe || a == QueryUserNone {
+			continue
+		}
+		if _, err := uuid.Parse(a); err != nil {
+			return fmt.Errorf("%w: assignee %q must be a user id, \"me\" or \"none\"", ErrInvalidTaskQuery, a)
+		}
+	}
+	for _, cr := range q.Creators {
+		if cr == QueryUserMe {
+			continue
+		}
+		if _, err := uuid.Parse(cr); err != nil {
+			return fmt.Errorf("%w: creator %q must be a user id or \"me\"", ErrInvalidTaskQuery, cr)
+		}
+	}
+
+	var from, to time.Time
+	var err error
+	if q.DueFrom != "" {
+		if from, err = time.Parse("2006-01-02", q.DueFrom); err != nil {
+			return fmt.Errorf("%w: due_from must be a YYYY-MM-DD date", ErrInvalidTaskQuery)
+		}
+	}
+	if q.DueTo != "" {
+		if to, err = time.Parse("2006-01-02", q.DueTo); err != nil {
+			return fmt.Errorf("%w: due_to must be a YYYY-MM-DD date", ErrInvalidTaskQuery)
+		}
+	}
+	if q.DueFrom != "" && q.DueTo != "" && to.Before(from) {
+		return fmt.Errorf("%w: due_to is before due_from", ErrInvalidTaskQuery)
+	}
+	if q.NoDue && (q.Overdue || q.DueFrom != "" || q.DueTo != "") {
+		return fmt.Errorf("%w: no_due cannot be combined with other due date filters", ErrInvalidTaskQuery)
+	}
+
+	if q.TagMatch != "" && q.TagMatch != TagMatchAny && q.TagMatch != TagMatchAll {
+		return fmt.Errorf("%w: tag_match must be \"any\" or \"all\"", ErrInvalidTaskQuery)
+	}
+	if len(q.Search) > 200 {
+		return fmt.Errorf("%w: search is limited to 200 characters", ErrInvalidTaskQuery)
+	}
+
+	for _, key := range TaskSortKeys {
+		if q.SortKey() == key {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: unknown sort %q", ErrInvalidTaskQuery, q.Sort)
+}
@@ -0,0 +1,42 @@
+package models
+
+import (
+	"errors"
+	"unicode"
+	"unicode/utf8"
+)
+
+var ErrInvalidReaction = errors.New("reaction must be a single emoji")
+
+// CommentReaction aggregates the reactions with one emoji on a comment.
+// Reacted tells whether the viewing user is among them.
+type CommentReaction struct {
+	Emoji   string `json:"emoji" db:"emoji"`
+	Count   int    `json:"count" db:"count"`
+	Reacted bool   `json:"reacted" db:"reacted"`
+}
+
+type ToggleReactionRequest struct {
+	Emoji string `json:"emoji" binding:"required,max=32"`
+}
+
+// ValidateReaction accepts short non-ASCII symbol sequences, which covers
+// emoji with skin tone modifiers and ZWJ sequences but not plain words.
+func ValidateReaction(emoji string) error {
+	if emoji == "" || len(emoji) > 32 || !utf8.ValidString(emoji) {
+		return ErrInvalidReaction
+	}
+	symbol := false
+	for _, r := range emoji {
+		switch {
+		case r < utf8.RuneSelf, unicode.IsSpace(r), unicode.IsControl(r), unicode.IsLetter(r):
+			return ErrInvalidReaction
+		case unicode.Is(unicode.So, r):
+			symbol = true
+		}
+	}
+	if !symbol {
+		return ErrInvalidReaction
+	}
+	return nil
+}
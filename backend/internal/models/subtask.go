@@ -0,0 +1,56 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// MaxTaskDepth is the number of levels a task tree may have, counting the
+// top-level task.
+const MaxTaskDepth = 3
+
+// TaskProgress rolls up the direct subtasks and checklist items of a task.
+type TaskProgress struct {
+	SubtasksTotal  int `json:"subtasks_total"`
+	SubtasksDone   int `json:"subtasks_done"`
+	ChecklistTotal int `json:"checklist_total"`
+	ChecklistDone  int `json:"checklist_done"`
+	Percent        int `json:"percent"`
+}
+
+// NewTaskProgress returns nil for tasks without subtasks or checklist items.
+func NewTaskProgress(subtasksTotal, subtasksDone, checklistTotal, checklistDone int) *TaskProgress {
+	total := subtasksTotal + checklistTotal
+	if total == 0 {
+		return nil
+	}
+	return &TaskProgress{
+		SubtasksTotal:  subtasksTotal,
+		SubtasksDone:   subtasksDone,
+		ChecklistTotal: checklistTotal,
+		ChecklistDone:  checklistDone,
+		Percent:        (subtasksDone + checklistDone) * 100 / total,
+	}
+}
+
+type ChecklistItem struct {
+	ID        uuid.UUID  `json:"id" db:"id"`
+	TaskID    uuid.UUID  `json:"task_id" db:"task_id"`
+	Content   string     `json:"content" db:"content"`
+	Done      bool       `json:"done" db:"done"`
+	Position  int        `json:"position" db:"position"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty" db:"created_by"`
+	CreatedAt time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time  `json:"updated_at" db:"updated_at"`
+}
+
+type CreateChecklistItemRequest struct {
+	Content string `json:"content" binding:"required,max=500"`
+}
+
+type UpdateChecklistItemRequest struct {
+	Content  string `json:"content" binding:"max=500"`
+	Done     *bool  `json:"done"`
+	Position *int   `json:"position" binding:"omitempty,min=0"`
+}
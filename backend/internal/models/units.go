@@ -0,0 +1,47 @@
+package models
+
+import "github.com/itmo-pride/student-taskboard/backend/internal/units"
+
+type ConvertUnitsRequest struct {
+	Value       *float64 `json:"value" binding:"required"`
+	Uncertainty *float64 `json:"uncertainty" binding:"omitempty,gt=0"`
+	From        string   `json:"from" binding:"required"`
+	To          string   `json:"to" binding:"required"`
+}
+
+type ConvertUnitsResponse struct {
+	Value       float64         `json:"value"`
+	Uncertainty *float64        `json:"uncertainty,omitempty"`
+	From        string          `json:"from"`
+	To          string          `json:"to"`
+	Dimension   units.Dimension `json:"dimension"`
+}
+
+// CheckUnitRequest validates a unit and, with CompatibleWith, whether values
+// in it can be converted to another unit.
+type CheckUnitRequest struct {
+	Unit           string  `json:"unit"`
+	CompatibleWith *string `json:"compatible_with"`
+}
+
+// CheckUnitResponse describes a unit: a value in it is Scale·value + Offset
+// in the SI unit, written out in base units as SI and as the named unit
+// Named where there is one.
+type CheckUnitResponse struct {
+	Unit       string           `json:"unit"`
+	Valid      bool             `json:"valid"`
+	Error      string           `json:"error,omitempty"`
+	Scale      float64          `json:"scale,omitempty"`
+	Offset     float64          `json:"offset,omitempty"`
+	Dimension  *units.Dimension `json:"dimension,omitempty"`
+	SI         string           `json:"si,omitempty"`
+	Named      string           `json:"named,omitempty"`
+	Compatible *bool            `json:"compatible,omitempty"`
+}
+
+// ConvertedValue is a constant's value shown in another unit.
+type ConvertedValue struct {
+	Unit        string   `json:"unit"`
+	Value       float64  `json:"value"`
+	Uncertainty *float64 `json:"uncertainty,omitempty"`
+}
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	ViewVisibilityPrivate = "private"
+	ViewVisibilityProject = "project"
+)
+
+const (
+	GroupByNone     = ""
+	GroupByStatus   = "status"
+	GroupByAssignee = "assignee"
+	GroupByPriority = "priority"
+	GroupByTag      = "tag"
+)
+
+// GroupKeyNone is the group of tasks without an assignee or tag.
+const GroupKeyNone = "none"
+
+// SavedView is a named task query on a project board. Private views are
+// listed only to their owner; project views to every member.
+type SavedView struct {
+	ID         uuid.UUID `json:"id" db:"id"`
+	ProjectID  uuid.UUID `json:"project_id" db:"project_id"`
+	OwnerID    uuid.UUID `json:"owner_id" db:"owner_id"`
+	Name       string    `json:"name" db:"name"`
+	Query      TaskQuery `json:"query" db:"query"`
+	GroupBy    string    `json:"group_by" db:"group_by"`
+	Visibility string    `json:"visibility" db:"visibility"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time `json:"updated_at" db:"updated_at"`
+}
+
+type CreateViewRequest struct {
+	Name       string    `json:"name" binding:"required,max=100"`
+	Query      TaskQuery `json:"query"`
+	GroupBy    string    `json:"group_by" binding:"omitempty,oneof=status assignee priority tag"`
+	Visibility string    `json:"visibility" binding:"omitempty,oneof=private project"`
+}
+
+// UpdateViewRequest replaces the fields that are set. An empty GroupBy
+// string removes the grouping.
+type UpdateViewRequest struct {
+	Name       string     `json:"name" binding:"max=100"`
+	Query      *TaskQuery `json:"query"`
+	GroupBy    *string    `json:"group_by"`
+	Visibility string     `json:"visibility" binding:"omitempty,oneof=private project"`
+}
+
+func ValidGroupBy(groupBy string) bool {
+	switch groupBy {
+	case GroupByNone, GroupByStatus, GroupByAssignee, GroupByPriority, GroupByTag:
+		return true
+	}
+	return false
+}
+
+type TaskGroup struct {
+	Key   string `json:"key"`
+	Tasks []Task `json:"tasks"`
+}
+
+// ViewResult is a page of tasks matching a view. Groups is set when the view
+// groups its tasks; a task with several assignees or tags is listed in each
+// of their groups.
+type ViewResult struct {
+	View       SavedView   `json:"view"`
+	Tasks      []Task      `json:"tasks"`
+	Groups     []TaskGroup `json:"groups,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"`
+}
+
+// GroupTasks splits tasks by groupBy, keeping their order within a group.
+// Groups named in order come first, in that order; the rest follow in order
+// of first appearance.
+func GroupTasks(tasks []Task, groupBy string, order []string) []TaskGroup {
+	byKey := make(map[string][]Task)
+	var seen []string
+	add := func(key string, t Task) {
+		if _, ok := byKey[key]; !ok {
+			seen = append(seen, key)
+		}
+		byKey[key] = append(byKey[key], t)
+	}
+
+	for _, t := range tasks {
+		switch groupBy {
+		case GroupByStatus:
+			add(t.Status, t)
+		case GroupByPriority:
+			add(t.Priority, t)
+		case GroupByAssignee:
+			if len(t.Assignees) == 0 {
+				add(GroupKeyNone, t)
+			}
+			for _, id := range t.Assignees {
+				add(id.String(), t)
+			}
+		case GroupByTag:
+			if len(t.Tags) == 0 {
+				add(GroupKeyNone, t)
+			}
+			for _, tag := range t.Tags {
+				add(tag.ID.String(), t)
+			}
+		}
+	}
+
+	groups := make([]TaskGroup, 0, len(byKey))
+	for _, key := range order {
+		if ts, ok := byKey[key]; ok {
+			groups = append(groups, TaskGroup{Key: key, Tasks: ts})
+			delete(byKey, key)
+		}
+	}
+	for _, key := range seen {
+		if ts, ok := byKey[key]; ok {
+			groups = append(groups, TaskGroup{Key: key, Tasks: ts})
+		}
+	}
+	return groups
+}
@@ -0,0 +1,215 @@
+package models
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	StatusCategoryTodo       = "todo"
+	StatusCategoryInProgress = "in_progress"
+	StatusCategoryDone       = "done"
+)
+
+var (
+	ErrUnknownStatus        = errors.New("unknown status")
+	ErrTransitionNotAllowed = errors.New("transition not allowed")
+	ErrWIPLimitReached      = errors.New("wip limit reached")
+	ErrInvalidWorkflow      = errors.New("invalid workflow")
+)
+
+var statusKeyPattern = regexp.MustCompile(`^[a-z0-9_]{1,50}$`)
+
+type WorkflowStatus struct {
+	Key      string `json:"key"`
+	Name     string `json:"name"`
+	Category string `json:"category"`
+	WIPLimit *int   `json:"wip_limit,omitempty"`
+}
+
+type WorkflowTransition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+type WorkflowStatuses []WorkflowStatus
+
+func (s WorkflowStatuses) Value() (driver.Value, error) {
+	return json.Marshal(s)
+}
+
+func (s *WorkflowStatuses) Scan(src interface{}) error {
+	return scanJSON(src, s)
+}
+
+type WorkflowTransitions []WorkflowTransition
+
+func (t WorkflowTransitions) Value() (driver.Value, error) {
+	if t == nil {
+		return []byte("[]"), nil
+	}
+	return json.Marshal(t)
+}
+
+func (t *WorkflowTransitions) Scan(src interface{}) error {
+	return scanJSON(src, t)
+}
+
+// Workflow describes the columns of a project's board. An empty transition
+// list means any status can move to any other.
+type Workflow struct {
+	ProjectID   uuid.UUID           `json:"project_id" db:"project_id"`
+	Statuses    WorkflowStatuses    `json:"statuses" db:"statuses"`
+	Transitions WorkflowTransitions `json:"transitions" db:"transitions"`
+	// BlockOpenSubtasks keeps a task out of done statuses while any of its
+	// subtasks is still open.
+	BlockOpenSubtasks bool `json:"block_open_subtasks" db:"block_open_subtasks"`
+	// BlockOnDependencies keeps a task out of done statuses while any task
+	// blocking it is still open.
+	BlockOnDependencies bool      `json:"block_on_dependencies" db:"block_on_dependencies"`
+	UpdatedAt           time.Time `json:"updated_at" db:"updated_at"`
+	IsDefault           bool      `json:"is_default" db:"-"`
+}
+
+// DefaultWorkflow is used for projects that have not defined their own and
+// matches the statuses tasks have always used.
+func DefaultWorkflow(projectID uuid.UUID) *Workflow {
+	return &Workflow{
+		ProjectID: projectID,
+		Statuses: WorkflowStatuses{
+			{Key: "todo", Name: "To Do", Category: StatusCategoryTodo},
+			{Key: "in_progress", Name: "In Progress", Category: StatusCategoryInProgress},
+			{Key: "done", Name: "Done", Category: StatusCategoryDone},
+		},
+		Transitions: WorkflowTransitions{},
+		IsDefault:   true,
+	}
+}
+
+func (w *Workflow) Status(key string) (WorkflowStatus, bool) {
+	for _, st := range w.Statuses {
+		if st.Key == key {
+			return st, true
+		}
+	}
+	return WorkflowStatus{}, false
+}
+
+// InitialStatus is the status new tasks get when none is given: the first
+// status in the todo category, or the first status overall.
+func (w *Workflow) InitialStatus() string {
+	for _, st := range w.Statuses {
+		if st.Category == StatusCategoryTodo {
+			return st.Key
+		}
+	}
+	if len(w.Statuses) > 0 {
+		return w.Statuses[0].Key
+	}
+	return ""
+}
+
+func (w *Workflow) IsDone(key string) bool {
+	st, ok := w.Status(key)
+	return ok && st.Category == StatusCategoryDone
+}
+
+func (w *Workflow) statusKeys() string {
+	keys := make([]string, len(w.Statuses))
+	for i, st := range w.Statuses {
+		keys[i] = st.Key
+	}
+	return strings.Join(keys, ", ")
+}
+
+// CheckStatus verifies that key is a status of the workflow.
+func (w *Workflow) CheckStatus(key string) error {
+	if _, ok := w.Status(key); !ok {
+		return fmt.Errorf("%w %q, allowed statuses: %s", ErrUnknownStatus, key, w.statusKeys())
+	}
+	return nil
+}
+
+// CheckTransition verifies that a task may move from one status to another.
+func (w *Workflow) CheckTransition(from, to string) error {
+	if err := w.CheckStatus(to); err != nil {
+		return err
+	}
+	if from == to || len(w.Transitions) == 0 {
+		return nil
+	}
+	// Tasks stuck in a status that no longer exists may always be moved out.
+	if _, ok := w.Status(from); !ok {
+		return nil
+	}
+
+	var allowed []string
+	for _, tr := range w.Transitions {
+		if tr.From != from {
+			continue
+		}
+		if tr.To == to {
+			return nil
+		}
+		allowed = append(allowed, tr.To)
+	}
+	if len(allowed) == 0 {
+		return fmt.Errorf("%w: no transitions are allowed from %q", ErrTransitionNotAllowed, from)
+	}
+	return fmt.Errorf("%w: %q -> %q, allowed targets: %s",
+		ErrTransitionNotAllowed, from, to, strings.Join(allowed, ", "))
+}
+
+func (w *Workflow) Validate() error {
+	if len(w.Statuses) == 0 {
+		return fmt.Errorf("%w: at least one status is required", ErrInvalidWorkflow)
+	}
+
+	seen := make(map[string]bool, len(w.Statuses))
+	for _, st := range w.Statuses {
+		if !statusKeyPattern.MatchString(st.Key) {
+			return fmt.Errorf("%w: status key %q must be 1-50 lowercase letters, digits or underscores", ErrInvalidWorkflow, st.Key)
+		}
+		if seen[st.Key] {
+			return fmt.Errorf("%w: duplicate status %q", ErrInvalidWorkflow, st.Key)
+		}
+		seen[st.Key] = true
+
+		if strings.TrimSpace(st.Name) == "" {
+			return fmt.Errorf("%w: status %q needs a name", ErrInvalidWorkflow, st.Key)
+		}
+		switch st.Category {
+		case StatusCategoryTodo, StatusCategoryInProgress, StatusCategoryDone:
+		default:
+			return fmt.Errorf("%w: status %q has invalid category %q", ErrInvalidWorkflow, st.Key, st.Category)
+		}
+		if st.WIPLimit != nil && *st.WIPLimit < 1 {
+			return fmt.Errorf("%w: status %q has a WIP limit below 1", ErrInvalidWorkflow, st.Key)
+		}
+	}
+
+	for _, tr := range w.Transitions {
+		if !seen[tr.From] || !seen[tr.To] {
+			return fmt.Errorf("%w: transition %q -> %q references an unknown status", ErrInvalidWorkflow, tr.From, tr.To)
+		}
+	}
+
+	return nil
+}
+
+type UpdateWorkflowRequest struct {
+	Statuses            WorkflowStatuses    `json:"statuses" binding:"required"`
+	Transitions         WorkflowTransitions `json:"transitions"`
+	BlockOpenSubtasks   bool                `json:"block_open_subtasks"`
+	BlockOnDependencies bool                `json:"block_on_dependencies"`
+	// Renames maps old status keys to new ones; tasks in the old status are
+	// moved to the new key when the workflow is saved.
+	Renames map[string]string `json:"renames"`
+}
@@ -0,0 +1,177 @@
+package notify
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+// InAppChannel stores notifications for the notification center.
+type InAppChannel struct {
+	store *store.Store
+}
+
+func NewInAppChannel(s *store.Store) *InAppChannel {
+	return &InAppChannel{store: s}
+}
+
+func (c *InAppChannel) Name() string { return "in-app" }
+
+func (c *InAppChannel) Deliver(_ context.Context, n *models.Notification, _ *models.User) error {
+	return c.store.CreateNotification(n)
+}
+
+// smtpTimeout bounds a whole SMTP session, from dialling to QUIT.
+const smtpTimeout = 30 * time.Second
+
+// EmailChannel sends notifications as plain-text mail over SMTP.
+type EmailChannel struct {
+	host string
+	addr string
+	from string
+	auth smtp.Auth
+}
+
+// NewEmailChannel uses PLAIN auth when user is set, which net/smtp only
+// allows over TLS or to localhost.
+func NewEmailChannel(host, port, user, password, from string) *EmailChannel {
+	var auth smtp.Auth
+	if user != "" {
+		auth = smtp.PlainAuth("", user, password, host)
+	}
+	return &EmailChannel{host: host, addr: net.JoinHostPort(host, port), from: from, auth: auth}
+}
+
+func (c *EmailChannel) Name() string { return "email" }
+
+func (c *EmailChannel) Deliver(ctx context.Context, n *models.Notification, recipient *models.User) error {
+	var msg strings.Builder
+	fmt.Fprintf(&msg, "From: %s\r\n", c.from)
+	fmt.Fprintf(&msg, "To: %s\r\n", recipient.Email)
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mime.QEncoding.Encode("UTF-8", n.Title))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=UTF-8\r\n\r\n")
+	msg.WriteString(n.Title)
+	if n.Body != "" {
+		msg.WriteString("\r\n\r\n" + n.Body)
+	}
+	msg.WriteString("\r\n")
+
+	if err := c.send(ctx, recipient.Email, []byte(msg.String())); err != nil {
+		return fmt.Errorf("failed to send mail: %w", err)
+	}
+	return nil
+}
+
+// send does what smtp.SendMail does, but over a connection with a deadline
+// so an unresponsive server cannot stall delivery.
+func (c *EmailChannel) send(ctx context.Context, to string, msg []byte) error {
+	dialer := net.Dialer{Timeout: smtpTimeout}
+	conn, err := dialer.DialContext(ctx, "tcp", c.addr)
+	if err != nil {
+		return err
+	}
+	if err := conn.SetDeadline(time.Now().Add(smtpTimeout)); err != nil {
+		conn.Close()
+		return err
+	}
+
+	client, err := smtp.NewClient(conn, c.host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: c.host}); err != nil {
+			return err
+		}
+	}
+	if c.auth != nil {
+		if ok, _ := client.Extension("AUTH"); !ok {
+			return errors.New("server does not support AUTH")
+		}
+		if err := client.Auth(c.auth); err != nil {
+			return err
+		}
+	}
+	if err := client.Mail(c.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(to); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(msg); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
+
+// WebhookChannel posts notifications as JSON to a URL, e.g. a chat bot.
+type WebhookChannel struct {
+	url    string
+	client *http.Client
+}
+
+func NewWebhookChannel(url string) *WebhookChannel {
+	return &WebhookChannel{url: url, client: &http.Client{Timeout: 10 * time.Second}}
+}
+
+func (c *WebhookChannel) Name() string { return "webhook" }
+
+type webhookPayload struct {
+	Notification *models.Notification `json:"notification"`
+	Recipient    webhookRecipient     `json:"recipient"`
+}
+
+type webhookRecipient struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Email string `json:"email"`
+}
+
+func (c *WebhookChannel) Deliver(ctx context.Context, n *models.Notification, recipient *models.User) error {
+	body, err := json.Marshal(webhookPayload{
+		Notification: n,
+		Recipient:    webhookRecipient{ID: recipient.ID.String(), Name: recipient.Name, Email: recipient.Email},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to build webhook request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call webhook: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with %s", resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,205 @@
+// Package notify creates user notifications and delivers them through the
+// configured channels.
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/store"
+)
+
+// Channel delivers a notification to its recipient.
+type Channel interface {
+	Name() string
+	Deliver(ctx context.Context, n *models.Notification, recipient *models.User) error
+}
+
+// queueSize is how many notifications wait for delivery, in the notifier
+// and in each channel, before new ones are dropped.
+const queueSize = 256
+
+// Notifier queues notifications and delivers them in the background, so
+// slow channels such as email do not hold up requests. Each channel has its
+// own queue and worker, so a slow channel does not hold up the others.
+type Notifier struct {
+	store   *store.Store
+	workers []*channelWorker
+	queue   chan queued
+}
+
+// queued is a notification waiting for delivery. Stored ones are already
+// in the database and skip the in-app channel.
+type queued struct {
+	notification *models.Notification
+	stored       bool
+}
+
+type delivery struct {
+	notification *models.Notification
+	recipient    *models.User
+}
+
+type channelWorker struct {
+	channel Channel
+	queue   chan delivery
+}
+
+func New(s *store.Store, channels ...Channel) *Notifier {
+	workers := make([]*channelWorker, len(channels))
+	for i, ch := range channels {
+		workers[i] = &channelWorker{channel: ch, queue: make(chan delivery, queueSize)}
+	}
+	return &Notifier{
+		store:   s,
+		workers: workers,
+		queue:   make(chan queued, queueSize),
+	}
+}
+
+// Run delivers queued notifications until ctx is cancelled.
+func (n *Notifier) Run(ctx context.Context) {
+	for _, w := range n.workers {
+		go w.run(ctx)
+	}
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case q := <-n.queue:
+			n.deliver(q)
+		}
+	}
+}
+
+func (n *Notifier) deliver(q queued) {
+	notification := q.notification
+	recipient, err := n.store.GetUserByID(notification.UserID)
+	if err != nil || recipient == nil {
+		log.Printf("Failed to load recipient %s of notification %s: %v", notification.UserID, notification.ID, err)
+		return
+	}
+	for _, w := range n.workers {
+		if _, inApp := w.channel.(*InAppChannel); inApp && q.stored {
+			continue
+		}
+		select {
+		case w.queue <- delivery{notification: notification, recipient: recipient}:
+		default:
+			log.Printf("Dropping notification %s via %s: queue is full", notification.ID, w.channel.Name())
+		}
+	}
+}
+
+func (w *channelWorker) run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case d := <-w.queue:
+			if err := w.channel.Deliver(ctx, d.notification, d.recipient); err != nil {
+				log.Printf("Failed to deliver notification %s via %s: %v", d.notification.ID, w.channel.Name(), err)
+			}
+		}
+	}
+}
+
+// Send queues one notification per recipient. The actor is never notified of
+// their own actions. Send never blocks: when the queue is full the
+// notification is dropped.
+func (n *Notifier) Send(kind string, task *models.Task, actorID *uuid.UUID, recipients []uuid.UUID, title, body string) {
+	seen := make(map[uuid.UUID]bool)
+	for _, userID := range recipients {
+		if seen[userID] || (actorID != nil && *actorID == userID) {
+			continue
+		}
+		seen[userID] = true
+		n.enqueue(newNotification(kind, task, actorID, userID, title, body), false)
+	}
+}
+
+func newNotification(kind string, task *models.Task, actorID *uuid.UUID, userID uuid.UUID, title, body string) *models.Notification {
+	notification := &models.Notification{
+		ID:        uuid.New(),
+		UserID:    userID,
+		Kind:      kind,
+		ActorID:   actorID,
+		Title:     title,
+		Body:      body,
+		CreatedAt: time.Now(),
+	}
+	if task != nil {
+		notification.ProjectID = &task.ProjectID
+		notification.TaskID = &task.ID
+	}
+	return notification
+}
+
+func (n *Notifier) enqueue(notification *models.Notification, stored bool) {
+	select {
+	case n.queue <- queued{notification: notification, stored: stored}:
+	default:
+		log.Printf("Dropping notification %s to %s: queue is full", notification.ID, notification.UserID)
+	}
+}
+
+// TaskAssigned notifies users newly assigned to a task.
+func (n *Notifier) TaskAssigned(task *models.Task, actorID uuid.UUID, userIDs []uuid.UUID) {
+	n.Send(models.NotificationAssigned, task, &actorID, userIDs,
+		fmt.Sprintf("You were assigned to %q", task.Title), "")
+}
+
+// TaskCommented notifies the watchers of a task about a new comment, except
+// those in skip who were already notified otherwise.
+func (n *Notifier) TaskCommented(task *models.Task, comment *models.TaskComment, skip []uuid.UUID) {
+	watchers, err := n.store.GetTaskWatchers(task.ID)
+	if err != nil {
+		log.Printf("Failed to get watchers of task %s: %v", task.ID, err)
+		return
+	}
+	skipped := make(map[uuid.UUID]bool, len(skip))
+	for _, id := range skip {
+		skipped[id] = true
+	}
+	recipients := make([]uuid.UUID, 0, len(watchers))
+	for _, w := range watchers {
+		if !skipped[w.UserID] {
+			recipients = append(recipients, w.UserID)
+		}
+	}
+	n.Send(models.NotificationComment, task, &comment.UserID, recipients,
+		fmt.Sprintf("New comment on %q", task.Title), excerpt(comment.Content))
+}
+
+// CommentMentioned notifies users mentioned in a comment.
+func (n *Notifier) CommentMentioned(task *models.Task, comment *models.TaskComment, userIDs []uuid.UUID) {
+	n.Send(models.NotificationMentioned, task, &comment.UserID, userIDs,
+		fmt.Sprintf("You were mentioned in %q", task.Title), excerpt(comment.Content))
+}
+
+// CommentReplied notifies the author of a comment about a reply to it.
+func (n *Notifier) CommentReplied(task *models.Task, reply *models.TaskComment, parentAuthorID uuid.UUID) {
+	n.Send(models.NotificationComment, task, &reply.UserID, []uuid.UUID{parentAuthorID},
+		fmt.Sprintf("New reply to your comment on %q", task.Title), excerpt(reply.Content))
+}
+
+// EntryReviewed tells the author of a proposed global constant or formula
+// whether a curator approved or rejected it.
+func (n *Notifier) EntryReviewed(reviewerID, authorID uuid.UUID, entityType, name, status, note string) {
+	n.Send(models.NotificationReviewed, nil, &reviewerID, []uuid.UUID{authorID},
+		fmt.Sprintf("Your global %s %q was %s", entityType, name, status), excerpt(note))
+}
+
+// excerpt shortens text for notification bodies.
+func excerpt(text string) string {
+	const max = 200
+	runes := []rune(text)
+	if len(runes) <= max {
+		return text
+	}
+	return string(runes[:max]) + "…"
+}
@@ -0,0 +1,93 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+)
+
+// reminderLookback bounds how long after its deadline a task still gets its
+// overdue reminder, e.g. after the scheduler was down.
+const reminderLookback = 7 * 24 * time.Hour
+
+// RunDeadlineReminders checks for due and overdue tasks every interval until
+// ctx is cancelled. Assignees get one reminder when a task comes within
+// window of its deadline and one more once it is overdue.
+func (n *Notifier) RunDeadlineReminders(ctx context.Context, interval, window time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := n.sendDeadlineReminders(time.Now(), window); err != nil {
+			log.Printf("Failed to send deadline reminders: %v", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (n *Notifier) sendDeadlineReminders(now time.Time, window time.Duration) error {
+	projectIDs, err := n.store.GetAllProjectIDs()
+	if err != nil {
+		return err
+	}
+
+	// Date-only due dates are stored at 23:59:59 UTC and become overdue a
+	// second later, so the query looks that second further ahead.
+	for _, projectID := range projectIDs {
+		tasks, err := n.store.GetTasksDueForReminders(projectID, window+time.Second, reminderLookback)
+		if err != nil {
+			return err
+		}
+		refs := make([]*models.Task, len(tasks))
+		for i := range tasks {
+			refs[i] = &tasks[i]
+		}
+		if err := n.store.AttachTaskDetails(refs...); err != nil {
+			return err
+		}
+
+		for i := range tasks {
+			if err := n.remindTask(&tasks[i], now, window); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (n *Notifier) remindTask(task *models.Task, now time.Time, window time.Duration) error {
+	deadline := services.Deadline(*task.DueDate)
+	kind, title := models.NotificationDeadlineSoon, fmt.Sprintf("%q is due soon", task.Title)
+	switch {
+	case now.After(deadline):
+		kind, title = models.NotificationOverdue, fmt.Sprintf("%q is overdue", task.Title)
+	case deadline.Sub(now) > window:
+		return nil
+	}
+	body := "Due " + task.DueDate.UTC().Format("2006-01-02 15:04 MST")
+	if !deadline.Equal(*task.DueDate) {
+		body = "Due " + task.DueDate.UTC().Format("2006-01-02")
+	}
+
+	// The in-app notification is stored with the claim; only the other
+	// channels go through the queue and may drop it when it is full.
+	for _, userID := range task.Assignees {
+		notification := newNotification(kind, task, nil, userID, title, body)
+		claimed, err := n.store.ClaimDeadlineReminder(task.ID, userID, kind, *task.DueDate, notification)
+		if err != nil {
+			return err
+		}
+		if claimed {
+			n.enqueue(notification, true)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,175 @@
+package services
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+var ErrUnsupportedArchiveVersion = errors.New("unsupported archive schema version")
+
+const archiveAttachmentsDir = "attachments"
+
+// archiveWorkflowFile holds the project's own workflow. Projects on the
+// default workflow, and archives made before workflows were exported, have
+// none.
+const archiveWorkflowFile = "workflow.json"
+
+func archiveSections(a *models.ProjectArchive) []struct {
+	name string
+	data interface{}
+} {
+	return []struct {
+		name string
+		data interface{}
+	}{
+		{"project.json", &a.Project},
+		{"members.json", &a.Members},
+		{"tasks.json", &a.Tasks},
+		{"tags.json", &a.Tags},
+		{"comments.json", &a.Comments},
+		{"boards.json", &a.Boards},
+		{"constants.json", &a.Constants},
+		{"formulas.json", &a.Formulas},
+		{"attachments.json", &a.Attachments},
+	}
+}
+
+// WriteProjectArchive streams the archive as a ZIP file. Attachment files are
+// copied from disk; attachments whose file is gone are still listed but get
+// an empty ArchivePath.
+func WriteProjectArchive(w io.Writer, a *models.ProjectArchive) error {
+	zw := zip.NewWriter(w)
+
+	for i := range a.Attachments {
+		att := &a.Attachments[i]
+		att.ArchivePath = ""
+
+		src, err := os.Open(att.FilePath)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue
+			}
+			return fmt.Errorf("failed to open attachment %s: %w", att.ID, err)
+		}
+
+		archivePath := path.Join(archiveAttachmentsDir, att.ID.String()+filepath.Ext(att.Filename))
+		dst, err := zw.Create(archivePath)
+		if err != nil {
+			src.Close()
+			return fmt.Errorf("failed to add attachment %s: %w", att.ID, err)
+		}
+		_, err = io.Copy(dst, src)
+		src.Close()
+		if err != nil {
+			return fmt.Errorf("failed to copy attachment %s: %w", att.ID, err)
+		}
+		att.ArchivePath = archivePath
+	}
+
+	if err := writeArchiveJSON(zw, "manifest.json", a.Manifest); err != nil {
+		return err
+	}
+	for _, section := range archiveSections(a) {
+		if err := writeArchiveJSON(zw, section.name, section.data); err != nil {
+			return err
+		}
+	}
+	if a.Workflow != nil {
+		if err := writeArchiveJSON(zw, archiveWorkflowFile, a.Workflow); err != nil {
+			return err
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("failed to finalize archive: %w", err)
+	}
+	return nil
+}
+
+func writeArchiveJSON(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return fmt.Errorf("failed to add %s: %w", name, err)
+	}
+	enc := json.NewEncoder(f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to encode %s: %w", name, err)
+	}
+	return nil
+}
+
+// ReadProjectArchive parses the manifests of an exported project and checks
+// the schema version. The returned zip.Reader gives access to attachment files.
+func ReadProjectArchive(r io.ReaderAt, size int64) (*models.ProjectArchive, *zip.Reader, error) {
+	zr, err := zip.NewReader(r, size)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid archive: %w", err)
+	}
+
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	var a models.ProjectArchive
+	manifest, ok := files["manifest.json"]
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid archive: manifest.json is missing")
+	}
+	if err := readArchiveJSON(manifest, &a.Manifest); err != nil {
+		return nil, nil, err
+	}
+	if a.Manifest.SchemaVersion < 1 || a.Manifest.SchemaVersion > models.ArchiveSchemaVersion {
+		return nil, nil, fmt.Errorf("%w: got %d, supported up to %d",
+			ErrUnsupportedArchiveVersion, a.Manifest.SchemaVersion, models.ArchiveSchemaVersion)
+	}
+
+	for _, section := range archiveSections(&a) {
+		f, ok := files[section.name]
+		if !ok {
+			return nil, nil, fmt.Errorf("invalid archive: %s is missing", section.name)
+		}
+		if err := readArchiveJSON(f, section.data); err != nil {
+			return nil, nil, err
+		}
+	}
+	if f, ok := files[archiveWorkflowFile]; ok {
+		if err := readArchiveJSON(f, &a.Workflow); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return &a, zr, nil
+}
+
+func readArchiveJSON(f *zip.File, v interface{}) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+
+	if err := json.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("invalid archive: failed to decode %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// OpenArchiveFile returns a reader for a file stored inside the archive.
+func OpenArchiveFile(zr *zip.Reader, name string) (io.ReadCloser, error) {
+	for _, f := range zr.File {
+		if f.Name == name {
+			return f.Open()
+		}
+	}
+	return nil, fmt.Errorf("file %s not found in archive", name)
+}
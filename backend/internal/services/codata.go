@@ -0,0 +1,93 @@
+package services
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+var (
+	ErrNotCODATATable = errors.New("not a CODATA allascii table: column header not found")
+
+	codataVersionRe = regexp.MustCompile(`(?:(\d{4})\s+CODATA|CODATA\s+(\d{4}))`)
+	codataColumnsRe = regexp.MustCompile(`\S(?:\S| \S)*`)
+)
+
+// ParseCODATA reads a table in the format of NIST's "allascii.txt": a
+// preamble naming the adjustment ("2018 CODATA adjustment"), a header line
+// with the Quantity, Value, Uncertainty and Unit columns, a line of dashes
+// and one constant per line. Columns are separated by at least two spaces;
+// digit groups inside numbers by single ones. Rows whose value or unit does
+// not parse are returned in Skipped rather than failing the whole table.
+func ParseCODATA(r io.Reader) (*models.CODATATable, error) {
+	table := &models.CODATATable{}
+	scanner := bufio.NewScanner(r)
+	inBody := false
+	lineNo := 0
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), " \r")
+
+		if !inBody {
+			if m := codataVersionRe.FindStringSubmatch(line); m != nil && table.Version == "" {
+				table.Version = m[1] + m[2]
+			}
+			if strings.Contains(line, "Quantity") && strings.Contains(line, "Value") && strings.Contains(line, "Uncertainty") {
+				inBody = true
+			}
+			continue
+		}
+		if strings.TrimSpace(line) == "" || strings.Trim(line, "-") == "" {
+			continue
+		}
+
+		fields := codataColumnsRe.FindAllString(line, -1)
+		if len(fields) < 3 || len(fields) > 4 {
+			return nil, fmt.Errorf("line %d: expected 3 or 4 columns, got %d", lineNo, len(fields))
+		}
+		entry, err := parseCODATAEntry(fields)
+		if err != nil {
+			table.Skipped = append(table.Skipped, models.CODATASkipped{Name: fields[0], Reason: err.Error()})
+			continue
+		}
+		table.Entries = append(table.Entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read CODATA table: %w", err)
+	}
+	if !inBody {
+		return nil, ErrNotCODATATable
+	}
+	if table.Version == "" {
+		table.Version = "unknown"
+	}
+	return table, nil
+}
+
+func parseCODATAEntry(fields []string) (models.CODATAEntry, error) {
+	entry := models.CODATAEntry{Name: fields[0], Value: strings.ReplaceAll(fields[1], " ", "")}
+	if len(fields) == 4 {
+		entry.Unit = fields[3]
+	}
+
+	var err error
+	if entry.Number, _, err = units.ParseValue(fields[1]); err != nil {
+		return entry, err
+	}
+	if fields[2] == "(exact)" {
+		entry.Exact = true
+	} else if entry.Uncertainty, _, err = units.ParseValue(fields[2]); err != nil {
+		return entry, err
+	}
+	if entry.ParsedUnit, err = units.Parse(entry.Unit); err != nil {
+		return entry, err
+	}
+	return entry, nil
+}
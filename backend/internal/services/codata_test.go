@@ -0,0 +1,130 @@
+package services
+
+import (
+	"errors"
+	"math"
+	"os"
+	"strings"
+	"testing"
+)
+
+// testdata/allascii.txt is an excerpt of NIST's 2018 allascii.txt in its
+// original column layout, with one row whose unit does not parse.
+func TestParseCODATA(t *testing.T) {
+	f, err := os.Open("testdata/allascii.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+
+	table, err := ParseCODATA(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Version != "2018" {
+		t.Errorf("Version = %q, want 2018", table.Version)
+	}
+
+	tests := []struct {
+		name        string
+		value       string
+		number      float64
+		uncertainty float64
+		exact       bool
+		unit        string
+		dim         string
+	}{
+		// No unit column: a dimensionless ratio.
+		{"alpha particle-electron mass ratio", "7294.29954142", 7294.29954142, 2.4e-7, false, "", "1"},
+		// Digit groups in both the value and the uncertainty, which shares
+		// the value's exponent.
+		{"alpha particle mass", "6.6446573357e-27", 6.6446573357e-27, 2.0e-36, false, "kg", "kg"},
+		{"Avogadro constant", "6.02214076e23", 6.02214076e23, 0, true, "mol^-1", "mol^-1"},
+		{"Boltzmann constant", "1.380649e-23", 1.380649e-23, 0, true, "J K^-1", "kg·m^2·s^-2·K^-1"},
+		// A truncated exact value keeps its "..." in Value only.
+		{"electron volt-hertz relationship", "2.417989242...e14", 2.417989242e14, 0, true, "Hz", "s^-1"},
+		{"elementary charge", "1.602176634e-19", 1.602176634e-19, 0, true, "C", "s·A"},
+		{"Fermi coupling constant", "1.1663787e-5", 1.1663787e-5, 6e-12, false, "GeV^-2", "s^4·kg^-2·m^-4"},
+		{"Newtonian constant of gravitation", "6.67430e-11", 6.6743e-11, 1.5e-15, false, "m^3 kg^-1 s^-2", "m^3·kg^-1·s^-2"},
+		{"speed of light in vacuum", "299792458", 299792458, 0, true, "m s^-1", "m·s^-1"},
+	}
+	if len(table.Entries) != len(tests) {
+		t.Fatalf("got %d entries, want %d", len(table.Entries), len(tests))
+	}
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := table.Entries[i]
+			if e.Name != tt.name {
+				t.Fatalf("Name = %q, want %q", e.Name, tt.name)
+			}
+			if e.Value != tt.value {
+				t.Errorf("Value = %q, want %q", e.Value, tt.value)
+			}
+			if math.Abs(e.Number-tt.number) > 1e-12*tt.number {
+				t.Errorf("Number = %g, want %g", e.Number, tt.number)
+			}
+			if math.Abs(e.Uncertainty-tt.uncertainty) > 1e-12*tt.number {
+				t.Errorf("Uncertainty = %g, want %g", e.Uncertainty, tt.uncertainty)
+			}
+			if e.Exact != tt.exact {
+				t.Errorf("Exact = %v, want %v", e.Exact, tt.exact)
+			}
+			if e.Unit != tt.unit {
+				t.Errorf("Unit = %q, want %q", e.Unit, tt.unit)
+			}
+			if got := e.ParsedUnit.Dim.String(); got != tt.dim {
+				t.Errorf("ParsedUnit.Dim = %s, want %s", got, tt.dim)
+			}
+		})
+	}
+
+	if len(table.Skipped) != 1 {
+		t.Fatalf("Skipped = %+v, want one row", table.Skipped)
+	}
+	if s := table.Skipped[0]; s.Name != "natural unit of momentum in MeV/c" || !strings.Contains(s.Reason, `unknown unit "c"`) {
+		t.Errorf("Skipped[0] = %+v, want the MeV/c row with an unknown unit", s)
+	}
+}
+
+func TestParseCODATAVersion(t *testing.T) {
+	const body = "  Quantity    Value    Uncertainty    Unit\n" +
+		"-------------------------------------------\n" +
+		"elementary charge    1.602 176 634 e-19    (exact)    C\n"
+
+	tests := []struct {
+		preamble string
+		version  string
+	}{
+		{"             2018 CODATA adjustment\n", "2018"},
+		{"  Values of CODATA 2022\n", "2022"},
+		{"  Fundamental Physical Constants\n", "unknown"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.version, func(t *testing.T) {
+			table, err := ParseCODATA(strings.NewReader(tt.preamble + body))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if table.Version != tt.version {
+				t.Errorf("Version = %q, want %q", table.Version, tt.version)
+			}
+			if len(table.Entries) != 1 {
+				t.Errorf("got %d entries, want 1", len(table.Entries))
+			}
+		})
+	}
+}
+
+func TestParseCODATAErrors(t *testing.T) {
+	if _, err := ParseCODATA(strings.NewReader("name,value\nc,299792458\n")); !errors.Is(err, ErrNotCODATATable) {
+		t.Errorf("CSV input: got %v, want ErrNotCODATATable", err)
+	}
+
+	input := "  Quantity    Value    Uncertainty    Unit\n" +
+		"-------------------------------------------\n" +
+		"elementary charge    1.602 176 634 e-19    (exact)    C    extra\n"
+	_, err := ParseCODATA(strings.NewReader(input))
+	if err == nil || err.Error() != "line 3: expected 3 or 4 columns, got 5" {
+		t.Errorf("five columns: got %v", err)
+	}
+}
@@ -0,0 +1,88 @@
+package services
+
+import (
+	"time"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// ParseDueDate parses a due date given as an RFC 3339 time or as a
+// YYYY-MM-DD date. A date without a time is stored as the last second of
+// that day in UTC, which IsDateOnly recognizes.
+func ParseDueDate(value string) (time.Time, error) {
+	if due, err := time.Parse(time.RFC3339, value); err == nil {
+		return due, nil
+	}
+	day, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return day.Add(24*time.Hour - time.Second), nil
+}
+
+// IsDateOnly reports whether a due date was given without a time, that is,
+// whether it is stored as 23:59:59 UTC.
+func IsDateOnly(due time.Time) bool {
+	utc := due.UTC()
+	return utc.Hour() == 23 && utc.Minute() == 59 && utc.Second() == 59 && utc.Nanosecond() == 0
+}
+
+// DueDay returns the calendar day a due date falls on in loc. Date-only due
+// dates keep their calendar day in every timezone.
+func DueDay(due time.Time, loc *time.Location) time.Time {
+	if IsDateOnly(due) {
+		utc := due.UTC()
+		return time.Date(utc.Year(), utc.Month(), utc.Day(), 0, 0, 0, 0, loc)
+	}
+	local := due.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+}
+
+// Deadline returns the moment a task becomes overdue: its due time, or the
+// end of the day in UTC for date-only due dates.
+func Deadline(due time.Time) time.Time {
+	if IsDateOnly(due) {
+		return due.Add(time.Second)
+	}
+	return due
+}
+
+// EndOfWeek returns the start of the Monday after now, in now's location.
+func EndOfWeek(now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	daysLeft := (7 - int(today.Weekday())) % 7
+	return today.AddDate(0, 0, daysLeft+1)
+}
+
+// GroupMyTasks sorts open tasks into dashboard groups relative to now, whose
+// location is the user's timezone. Timed tasks are overdue once their due
+// time has passed; date-only tasks once their day is over.
+func GroupMyTasks(tasks []models.MyTask, now time.Time) models.MyTasks {
+	loc := now.Location()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	weekEnd := EndOfWeek(now)
+
+	groups := models.MyTasks{
+		Timezone: loc.String(),
+		Overdue:  []models.MyTask{},
+		DueToday: []models.MyTask{},
+		ThisWeek: []models.MyTask{},
+		NoDate:   []models.MyTask{},
+	}
+	for _, t := range tasks {
+		if t.DueDate == nil {
+			groups.NoDate = append(groups.NoDate, t)
+			continue
+		}
+		day := DueDay(*t.DueDate, loc)
+		switch {
+		case day.Before(today), !IsDateOnly(*t.DueDate) && t.DueDate.Before(now):
+			groups.Overdue = append(groups.Overdue, t)
+		case day.Equal(today):
+			groups.DueToday = append(groups.DueToday, t)
+		case day.Before(weekEnd):
+			groups.ThisWeek = append(groups.ThisWeek, t)
+		}
+	}
+	return groups
+}
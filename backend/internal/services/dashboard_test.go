@@ -0,0 +1,88 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+func TestParseDueDate(t *testing.T) {
+	tests := []struct {
+		in       string
+		want     time.Time
+		dateOnly bool
+	}{
+		{"2026-03-10", time.Date(2026, 3, 10, 23, 59, 59, 0, time.UTC), true},
+		{"2026-03-10T15:30:00Z", time.Date(2026, 3, 10, 15, 30, 0, 0, time.UTC), false},
+		{"2026-03-10T00:00:00Z", time.Date(2026, 3, 10, 0, 0, 0, 0, time.UTC), false},
+		{"2026-03-10T23:59:59+03:00", time.Date(2026, 3, 10, 20, 59, 59, 0, time.UTC), false},
+	}
+	for _, tt := range tests {
+		got, err := ParseDueDate(tt.in)
+		if err != nil {
+			t.Fatalf("ParseDueDate(%q): %v", tt.in, err)
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("ParseDueDate(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+		if IsDateOnly(got) != tt.dateOnly {
+			t.Errorf("IsDateOnly(%q) = %v, want %v", tt.in, !tt.dateOnly, tt.dateOnly)
+		}
+	}
+
+	if _, err := ParseDueDate("10.03.2026"); err == nil {
+		t.Error("ParseDueDate accepted 10.03.2026")
+	}
+}
+
+func TestDateOnlyDueDatesInUTCPlus3(t *testing.T) {
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	due, err := ParseDueDate("2026-03-10")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if day := DueDay(due, loc); !day.Equal(time.Date(2026, 3, 10, 0, 0, 0, 0, loc)) {
+		t.Errorf("DueDay = %s, want 2026-03-10 in UTC+3", day)
+	}
+	if deadline := Deadline(due); !deadline.Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Deadline = %s, want the end of 2026-03-10 UTC", deadline)
+	}
+
+	timed := time.Date(2026, 3, 10, 22, 0, 0, 0, time.UTC)
+	if day := DueDay(timed, loc); !day.Equal(time.Date(2026, 3, 11, 0, 0, 0, 0, loc)) {
+		t.Errorf("DueDay of a timed task = %s, want 2026-03-11 in UTC+3", day)
+	}
+
+	task := models.MyTask{Task: models.Task{Title: "report", DueDate: &due}}
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"day before", time.Date(2026, 3, 9, 12, 0, 0, 0, loc), "this_week"},
+		{"early on the day", time.Date(2026, 3, 10, 1, 0, 0, 0, loc), "due_today"},
+		{"late on the day", time.Date(2026, 3, 10, 23, 30, 0, 0, loc), "due_today"},
+		{"day after", time.Date(2026, 3, 11, 1, 0, 0, 0, loc), "overdue"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			groups := GroupMyTasks([]models.MyTask{task}, tt.now)
+			got := map[string]int{
+				"overdue":   len(groups.Overdue),
+				"due_today": len(groups.DueToday),
+				"this_week": len(groups.ThisWeek),
+			}
+			for group, n := range got {
+				want := 0
+				if group == tt.want {
+					want = 1
+				}
+				if n != want {
+					t.Errorf("%s has %d tasks, want %d", group, n, want)
+				}
+			}
+		})
+	}
+}
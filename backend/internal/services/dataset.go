@@ -0,0 +1,459 @@
+package services
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/expr"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+var (
+	ErrEmptyTable = errors.New("the file has no header row")
+
+	// headerUnitRe splits "t [s]", "t (s)" and "t / s" into name and unit.
+	headerUnitRe = regexp.MustCompile(`^(.+?)\s*(?:\[(.+)\]|\((.+)\)|\s/\s*(.+))$`)
+	// uncertaintyRe matches the headers of uncertainty columns: "σ(t)",
+	// "u(t)", "Δt", "σ_t" or "sigma_t", named after the column they qualify.
+	uncertaintyRe = regexp.MustCompile(`^(?:(?:σ|u|Δ|δ|sigma|delta)\s*\((.+)\)|(?:σ_?|Δ|δ|sigma_|delta_)(.+))$`)
+)
+
+// DatasetTable is a parsed CSV or TSV file: its columns and the cells of
+// each row keyed by the columns' ids.
+type DatasetTable struct {
+	Columns []models.DatasetColumn
+	Rows    []models.DatasetCells
+}
+
+// ParseDatasetTable reads a delimited table with a header row. Tab
+// separated files are recognized by their .tsv extension or by their
+// header, and files separated by tabs or semicolons may use decimal commas.
+//
+// Headers may give the unit of a column as "t [s]", "t (s)" or "t / s". A
+// column is numeric when all its cells are numbers, which may carry their
+// own uncertainty as "1.23 ± 0.01" or "1.23(1)"; otherwise it holds text.
+// Columns headed "σ(t)", "u(t)", "Δt" or "σ_t" give the uncertainties of
+// column t rather than becoming columns of their own. Lines starting with #
+// are skipped.
+func ParseDatasetTable(r io.Reader, filename string) (*DatasetTable, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read file: %w", err)
+	}
+	text := strings.TrimPrefix(string(data), "\ufeff")
+
+	reader := csv.NewReader(strings.NewReader(text))
+	reader.Comma = detectDelimiter(text, filename)
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse table: %w", err)
+	}
+	if len(records) == 0 {
+		return nil, ErrEmptyTable
+	}
+	decimalComma := reader.Comma != ','
+
+	header := records[0]
+	var body [][]string
+	for _, record := range records[1:] {
+		if !isBlankRecord(record) {
+			body = append(body, record)
+		}
+	}
+	field := func(record []string, i int) string {
+		if i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	// Name the columns first, so that uncertainty columns can find the
+	// columns they belong to wherever they stand.
+	type source struct {
+		name, unit string
+		sigmaOf    int
+	}
+	sources := make([]source, len(header))
+	index := make(map[string]int, len(header))
+	for i, h := range header {
+		name, unit := splitHeader(strings.TrimSpace(h))
+		if name == "" {
+			name = fmt.Sprintf("column_%d", i+1)
+		}
+		sources[i] = source{name: name, unit: unit, sigmaOf: -1}
+		if _, ok := index[name]; !ok {
+			index[name] = i
+		}
+	}
+	for i, h := range header {
+		// "σ(t)" could also be read as σ in tonnes, so the whole header is
+		// tried before its name.
+		for _, candidate := range []source{{name: strings.TrimSpace(h)}, sources[i]} {
+			m := uncertaintyRe.FindStringSubmatch(candidate.name)
+			if m == nil {
+				continue
+			}
+			target, ok := index[strings.TrimSpace(m[1]+m[2])]
+			if ok && target != i && compatibleUnits(candidate.unit, sources[target].unit) {
+				sources[i] = source{name: candidate.name, unit: candidate.unit, sigmaOf: target}
+				break
+			}
+		}
+	}
+
+	table := &DatasetTable{Rows: make([]models.DatasetCells, len(body))}
+	for i := range table.Rows {
+		table.Rows[i] = models.DatasetCells{}
+	}
+	columnIDs := make([]uuid.UUID, len(header))
+	used := map[string]bool{}
+	for i, src := range sources {
+		if src.sigmaOf >= 0 {
+			continue
+		}
+		col := models.DatasetColumn{
+			ID:       uuid.New(),
+			Name:     uniqueName(src.name, used),
+			Kind:     models.ColumnNumber,
+			Unit:     src.unit,
+			Position: len(table.Columns),
+		}
+		columnIDs[i] = col.ID
+
+		cells := make([]models.DatasetCell, len(body))
+		for j, record := range body {
+			raw := field(record, i)
+			if raw == "" {
+				continue
+			}
+			value, sigma, err := parseCellNumber(raw, decimalComma)
+			if err != nil {
+				col.Kind = models.ColumnText
+				break
+			}
+			cells[j].Value = &value
+			if sigma > 0 {
+				cells[j].Uncertainty = &sigma
+			}
+		}
+		for j, record := range body {
+			if col.Kind == models.ColumnText {
+				cells[j] = models.DatasetCell{Text: field(record, i)}
+			}
+			if cells[j].Value != nil || cells[j].Text != "" {
+				table.Rows[j][col.ID] = cells[j]
+			}
+		}
+		table.Columns = append(table.Columns, col)
+	}
+
+	for i, src := range sources {
+		if src.sigmaOf < 0 {
+			continue
+		}
+		// The column qualified may itself have been taken for uncertainties.
+		k := indexOfColumn(table.Columns, columnIDs[src.sigmaOf])
+		if k < 0 || table.Columns[k].Kind != models.ColumnNumber {
+			continue
+		}
+		target := table.Columns[k]
+		from, to := units.MustParse(src.unit), units.MustParse(target.Unit)
+		for j, record := range body {
+			raw := field(record, i)
+			cell, ok := table.Rows[j][target.ID]
+			if raw == "" || !ok || cell.Uncertainty != nil {
+				continue
+			}
+			sigma, _, err := parseCellNumber(raw, decimalComma)
+			if err != nil {
+				return nil, fmt.Errorf("row %d: %s: %v", j+2, src.name, err)
+			}
+			if src.unit != "" {
+				sigma, _ = units.ConvertUncertainty(sigma, from, to)
+			}
+			sigma = math.Abs(sigma)
+			if sigma > 0 {
+				cell.Uncertainty = &sigma
+				table.Rows[j][target.ID] = cell
+			}
+		}
+	}
+	return table, nil
+}
+
+// detectDelimiter picks the separator used by the header line.
+func detectDelimiter(text, filename string) rune {
+	if strings.EqualFold(filepath.Ext(filename), ".tsv") {
+		return '\t'
+	}
+	var line string
+	for _, l := range strings.Split(text, "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "#") {
+			line = l
+			break
+		}
+	}
+	best, count := ',', strings.Count(line, ",")
+	for _, sep := range []rune{';', '\t'} {
+		if n := strings.Count(line, string(sep)); n > count {
+			best, count = sep, n
+		}
+	}
+	return best
+}
+
+func isBlankRecord(record []string) bool {
+	for _, f := range record {
+		if strings.TrimSpace(f) != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// splitHeader splits a column header into its name and unit. A bracketed
+// part that is not a unit stays in the name.
+func splitHeader(h string) (string, string) {
+	m := headerUnitRe.FindStringSubmatch(h)
+	if m == nil {
+		return h, ""
+	}
+	unit := strings.TrimSpace(m[2] + m[3] + m[4])
+	if _, err := units.Parse(unit); err != nil {
+		return h, ""
+	}
+	return strings.TrimSpace(m[1]), unit
+}
+
+func compatibleUnits(a, b string) bool {
+	if a == "" {
+		return true
+	}
+	ua, err := units.Parse(a)
+	if err != nil {
+		return false
+	}
+	ub, err := units.Parse(b)
+	return err == nil && ua.Dim == ub.Dim
+}
+
+func uniqueName(name string, used map[string]bool) string {
+	unique := name
+	for n := 2; used[unique]; n++ {
+		unique = name + "_" + strconv.Itoa(n)
+	}
+	used[unique] = true
+	return unique
+}
+
+func indexOfColumn(columns []models.DatasetColumn, id uuid.UUID) int {
+	for i, col := range columns {
+		if col.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+// parseCellNumber parses a number with an optional uncertainty. With
+// decimalComma, "1,5" is read as 1.5.
+func parseCellNumber(raw string, decimalComma bool) (float64, float64, error) {
+	if decimalComma && !strings.Contains(raw, ".") {
+		raw = strings.ReplaceAll(raw, ",", ".")
+	}
+	return units.ParseValue(raw)
+}
+
+// CellUncertainty returns the standard uncertainty of a cell: its own, or
+// else the column's default.
+func CellUncertainty(col models.DatasetColumn, cell models.DatasetCell) *float64 {
+	if cell.Uncertainty != nil {
+		return cell.Uncertainty
+	}
+	return col.Uncertainty
+}
+
+// DatasetStats summarizes the numeric columns of a dataset.
+func DatasetStats(columns []models.DatasetColumn, rows []models.DatasetRow) []models.ColumnStats {
+	stats := []models.ColumnStats{}
+	for _, col := range columns {
+		if col.Kind != models.ColumnNumber {
+			continue
+		}
+		st := models.ColumnStats{ColumnID: col.ID, Name: col.Name, Unit: col.Unit}
+
+		var values []float64
+		var sumW, sumWX float64
+		weighted := true
+		for _, row := range rows {
+			cell, ok := row.Cells[col.ID]
+			if !ok || cell.Value == nil {
+				continue
+			}
+			v := *cell.Value
+			values = append(values, v)
+			if sigma := CellUncertainty(col, cell); sigma != nil && *sigma > 0 {
+				w := 1 / (*sigma * *sigma)
+				sumW += w
+				sumWX += w * v
+			} else {
+				weighted = false
+			}
+		}
+		st.Count = len(values)
+		if st.Count == 0 {
+			stats = append(stats, st)
+			continue
+		}
+
+		mean, std := meanStd(values)
+		minValue, maxValue := values[0], values[0]
+		for _, v := range values {
+			minValue = math.Min(minValue, v)
+			maxValue = math.Max(maxValue, v)
+		}
+		st.Mean, st.Min, st.Max = &mean, &minValue, &maxValue
+		if st.Count > 1 {
+			stdErr := std / math.Sqrt(float64(st.Count))
+			st.StdDev, st.StdError = &std, &stdErr
+		}
+		if weighted {
+			wMean, wSigma := sumWX/sumW, 1/math.Sqrt(sumW)
+			st.WeightedMean, st.WeightedUncertainty = &wMean, &wSigma
+		}
+		stats = append(stats, st)
+	}
+	return stats
+}
+
+// DatasetColumnError reports a formula variable that cannot be read from
+// the dataset's columns.
+type DatasetColumnError struct {
+	Symbol  string
+	Message string
+}
+
+func (e *DatasetColumnError) Error() string {
+	return e.Symbol + ": " + e.Message
+}
+
+// DerivedColumn is the result of evaluating a formula over a dataset: the
+// cells of the rows it could be evaluated for, keyed by row id, and the
+// unit they are in.
+type DerivedColumn struct {
+	Unit   string
+	Cells  map[uuid.UUID]models.DatasetCell
+	Errors []models.DatasetRowError
+}
+
+// EvaluateDataset evaluates the formula for every row. Each variable is
+// read from the column mapped to it, or the column with its symbol, or else
+// from the scope's constants; skip names the column being derived, which
+// is never an input. Cell uncertainties are propagated linearly. Rows that
+// fail are reported in Errors instead of failing the whole evaluation.
+func EvaluateDataset(latex string, scope *FormulaScope, columns []models.DatasetColumn, rows []models.DatasetRow, mapping map[string]string, unit string, skip uuid.UUID) (*DerivedColumn, error) {
+	eq, err := expr.Parse(latex)
+	if err != nil {
+		return nil, err
+	}
+	_, node, err := eq.Solved()
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]models.DatasetColumn, len(columns))
+	bySymbol := make(map[string]models.DatasetColumn, len(columns))
+	for _, col := range columns {
+		if col.ID == skip {
+			continue
+		}
+		byName[col.Name] = col
+		if _, ok := bySymbol[expr.Symbol(col.Name)]; !ok {
+			bySymbol[expr.Symbol(col.Name)] = col
+		}
+	}
+	mapped := make(map[string]string, len(mapping))
+	for symbol, name := range mapping {
+		mapped[expr.Symbol(symbol)] = name
+	}
+
+	inputs := map[string]models.DatasetColumn{}
+	var missing []string
+	for _, name := range expr.Variables(node) {
+		col, ok := bySymbol[name]
+		if colName, isMapped := mapped[name]; isMapped {
+			if col, ok = byName[colName]; !ok {
+				return nil, &DatasetColumnError{Symbol: name, Message: fmt.Sprintf("no column %q", colName)}
+			}
+		}
+		if !ok {
+			if _, found := scope.constant(name); !found {
+				missing = append(missing, name)
+			}
+			continue
+		}
+		if col.Kind != models.ColumnNumber {
+			return nil, &DatasetColumnError{Symbol: name, Message: fmt.Sprintf("column %q does not hold numbers", col.Name)}
+		}
+		inputs[name] = col
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: missing}
+	}
+
+	out := &DerivedColumn{Unit: unit, Cells: map[uuid.UUID]models.DatasetCell{}, Errors: []models.DatasetRowError{}}
+	fail := func(row models.DatasetRow, err error) {
+		out.Errors = append(out.Errors, models.DatasetRowError{RowID: row.ID, Position: row.Position, Error: err.Error()})
+	}
+	for _, row := range rows {
+		req := models.PropagateUncertaintyRequest{Method: models.PropagationLinear}
+		req.Unit = unit
+		req.Variables = make(map[string]models.VariableValue, len(inputs))
+		var empty []string
+		for name, col := range inputs {
+			cell, ok := row.Cells[col.ID]
+			if !ok || cell.Value == nil {
+				empty = append(empty, col.Name)
+				continue
+			}
+			req.Variables[name] = models.VariableValue{Value: cell.Value, Uncertainty: CellUncertainty(col, cell), Unit: col.Unit}
+		}
+		if len(empty) > 0 {
+			fail(row, fmt.Errorf("no value in %s", strings.Join(mergeNames(empty, nil), ", ")))
+			continue
+		}
+
+		resp, err := PropagateUncertainty(latex, req, scope)
+		if err != nil {
+			var unitErr *units.Error
+			var incompatible *units.IncompatibleError
+			if errors.As(err, &unitErr) || errors.As(err, &incompatible) {
+				return nil, err
+			}
+			fail(row, err)
+			continue
+		}
+		if out.Unit == "" {
+			out.Unit = resp.Result.Unit
+		}
+		cell := models.DatasetCell{Value: &resp.Result.Value}
+		if len(resp.Contributions) > 0 {
+			cell.Uncertainty = &resp.Result.Uncertainty
+		}
+		out.Cells[row.ID] = cell
+	}
+	return out, nil
+}
@@ -0,0 +1,152 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// describeTable renders a parsed table compactly: one "name [unit] kind"
+// entry per column, then each row's cells as "value±uncertainty", "text"
+// or "-" when the row has no cell in that column.
+func describeTable(table *DatasetTable) (columns []string, rows []string) {
+	for _, col := range table.Columns {
+		columns = append(columns, fmt.Sprintf("%s [%s] %s", col.Name, col.Unit, col.Kind))
+	}
+	for _, row := range table.Rows {
+		var cells []string
+		for _, col := range table.Columns {
+			cell, ok := row[col.ID]
+			switch {
+			case !ok:
+				cells = append(cells, "-")
+			case cell.Value == nil:
+				cells = append(cells, fmt.Sprintf("%q", cell.Text))
+			case cell.Uncertainty == nil:
+				cells = append(cells, fmt.Sprintf("%g", *cell.Value))
+			default:
+				cells = append(cells, fmt.Sprintf("%g±%g", *cell.Value, *cell.Uncertainty))
+			}
+		}
+		rows = append(rows, strings.Join(cells, " "))
+	}
+	return columns, rows
+}
+
+func TestParseDatasetTable(t *testing.T) {
+	tests := []struct {
+		name     string
+		filename string
+		input    string
+		columns  []string
+		rows     []string
+	}{
+		// Delimiters and decimal commas.
+		{"comma", "data.csv", "t,x\n1,2.5\n2,3.5\n",
+			[]string{"t [] number", "x [] number"}, []string{"1 2.5", "2 3.5"}},
+		{"semicolon with decimal commas", "data.csv", "t;x\n1,5;2,25\n",
+			[]string{"t [] number", "x [] number"}, []string{"1.5 2.25"}},
+		{"tab in header", "data.txt", "t\tx\n1,5\t2\n",
+			[]string{"t [] number", "x [] number"}, []string{"1.5 2"}},
+		{"tsv extension", "data.TSV", "t\n1,5\n",
+			[]string{"t [] number"}, []string{"1.5"}},
+		{"no decimal commas with comma delimiter", "data.csv", "t,x\n1,\"1,5\"\n",
+			[]string{"t [] number", "x [] text"}, []string{`1 "1,5"`}},
+		{"decimal point wins over comma", "data.csv", "t;x\n1,5;1.5\n",
+			[]string{"t [] number", "x [] number"}, []string{"1.5 1.5"}},
+		{"bom, comments and blank lines", "data.csv", "\ufeff# run 1\nt,x\n\n1,2\n# pause\n,\n2,3\n",
+			[]string{"t [] number", "x [] number"}, []string{"1 2", "2 3"}},
+
+		// Units in headers.
+		{"brackets", "data.csv", "t [s],v [km/h]\n1,2\n",
+			[]string{"t [s] number", "v [km/h] number"}, []string{"1 2"}},
+		{"parentheses", "data.csv", "t (s),m(kg)\n1,2\n",
+			[]string{"t [s] number", "m [kg] number"}, []string{"1 2"}},
+		{"slash", "data.csv", "t / s,E/J\n1,2\n",
+			[]string{"t [s] number", "E/J [] number"}, []string{"1 2"}},
+		{"not a unit", "data.csv", "ratio (a.u.),sample [1]\n1,2\n",
+			[]string{"ratio (a.u.) [] number", "sample [1] number"}, []string{"1 2"}},
+		{"unnamed and duplicate columns", "data.csv", "t,,t\n1,2,3\n",
+			[]string{"t [] number", "column_2 [] number", "t_2 [] number"}, []string{"1 2 3"}},
+
+		// Cell uncertainties.
+		{"inline uncertainties", "data.csv", "t,x\n1.5 ± 0.1,2.34(5)\n",
+			[]string{"t [] number", "x [] number"}, []string{"1.5±0.1 2.34±0.05"}},
+		{"sigma of", "data.csv", "t [s],σ(t) [s]\n1.5,0.1\n",
+			[]string{"t [s] number"}, []string{"1.5±0.1"}},
+		{"sigma of without unit", "data.csv", "t (s),σ(t)\n1.5,0.1\n",
+			[]string{"t [s] number"}, []string{"1.5±0.1"}},
+		{"delta", "data.csv", "Δt,t\n0.1,1.5\n",
+			[]string{"t [] number"}, []string{"1.5±0.1"}},
+		{"sigma underscore", "data.csv", "t,σ_t,x,sigma_x,y,u(y)\n1,0.1,2,0.2,3,0.3\n",
+			[]string{"t [] number", "x [] number", "y [] number"}, []string{"1±0.1 2±0.2 3±0.3"}},
+		{"sigma converted to the column's unit", "data.csv", "t [s],σ(t) [ms],m [g],Δm [kg]\n1.5,20,10,0.001\n",
+			[]string{"t [s] number", "m [g] number"}, []string{"1.5±0.02 10±1"}},
+		{"sigma with decimal commas", "data.csv", "t;Δt\n1,5;0,1\n",
+			[]string{"t [] number"}, []string{"1.5±0.1"}},
+		{"inline uncertainty is kept", "data.csv", "t,Δt\n1.5 ± 0.2,0.1\n2,0.1\n",
+			[]string{"t [] number"}, []string{"1.5±0.2", "2±0.1"}},
+		{"zero sigma is dropped", "data.csv", "t,Δt\n1.5,0\n",
+			[]string{"t [] number"}, []string{"1.5"}},
+		{"sigma of a missing column", "data.csv", "t,Δx\n1,0.1\n",
+			[]string{"t [] number", "Δx [] number"}, []string{"1 0.1"}},
+		{"sigma in another dimension", "data.csv", "t [s],σ(t) [m]\n1,0.1\n",
+			[]string{"t [s] number", "σ(t) [m] number"}, []string{"1 0.1"}},
+
+		// Text columns.
+		{"text column", "data.csv", "sample,t\nA,1\nB,2\n",
+			[]string{"sample [] text", "t [] number"}, []string{`"A" 1`, `"B" 2`}},
+		{"one text cell makes a text column", "data.csv", "t,x\n1,2\n2,n/a\n",
+			[]string{"t [] number", "x [] text"}, []string{`1 "2"`, `2 "n/a"`}},
+		{"empty cells", "data.csv", "t,x\n1,\n2,3\n3\n",
+			[]string{"t [] number", "x [] number"}, []string{"1 -", "2 3", "3 -"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			table, err := ParseDatasetTable(strings.NewReader(tt.input), tt.filename)
+			if err != nil {
+				t.Fatal(err)
+			}
+			columns, rows := describeTable(table)
+			if strings.Join(columns, ", ") != strings.Join(tt.columns, ", ") {
+				t.Errorf("columns = %q, want %q", columns, tt.columns)
+			}
+			if strings.Join(rows, "; ") != strings.Join(tt.rows, "; ") {
+				t.Errorf("rows = %q, want %q", rows, tt.rows)
+			}
+			for i, col := range table.Columns {
+				if col.Position != i {
+					t.Errorf("%s.Position = %d, want %d", col.Name, col.Position, i)
+				}
+			}
+		})
+	}
+}
+
+func TestParseDatasetTableErrors(t *testing.T) {
+	if _, err := ParseDatasetTable(strings.NewReader("# only a comment\n"), "data.csv"); !errors.Is(err, ErrEmptyTable) {
+		t.Errorf("empty table: got %v, want ErrEmptyTable", err)
+	}
+
+	_, err := ParseDatasetTable(strings.NewReader("t,Δt\n1,0.1\n2,abc\n"), "data.csv")
+	if err == nil || !strings.HasPrefix(err.Error(), "row 3: Δt: ") {
+		t.Errorf("bad uncertainty: got %v, want an error for row 3", err)
+	}
+}
+
+func TestParseDatasetTableTextUncertainty(t *testing.T) {
+	// An uncertainty column for a text column is dropped with it.
+	table, err := ParseDatasetTable(strings.NewReader("t,Δt\nA,0.1\n"), "data.csv")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Columns) != 1 || table.Columns[0].Kind != models.ColumnText {
+		t.Fatalf("columns = %+v, want one text column", table.Columns)
+	}
+	if cell := table.Rows[0][table.Columns[0].ID]; cell.Text != "A" || cell.Uncertainty != nil {
+		t.Errorf("cell = %+v, want text A without uncertainty", cell)
+	}
+}
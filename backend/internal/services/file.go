@@ -31,7 +31,19 @@ func (fs *FileService) SaveFile(file *multipart.FileHeader) (string, string, err
         return "", "", fmt.Errorf("file size exceeds maximum allowed size")
     }
 
-    ext := strings.ToLower(filepath.Ext(file.Filename))
+    src, err := file.Open()
+    if err != nil {
+        return "", "", fmt.Errorf("failed to open file: %w", err)
+    }
+    defer src.Close()
+
+    return fs.SaveReader(file.Filename, src)
+}
+
+// SaveReader stores the contents of src under a fresh name, applying the same
+// extension checks as SaveFile. The original name is only used for its extension.
+func (fs *FileService) SaveReader(originalName string, src io.Reader) (string, string, error) {
+    ext := strings.ToLower(filepath.Ext(originalName))
     if !fs.isAllowedType(ext) {
         return "", "", fmt.Errorf("file type not allowed: %s", ext)
     }
@@ -43,21 +55,21 @@ func (fs *FileService) SaveFile(file *multipart.FileHeader) (string, string, err
         return "", "", fmt.Errorf("failed to create upload directory: %w", err)
     }
 
-    src, err := file.Open()
-    if err != nil {
-        return "", "", fmt.Errorf("failed to open file: %w", err)
-    }
-    defer src.Close()
-
     dst, err := os.Create(filepath)
     if err != nil {
         return "", "", fmt.Errorf("failed to create file: %w", err)
     }
     defer dst.Close()
 
-    if _, err := io.Copy(dst, src); err != nil {
+    written, err := io.Copy(dst, io.LimitReader(src, fs.MaxFileSize+1))
+    if err != nil {
+        os.Remove(filepath)
         return "", "", fmt.Errorf("failed to save file: %w", err)
     }
+    if written > fs.MaxFileSize {
+        os.Remove(filepath)
+        return "", "", fmt.Errorf("file size exceeds maximum allowed size")
+    }
 
     return filename, filepath, nil
 }
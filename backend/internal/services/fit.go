@@ -0,0 +1,664 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/expr"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+const (
+	maxFitIterations = 200
+	fitTolerance     = 1e-10
+)
+
+// FitParameterError reports a parameter in a fit request that the model
+// does not have.
+type FitParameterError struct {
+	Symbol  string
+	Message string
+}
+
+func (e *FitParameterError) Error() string {
+	return e.Symbol + ": " + e.Message
+}
+
+type fitParam struct {
+	symbol   string
+	unit     units.Unit
+	unitName string
+	initial  *float64
+}
+
+// fitModel is a model bound to a dataset. Values are in SI units
+// throughout; vars holds the constants the expression uses and is
+// overwritten with the argument and parameters on every evaluation.
+type fitModel struct {
+	model    string
+	latex    string
+	symbol   string
+	argument string
+	node     expr.Node
+	derivs   []expr.Node
+	params   []fitParam
+	vars     map[string]expr.Quantity
+	xDim     units.Dimension
+	linear   bool
+}
+
+type fitPoint struct {
+	row   models.DatasetRow
+	x, y  float64
+	sigma float64
+}
+
+// FitDataset fits the model of req to the dataset's x and y columns by
+// weighted least squares. Models that are linear in their parameters are
+// solved directly; the others with the Levenberg–Marquardt method. latex
+// is the formula of a formula model and scope resolves its constants.
+// Points are weighted by 1/σ² of their y values when every point has an
+// uncertainty; uncertainties of x are not taken into account.
+func FitDataset(req models.FitDatasetRequest, latex string, scope *FormulaScope, columns []models.DatasetColumn, rows []models.DatasetRow) (*models.FitDatasetResponse, error) {
+	xCol, err := fitColumn(columns, "x", req.X)
+	if err != nil {
+		return nil, err
+	}
+	yCol, err := fitColumn(columns, "y", req.Y)
+	if err != nil {
+		return nil, err
+	}
+	ux, err := units.Parse(xCol.Unit)
+	if err != nil {
+		return nil, err
+	}
+	uy, err := units.Parse(yCol.Unit)
+	if err != nil {
+		return nil, err
+	}
+
+	var m *fitModel
+	if req.Model == models.FitFormula {
+		m, err = formulaFitModel(req, latex, scope, xCol)
+	} else {
+		m, err = builtinFitModel(req, xCol, yCol, ux.Dim, uy.Dim)
+	}
+	if err != nil {
+		return nil, err
+	}
+	m.xDim = ux.Dim
+	for _, p := range m.params {
+		m.derivs = append(m.derivs, expr.Diff(m.node, p.symbol))
+	}
+
+	resp := &models.FitDatasetResponse{
+		Model:      req.Model,
+		Expression: m.latex,
+		Symbol:     m.symbol,
+		Argument:   m.argument,
+		Residuals:  []models.FitResidual{},
+		Skipped:    []models.DatasetRowError{},
+	}
+	var points []fitPoint
+	resp.Weighted = true
+	for _, row := range rows {
+		xCell, yCell := row.Cells[xCol.ID], row.Cells[yCol.ID]
+		var empty []string
+		if xCell.Value == nil {
+			empty = append(empty, xCol.Name)
+		}
+		if yCell.Value == nil {
+			empty = append(empty, yCol.Name)
+		}
+		if len(empty) > 0 {
+			resp.Skipped = append(resp.Skipped, models.DatasetRowError{
+				RowID: row.ID, Position: row.Position, Error: "no value in " + strings.Join(empty, ", "),
+			})
+			continue
+		}
+		pt := fitPoint{row: row, x: ux.ToSI(*xCell.Value), y: uy.ToSI(*yCell.Value), sigma: 1}
+		if sigma := CellUncertainty(yCol, yCell); sigma != nil && *sigma > 0 {
+			pt.sigma = *sigma * uy.Scale
+		} else {
+			resp.Weighted = false
+		}
+		points = append(points, pt)
+	}
+	if !resp.Weighted {
+		for i := range points {
+			points[i].sigma = 1
+		}
+	}
+	if len(points) <= len(m.params) {
+		return nil, fmt.Errorf("a fit of %d parameters needs at least %d points with both values, got %d",
+			len(m.params), len(m.params)+1, len(points))
+	}
+
+	p := m.start(points)
+	q, err := m.eval(m.node, points[0].x, p)
+	if err != nil {
+		return nil, err
+	}
+	if q.Dim != uy.Dim {
+		return nil, fmt.Errorf("the model gives %s but column %s holds %s", q.Dim, yCol.Name, uy.Dim)
+	}
+
+	if m.linear {
+		p, err = m.gaussNewton(points, p)
+		resp.Iterations = 1
+	} else {
+		p, resp.Iterations, err = m.levenbergMarquardt(points, p)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	a, _, err := m.normal(points, p)
+	if err != nil {
+		return nil, err
+	}
+	cov, ok := invert(a)
+	if !ok {
+		return nil, errors.New("the parameters cannot be determined from these points")
+	}
+	chi2, err := m.chiSquare(points, p)
+	if err != nil {
+		return nil, err
+	}
+	resp.ChiSquare = chi2
+	resp.NDF = len(points) - len(p)
+	resp.ReducedChiSquare = chi2 / float64(resp.NDF)
+	if !resp.Weighted {
+		// Without uncertainties the scatter of the points estimates them.
+		for i := range cov {
+			for j := range cov[i] {
+				cov[i][j] *= resp.ReducedChiSquare
+			}
+		}
+		resp.ChiSquare /= uy.Scale * uy.Scale
+		resp.ReducedChiSquare /= uy.Scale * uy.Scale
+	}
+
+	resp.Parameters = make([]models.FitParameter, len(p))
+	resp.Covariance = make([][]float64, len(p))
+	for i, param := range m.params {
+		sigma, _ := units.ConvertUncertainty(math.Sqrt(cov[i][i]), units.Unit{Scale: 1, Dim: param.unit.Dim}, param.unit)
+		resp.Parameters[i] = models.FitParameter{
+			Symbol:      param.symbol,
+			Value:       param.unit.FromSI(p[i]),
+			Uncertainty: sigma,
+			Unit:        param.unitName,
+			Dimension:   param.unit.Dim,
+		}
+		resp.Covariance[i] = make([]float64, len(p))
+		for j := range p {
+			resp.Covariance[i][j] = cov[i][j] / (param.unit.Scale * m.params[j].unit.Scale)
+		}
+	}
+
+	for _, pt := range points {
+		q, err := m.eval(m.node, pt.x, p)
+		if err != nil {
+			return nil, err
+		}
+		res := models.FitResidual{
+			RowID:    pt.row.ID,
+			Position: pt.row.Position,
+			X:        *pt.row.Cells[xCol.ID].Value,
+			Y:        *pt.row.Cells[yCol.ID].Value,
+			Fitted:   uy.FromSI(q.Value),
+		}
+		res.Residual = res.Y - res.Fitted
+		if resp.Weighted {
+			normalized := (pt.y - q.Value) / pt.sigma
+			res.Normalized = &normalized
+		}
+		resp.Residuals = append(resp.Residuals, res)
+	}
+	return resp, nil
+}
+
+func fitColumn(columns []models.DatasetColumn, axis, name string) (models.DatasetColumn, error) {
+	for _, col := range columns {
+		if col.Name != name {
+			continue
+		}
+		if col.Kind != models.ColumnNumber {
+			return col, &DatasetColumnError{Symbol: axis, Message: fmt.Sprintf("column %q does not hold numbers", name)}
+		}
+		return col, nil
+	}
+	return models.DatasetColumn{}, &DatasetColumnError{Symbol: axis, Message: fmt.Sprintf("no column %q", name)}
+}
+
+// builtinFitModel writes a built-in model in the columns' symbols where
+// they are single variables, and gives its parameters the dimensions that
+// make it consistent.
+func builtinFitModel(req models.FitDatasetRequest, xCol, yCol models.DatasetColumn, xDim, yDim units.Dimension) (*fitModel, error) {
+	var symbols []string
+	var dims []units.Dimension
+	switch req.Model {
+	case models.FitLinear:
+		symbols = []string{"a", "b"}
+		dims = []units.Dimension{yDim, yDim.Div(xDim)}
+	case models.FitPolynomial:
+		if req.Degree == 0 {
+			return nil, errors.New("a polynomial fit needs a degree")
+		}
+		for k := 0; k <= req.Degree; k++ {
+			symbols = append(symbols, "c_"+strconv.Itoa(k))
+			dims = append(dims, yDim.Div(xDim.Pow(k)))
+		}
+	case models.FitExponential:
+		symbols = []string{"A", "k"}
+		dims = []units.Dimension{yDim, units.Dimension{}.Div(xDim)}
+	}
+
+	taken := map[string]bool{}
+	for _, symbol := range symbols {
+		taken[symbol] = true
+	}
+	x := modelSymbol(xCol.Name, "x", taken)
+	taken[x] = true
+	y := modelSymbol(yCol.Name, "y", taken)
+
+	var latex string
+	switch req.Model {
+	case models.FitLinear:
+		latex = fmt.Sprintf("%s = a + b %s", y, x)
+	case models.FitPolynomial:
+		terms := []string{"c_0"}
+		for k := 1; k <= req.Degree; k++ {
+			term := fmt.Sprintf("c_%d %s", k, x)
+			if k > 1 {
+				term += fmt.Sprintf("^{%d}", k)
+			}
+			terms = append(terms, term)
+		}
+		latex = y + " = " + strings.Join(terms, " + ")
+	case models.FitExponential:
+		latex = fmt.Sprintf("%s = A \\exp(k %s)", y, x)
+	}
+	eq, err := expr.Parse(latex)
+	if err != nil {
+		return nil, err
+	}
+	_, node, err := eq.Solved()
+	if err != nil {
+		return nil, err
+	}
+
+	m := &fitModel{
+		model:    req.Model,
+		latex:    latex,
+		symbol:   y,
+		argument: x,
+		node:     node,
+		vars:     map[string]expr.Quantity{},
+		linear:   req.Model != models.FitExponential,
+	}
+	given, err := fitParameterRequests(req.Parameters, taken, "is not a parameter of the "+req.Model+" model")
+	if err != nil {
+		return nil, err
+	}
+	for i, symbol := range symbols {
+		g := given[symbol]
+		to, unitName, err := resultUnit(dims[i], g.Unit)
+		if err != nil {
+			return nil, err
+		}
+		m.params = append(m.params, fitParam{symbol: symbol, unit: to, unitName: unitName, initial: g.Initial})
+	}
+	return m, nil
+}
+
+// modelSymbol returns the variable a column name stands for, or fallback
+// when the name is not a single variable or is already taken.
+func modelSymbol(name, fallback string, taken map[string]bool) string {
+	if eq, err := expr.Parse(name); err == nil && eq.Left == nil {
+		if v, ok := eq.Right.(*expr.Var); ok && !taken[v.Name] {
+			return v.Name
+		}
+	}
+	if !taken[fallback] {
+		return fallback
+	}
+	return fallback + "_0"
+}
+
+// formulaFitModel binds a formula's argument, free parameters and
+// constants.
+func formulaFitModel(req models.FitDatasetRequest, latex string, scope *FormulaScope, xCol models.DatasetColumn) (*fitModel, error) {
+	eq, err := expr.Parse(latex)
+	if err != nil {
+		return nil, err
+	}
+	symbol, node, err := eq.Solved()
+	if err != nil {
+		return nil, err
+	}
+	argument := expr.Symbol(xCol.Name)
+	if req.XSymbol != "" {
+		argument = expr.Symbol(req.XSymbol)
+	}
+
+	variables := expr.Variables(node)
+	inFormula := make(map[string]bool, len(variables))
+	for _, name := range variables {
+		inFormula[name] = true
+	}
+	if !inFormula[argument] {
+		return nil, &DatasetColumnError{Symbol: argument, Message: "does not appear in the formula"}
+	}
+	if symbol == argument {
+		return nil, &DatasetColumnError{Symbol: argument, Message: "is the variable the formula defines"}
+	}
+	given, err := fitParameterRequests(req.Parameters, inFormula, "does not appear in the formula")
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := given[argument]; ok {
+		return nil, &FitParameterError{Symbol: argument, Message: "is the argument of the fit"}
+	}
+
+	m := &fitModel{
+		model:    req.Model,
+		latex:    latex,
+		symbol:   symbol,
+		argument: argument,
+		node:     node,
+		vars:     map[string]expr.Quantity{},
+	}
+	var missing []string
+	for _, name := range variables {
+		if name == argument {
+			continue
+		}
+		g, free := given[name]
+		if len(given) == 0 {
+			_, isConstant := scope.constant(name)
+			free = !isConstant
+		}
+		if !free {
+			c, ok := scope.constant(name)
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			m.vars[name] = expr.Quantity{Value: *c.NumericValue * *c.UnitScale, Dim: *c.Dimensions}
+			continue
+		}
+
+		unitName := strings.TrimSpace(g.Unit)
+		if declared, ok := scope.declared[name]; ok && unitName == "" && declared.ConstantID == nil {
+			unitName = declared.Unit
+		}
+		u, err := units.Parse(unitName)
+		if err != nil {
+			return nil, err
+		}
+		m.params = append(m.params, fitParam{symbol: name, unit: u, unitName: unitName, initial: g.Initial})
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: missing}
+	}
+	if len(m.params) == 0 {
+		return nil, errors.New("the formula has no free parameters to fit")
+	}
+	return m, nil
+}
+
+// fitParameterRequests indexes the requested parameters by symbol; each
+// must be one of known.
+func fitParameterRequests(reqs []models.FitParameterRequest, known map[string]bool, unknown string) (map[string]models.FitParameterRequest, error) {
+	given := make(map[string]models.FitParameterRequest, len(reqs))
+	for _, req := range reqs {
+		symbol := expr.Symbol(req.Symbol)
+		if !known[symbol] {
+			return nil, &FitParameterError{Symbol: symbol, Message: unknown}
+		}
+		if _, ok := given[symbol]; ok {
+			return nil, &FitParameterError{Symbol: symbol, Message: "is given twice"}
+		}
+		given[symbol] = req
+	}
+	return given, nil
+}
+
+// start returns the parameters the fit starts from: the given initial
+// values, or else zero for linear models, an estimate from ln y for the
+// exponential one, and one in the parameter's unit otherwise.
+func (m *fitModel) start(points []fitPoint) []float64 {
+	p := make([]float64, len(m.params))
+	if m.model == models.FitExponential {
+		p[0], p[1] = exponentialStart(points)
+	} else if !m.linear {
+		for i, param := range m.params {
+			p[i] = param.unit.ToSI(1)
+		}
+	}
+	for i, param := range m.params {
+		if param.initial != nil {
+			p[i] = param.unit.ToSI(*param.initial)
+		}
+	}
+	return p
+}
+
+// exponentialStart estimates A and k of y = A exp(k x) with a straight
+// line through ln|y|, or starts flat when y changes sign.
+func exponentialStart(points []fitPoint) (float64, float64) {
+	var sumY float64
+	sign := math.Copysign(1, points[0].y)
+	sameSign := true
+	for _, pt := range points {
+		sumY += pt.y
+		if pt.y == 0 || math.Copysign(1, pt.y) != sign {
+			sameSign = false
+		}
+	}
+	if !sameSign {
+		return sumY / float64(len(points)), 0
+	}
+	var sx, sl, sxx, sxl float64
+	n := float64(len(points))
+	for _, pt := range points {
+		l := math.Log(math.Abs(pt.y))
+		sx += pt.x
+		sl += l
+		sxx += pt.x * pt.x
+		sxl += pt.x * l
+	}
+	var k float64
+	if d := n*sxx - sx*sx; d != 0 {
+		k = (n*sxl - sx*sl) / d
+	}
+	return sign * math.Exp((sl-k*sx)/n), k
+}
+
+func (m *fitModel) eval(n expr.Node, x float64, p []float64) (expr.Quantity, error) {
+	m.vars[m.argument] = expr.Quantity{Value: x, Dim: m.xDim}
+	for i, param := range m.params {
+		m.vars[param.symbol] = expr.Quantity{Value: p[i], Dim: param.unit.Dim}
+	}
+	return expr.Eval(n, m.vars)
+}
+
+func (m *fitModel) chiSquare(points []fitPoint, p []float64) (float64, error) {
+	var chi2 float64
+	for _, pt := range points {
+		q, err := m.eval(m.node, pt.x, p)
+		if err != nil {
+			return 0, err
+		}
+		r := (pt.y - q.Value) / pt.sigma
+		chi2 += r * r
+	}
+	return chi2, nil
+}
+
+// normal returns JᵀJ and Jᵀr for the weighted residuals r and their
+// Jacobian J with respect to the parameters.
+func (m *fitModel) normal(points []fitPoint, p []float64) ([][]float64, []float64, error) {
+	a := make([][]float64, len(p))
+	for i := range a {
+		a[i] = make([]float64, len(p))
+	}
+	g := make([]float64, len(p))
+	row := make([]float64, len(p))
+	for _, pt := range points {
+		q, err := m.eval(m.node, pt.x, p)
+		if err != nil {
+			return nil, nil, err
+		}
+		r := (pt.y - q.Value) / pt.sigma
+		for j, d := range m.derivs {
+			dq, err := m.eval(d, pt.x, p)
+			if err != nil {
+				return nil, nil, fmt.Errorf("cannot differentiate by %s: %w", m.params[j].symbol, err)
+			}
+			row[j] = dq.Value / pt.sigma
+		}
+		for i := range row {
+			g[i] += row[i] * r
+			for j := range row {
+				a[i][j] += row[i] * row[j]
+			}
+		}
+	}
+	return a, g, nil
+}
+
+// gaussNewton takes one Gauss–Newton step, which solves a model that is
+// linear in its parameters exactly.
+func (m *fitModel) gaussNewton(points []fitPoint, p []float64) ([]float64, error) {
+	a, g, err := m.normal(points, p)
+	if err != nil {
+		return nil, err
+	}
+	inv, ok := invert(a)
+	if !ok {
+		return nil, errors.New("the parameters cannot be determined from these points")
+	}
+	return addStep(p, inv, g), nil
+}
+
+// levenbergMarquardt minimizes χ² from p, damping each Gauss–Newton step
+// by λ·diag(JᵀJ) and adapting λ to whether the step lowered χ². It returns
+// the parameters and the number of iterations taken.
+func (m *fitModel) levenbergMarquardt(points []fitPoint, p []float64) ([]float64, int, error) {
+	chi2, err := m.chiSquare(points, p)
+	if err != nil {
+		return nil, 0, fmt.Errorf("the model cannot be evaluated at the initial values: %w", err)
+	}
+	lambda := 1e-3
+	for iter := 1; iter <= maxFitIterations; iter++ {
+		a, g, err := m.normal(points, p)
+		if err != nil {
+			return nil, iter, err
+		}
+		improved := false
+		for ; lambda < 1e12; lambda *= 10 {
+			damped := make([][]float64, len(a))
+			for i := range a {
+				damped[i] = append([]float64(nil), a[i]...)
+				damped[i][i] *= 1 + lambda
+			}
+			inv, ok := invert(damped)
+			if !ok {
+				continue
+			}
+			next := addStep(p, inv, g)
+			c, err := m.chiSquare(points, next)
+			if err != nil || c > chi2 {
+				continue
+			}
+			converged := chi2-c <= fitTolerance*chi2
+			p, chi2, improved = next, c, true
+			lambda = math.Max(lambda/10, 1e-12)
+			if converged {
+				return p, iter, nil
+			}
+			break
+		}
+		// No step lowers χ² any more: p is at the minimum.
+		if !improved {
+			return p, iter, nil
+		}
+	}
+	return nil, maxFitIterations, errors.New("the fit did not converge, try other initial values")
+}
+
+// addStep returns p + inv·g.
+func addStep(p []float64, inv [][]float64, g []float64) []float64 {
+	next := append([]float64(nil), p...)
+	for i := range inv {
+		for j := range g {
+			next[i] += inv[i][j] * g[j]
+		}
+	}
+	return next
+}
+
+// invert inverts a symmetric positive definite matrix by Gauss–Jordan
+// elimination. The matrix is first scaled to a unit diagonal, as
+// parameters in SI units can differ by many orders of magnitude. It
+// reports false when the matrix is singular.
+func invert(a [][]float64) ([][]float64, bool) {
+	n := len(a)
+	d := make([]float64, n)
+	for i := range a {
+		if !(a[i][i] > 0) {
+			return nil, false
+		}
+		d[i] = math.Sqrt(a[i][i])
+	}
+	m := make([][]float64, n)
+	inv := make([][]float64, n)
+	for i := range a {
+		m[i] = make([]float64, n)
+		inv[i] = make([]float64, n)
+		for j := range a[i] {
+			m[i][j] = a[i][j] / (d[i] * d[j])
+		}
+		inv[i][i] = 1
+	}
+	for col := 0; col < n; col++ {
+		pivot := col
+		for i := col + 1; i < n; i++ {
+			if math.Abs(m[i][col]) > math.Abs(m[pivot][col]) {
+				pivot = i
+			}
+		}
+		if math.Abs(m[pivot][col]) < 1e-13 {
+			return nil, false
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+		scale := m[col][col]
+		for j := 0; j < n; j++ {
+			m[col][j] /= scale
+			inv[col][j] /= scale
+		}
+		for i := 0; i < n; i++ {
+			if i == col || m[i][col] == 0 {
+				continue
+			}
+			f := m[i][col]
+			for j := 0; j < n; j++ {
+				m[i][j] -= f * m[col][j]
+				inv[i][j] -= f * inv[col][j]
+			}
+		}
+	}
+	for i := range inv {
+		for j := range inv[i] {
+			inv[i][j] /= d[i] * d[j]
+		}
+	}
+	return inv, true
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// fitDataset builds two number columns and a row for each x, with the y
+// uncertainties in sigmas if given.
+func fitDataset(x, y models.DatasetColumn, xs, ys, sigmas []float64) ([]models.DatasetColumn, []models.DatasetRow) {
+	x.ID, x.Kind = uuid.New(), models.ColumnNumber
+	y.ID, y.Kind = uuid.New(), models.ColumnNumber
+	rows := make([]models.DatasetRow, len(xs))
+	for i := range xs {
+		yCell := models.DatasetCell{Value: &ys[i]}
+		if sigmas != nil {
+			yCell.Uncertainty = &sigmas[i]
+		}
+		rows[i] = models.DatasetRow{
+			ID:       uuid.New(),
+			Position: i,
+			Cells:    models.DatasetCells{x.ID: {Value: &xs[i]}, y.ID: yCell},
+		}
+	}
+	return []models.DatasetColumn{x, y}, rows
+}
+
+func closeTo(got, want, tolerance float64) bool {
+	return math.Abs(got-want) <= tolerance*math.Max(1, math.Abs(want))
+}
+
+func TestFitDatasetWeightedLinear(t *testing.T) {
+	ts := []float64{1, 2, 3, 4, 5}
+	cm := []float64{2.1, 3.9, 6.2, 7.8, 10.1}
+	sigmas := []float64{0.1, 0.2, 0.1, 0.3, 0.2}
+	columns, rows := fitDataset(
+		models.DatasetColumn{Name: "t", Unit: "s"},
+		models.DatasetColumn{Name: "s", Unit: "cm"},
+		ts, cm, sigmas)
+
+	// The closed-form weighted least squares solution, in metres.
+	var sw, sx, sy, sxx, sxy float64
+	for i := range ts {
+		w := 1 / (sigmas[i] / 100 * sigmas[i] / 100)
+		y := cm[i] / 100
+		sw += w
+		sx += w * ts[i]
+		sy += w * y
+		sxx += w * ts[i] * ts[i]
+		sxy += w * ts[i] * y
+	}
+	delta := sw*sxx - sx*sx
+	a := (sxx*sy - sx*sxy) / delta
+	b := (sw*sxy - sx*sy) / delta
+	sigmaA, sigmaB := math.Sqrt(sxx/delta), math.Sqrt(sw/delta)
+	var chi2 float64
+	for i := range ts {
+		r := (cm[i]/100 - a - b*ts[i]) / (sigmas[i] / 100)
+		chi2 += r * r
+	}
+
+	req := models.FitDatasetRequest{
+		X: "t", Y: "s", Model: models.FitLinear,
+		Parameters: []models.FitParameterRequest{{Symbol: "b", Unit: "cm/s"}},
+	}
+	resp, err := FitDataset(req, "", NewFormulaScope(nil, nil, nil), columns, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !resp.Weighted || resp.NDF != 3 || len(resp.Parameters) != 2 {
+		t.Fatalf("weighted = %v, ndf = %d, %d parameters", resp.Weighted, resp.NDF, len(resp.Parameters))
+	}
+
+	pa, pb := resp.Parameters[0], resp.Parameters[1]
+	if pa.Unit != "m" || !closeTo(pa.Value, a, 1e-9) || !closeTo(pa.Uncertainty, sigmaA, 1e-9) {
+		t.Errorf("a = %g ± %g %s, want %g ± %g m", pa.Value, pa.Uncertainty, pa.Unit, a, sigmaA)
+	}
+	if pb.Unit != "cm/s" || !closeTo(pb.Value, 100*b, 1e-9) || !closeTo(pb.Uncertainty, 100*sigmaB, 1e-9) {
+		t.Errorf("b = %g ± %g %s, want %g ± %g cm/s", pb.Value, pb.Uncertainty, pb.Unit, 100*b, 100*sigmaB)
+	}
+	if !closeTo(resp.ChiSquare, chi2, 1e-9) || !closeTo(resp.ReducedChiSquare, chi2/3, 1e-9) {
+		t.Errorf("χ² = %g, reduced %g, want %g", resp.ChiSquare, resp.ReducedChiSquare, chi2)
+	}
+
+	// Residuals are in the column's unit and normalized by each point's σ.
+	for i, res := range resp.Residuals {
+		fitted := 100 * (a + b*ts[i])
+		if !closeTo(res.Fitted, fitted, 1e-9) || res.Normalized == nil ||
+			!closeTo(*res.Normalized, (cm[i]-fitted)/sigmas[i], 1e-6) {
+			t.Errorf("residual %d = %+v, want fitted %g", i, res, fitted)
+		}
+	}
+}
+
+func TestFitDatasetExponential(t *testing.T) {
+	var ts, counts []float64
+	for i := 0; i < 8; i++ {
+		x := float64(i) / 2
+		ts = append(ts, x)
+		counts = append(counts, 300*math.Exp(-0.4*x))
+	}
+	columns, rows := fitDataset(
+		models.DatasetColumn{Name: "t", Unit: "s"},
+		models.DatasetColumn{Name: "N", Unit: ""},
+		ts, counts, nil)
+
+	req := models.FitDatasetRequest{X: "t", Y: "N", Model: models.FitExponential}
+	resp, err := FitDataset(req, "", NewFormulaScope(nil, nil, nil), columns, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Weighted || resp.Expression != `N = A \exp(k t)` {
+		t.Errorf("weighted = %v, expression %q", resp.Weighted, resp.Expression)
+	}
+	if got := resp.Parameters[0].Value; !closeTo(got, 300, 1e-6) {
+		t.Errorf("A = %g, want 300", got)
+	}
+	if got := resp.Parameters[1]; !closeTo(got.Value, -0.4, 1e-6) || got.Unit != "Hz" {
+		t.Errorf("k = %g %s, want -0.4 Hz", got.Value, got.Unit)
+	}
+	if resp.ChiSquare > 1e-12 {
+		t.Errorf("χ² = %g for exact points", resp.ChiSquare)
+	}
+}
+
+func TestFitDatasetFormula(t *testing.T) {
+	var ts, heights []float64
+	for i := 0; i < 6; i++ {
+		x := 0.1 * float64(i)
+		ts = append(ts, x)
+		heights = append(heights, 100*(2+0.5*9.81*x*x))
+	}
+	columns, rows := fitDataset(
+		models.DatasetColumn{Name: "t", Unit: "s"},
+		models.DatasetColumn{Name: "h", Unit: "cm"},
+		ts, heights, nil)
+
+	req := models.FitDatasetRequest{
+		X: "t", Y: "h", Model: models.FitFormula,
+		Parameters: []models.FitParameterRequest{
+			{Symbol: "h_0", Unit: "m"},
+			{Symbol: "g", Unit: "m/s^2"},
+		},
+	}
+	resp, err := FitDataset(req, `h = h_0 + \frac{1}{2} g t^2`, NewFormulaScope(nil, nil, nil), columns, rows)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.Symbol != "h" || resp.Argument != "t" || len(resp.Parameters) != 2 {
+		t.Fatalf("fit of %s(%s) with %d parameters", resp.Symbol, resp.Argument, len(resp.Parameters))
+	}
+	want := map[string]float64{"h_0": 2, "g": 9.81}
+	for _, p := range resp.Parameters {
+		if !closeTo(p.Value, want[p.Symbol], 1e-6) {
+			t.Errorf("%s = %g %s, want %g", p.Symbol, p.Value, p.Unit, want[p.Symbol])
+		}
+	}
+}
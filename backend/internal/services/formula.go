@@ -0,0 +1,317 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/expr"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+// MissingVariablesError reports formula variables that have neither a
+// given value nor a constant with that symbol.
+type MissingVariablesError struct {
+	Names []string
+}
+
+func (e *MissingVariablesError) Error() string {
+	return "no value for " + strings.Join(e.Names, ", ")
+}
+
+// FormulaScope resolves a formula's variables: a declared variable's
+// linked constant or unit comes first, then the constant with its symbol.
+type FormulaScope struct {
+	declared  map[string]models.FormulaVariable
+	linked    map[uuid.UUID]models.Constant
+	bySymbol  map[string]models.Constant
+	variables []models.FormulaVariable
+}
+
+// NewFormulaScope builds a scope from the formula's declared variables, the
+// constants they link to, and the constants visible to the user, which
+// should hold one constant per symbol as GetEffectiveConstants returns
+// them. Constants without a parsed value are ignored.
+func NewFormulaScope(variables []models.FormulaVariable, linked, constants []models.Constant) *FormulaScope {
+	scope := &FormulaScope{
+		declared:  make(map[string]models.FormulaVariable, len(variables)),
+		linked:    make(map[uuid.UUID]models.Constant, len(linked)),
+		bySymbol:  make(map[string]models.Constant, len(constants)),
+		variables: variables,
+	}
+	for _, v := range variables {
+		scope.declared[expr.Symbol(v.Symbol)] = v
+	}
+	for _, c := range linked {
+		if hasParsedValue(c) {
+			scope.linked[c.ID] = c
+		}
+	}
+	for _, c := range constants {
+		if hasParsedValue(c) {
+			scope.bySymbol[expr.Symbol(c.Symbol)] = c
+		}
+	}
+	return scope
+}
+
+func hasParsedValue(c models.Constant) bool {
+	return c.NumericValue != nil && c.UnitScale != nil && c.Dimensions != nil
+}
+
+// constant returns the constant a variable stands for: the one it is
+// linked to, or else the one with its symbol unless it declares a unit.
+func (scope *FormulaScope) constant(name string) (models.Constant, bool) {
+	if v, ok := scope.declared[name]; ok {
+		if v.ConstantID != nil {
+			c, ok := scope.linked[*v.ConstantID]
+			return c, ok
+		}
+		if v.Unit != "" {
+			return models.Constant{}, false
+		}
+	}
+	c, ok := scope.bySymbol[name]
+	return c, ok
+}
+
+// dimension returns the dimension of a variable, if it is known.
+func (scope *FormulaScope) dimension(name string) (units.Dimension, bool) {
+	if c, ok := scope.constant(name); ok {
+		return *c.Dimensions, true
+	}
+	if v, ok := scope.declared[name]; ok && v.Unit != "" && v.ConstantID == nil {
+		if u, err := units.Parse(v.Unit); err == nil {
+			return u.Dim, true
+		}
+	}
+	return units.Dimension{}, false
+}
+
+// CheckFormula checks that the formula's sides and terms have matching
+// dimensions. LaTeX outside the supported subset cannot be checked and
+// only produces a warning, as do variables of unknown dimension.
+func CheckFormula(latex string, scope *FormulaScope) *models.FormulaCheck {
+	check := &models.FormulaCheck{Issues: []models.FormulaIssue{}}
+	warn := func(term, format string, args ...interface{}) {
+		check.Issues = append(check.Issues, models.FormulaIssue{
+			Severity: models.IssueWarning,
+			Term:     term,
+			Message:  fmt.Sprintf(format, args...),
+		})
+	}
+
+	eq, err := expr.Parse(latex)
+	if err != nil {
+		warn("", "formula cannot be checked: %v", err)
+		return check
+	}
+
+	names := expr.Variables(eq.Right)
+	if eq.Left != nil {
+		names = mergeNames(names, expr.Variables(eq.Left))
+	}
+	used := make(map[string]bool, len(names))
+	dims := make(map[string]units.Dimension, len(names))
+	for _, name := range names {
+		used[name] = true
+		if dim, ok := scope.dimension(name); ok {
+			dims[name] = dim
+		} else {
+			warn(name, "no unit declared and no constant with this symbol")
+		}
+	}
+	for _, v := range scope.variables {
+		if !used[expr.Symbol(v.Symbol)] {
+			warn(v.Symbol, "declared variable does not appear in the formula")
+		}
+	}
+
+	dim, issues := expr.Check(eq, dims)
+	for _, issue := range issues {
+		check.Issues = append(check.Issues, models.FormulaIssue{
+			Severity: models.IssueError,
+			Term:     issue.Term,
+			Message:  issue.Message,
+		})
+	}
+	check.Dimension = dim
+	check.Consistent = len(issues) == 0 && len(dims) == len(names)
+	return check
+}
+
+func mergeNames(a, b []string) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var out []string
+	for _, name := range append(a, b...) {
+		if !seen[name] {
+			seen[name] = true
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out
+}
+
+// boundFormula is a formula ready to evaluate: the expression for its
+// result and the SI values and uncertainties of its variables.
+type boundFormula struct {
+	symbol        string
+	eq            *expr.Equation
+	node          expr.Node
+	vars          map[string]expr.Quantity
+	sigmas        map[string]float64
+	substitutions []models.Substitution
+	unused        []string
+}
+
+// bind parses the formula and resolves its variables. Variables without a
+// given value are substituted from the scope's constants, and given values
+// without a unit are taken in the variable's declared unit.
+func (scope *FormulaScope) bind(latex string, variables map[string]models.VariableValue) (*boundFormula, error) {
+	eq, err := expr.Parse(latex)
+	if err != nil {
+		return nil, err
+	}
+	symbol, node, err := eq.Solved()
+	if err != nil {
+		return nil, err
+	}
+
+	given := make(map[string]models.Substitution, len(variables))
+	givenSigmas := make(map[string]float64, len(variables))
+	for key, v := range variables {
+		name := expr.Symbol(key)
+		unitName := v.Unit
+		if declared, ok := scope.declared[name]; ok && unitName == "" {
+			unitName = declared.Unit
+		}
+		u, err := units.Parse(unitName)
+		if err != nil {
+			return nil, err
+		}
+		given[name] = models.Substitution{
+			Symbol:      name,
+			Source:      models.SubstitutionVariable,
+			Value:       *v.Value,
+			Uncertainty: v.Uncertainty,
+			Unit:        unitName,
+			SIValue:     u.ToSI(*v.Value),
+			Dimension:   u.Dim,
+		}
+		if v.Uncertainty != nil {
+			givenSigmas[name] = *v.Uncertainty * u.Scale
+		}
+	}
+
+	b := &boundFormula{
+		symbol:        symbol,
+		eq:            eq,
+		node:          node,
+		vars:          map[string]expr.Quantity{},
+		sigmas:        map[string]float64{},
+		substitutions: []models.Substitution{},
+	}
+	var missing []string
+	used := map[string]bool{}
+	for _, name := range expr.Variables(node) {
+		used[name] = true
+		sub, ok := given[name]
+		if ok {
+			if sigma, ok := givenSigmas[name]; ok {
+				b.sigmas[name] = sigma
+			}
+		} else {
+			c, found := scope.constant(name)
+			if !found {
+				missing = append(missing, name)
+				continue
+			}
+			id := c.ID
+			sub = models.Substitution{
+				Symbol:      name,
+				Source:      models.SubstitutionConstant,
+				ConstantID:  &id,
+				Name:        c.Name,
+				Scope:       c.Scope,
+				Value:       *c.NumericValue,
+				Uncertainty: c.Uncertainty,
+				Unit:        c.Unit,
+				SIValue:     *c.NumericValue * *c.UnitScale,
+				Dimension:   *c.Dimensions,
+			}
+			if c.Uncertainty != nil && !c.Exact {
+				b.sigmas[name] = *c.Uncertainty * *c.UnitScale
+			}
+		}
+		b.vars[name] = expr.Quantity{Value: sub.SIValue, Dim: sub.Dimension}
+		b.substitutions = append(b.substitutions, sub)
+	}
+	if len(missing) > 0 {
+		return nil, &MissingVariablesError{Names: missing}
+	}
+	for name := range given {
+		if !used[name] {
+			b.unused = append(b.unused, name)
+		}
+	}
+	sort.Strings(b.unused)
+	return b, nil
+}
+
+// resultUnit returns the unit to report a result of dimension dim in and
+// its name: unit if one was asked for, or else the SI unit.
+func resultUnit(dim units.Dimension, unit string) (units.Unit, string, error) {
+	if unit == "" {
+		name := dim.Name()
+		if name == "" {
+			name = dim.String()
+		}
+		return units.Unit{Scale: 1, Dim: dim}, name, nil
+	}
+	to, err := units.Parse(unit)
+	if err != nil {
+		return units.Unit{}, "", err
+	}
+	if to.Dim != dim {
+		return units.Unit{}, "", &units.IncompatibleError{From: dim, To: to.Dim}
+	}
+	return to, strings.TrimSpace(unit), nil
+}
+
+// EvaluateFormula evaluates the formula's LaTeX with the given values and
+// the scope's constants. The result is in unit, or in SI units if unit is
+// empty.
+func EvaluateFormula(latex string, variables map[string]models.VariableValue, scope *FormulaScope, unit string) (*models.EvaluateFormulaResponse, error) {
+	b, err := scope.bind(latex, variables)
+	if err != nil {
+		return nil, err
+	}
+	q, err := expr.Eval(b.node, b.vars)
+	if err != nil {
+		return nil, err
+	}
+	to, unitName, err := resultUnit(q.Dim, unit)
+	if err != nil {
+		return nil, err
+	}
+	value, err := units.Convert(q.Value, units.Unit{Scale: 1, Dim: q.Dim}, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.EvaluateFormulaResponse{
+		Expression: b.eq.String(),
+		Result: models.EvaluationResult{
+			Symbol:    b.symbol,
+			Value:     value,
+			Unit:      unitName,
+			SIValue:   q.Value,
+			Dimension: q.Dim,
+		},
+		Substitutions: b.substitutions,
+		Unused:        b.unused,
+	}, nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// ParseMentions finds "@name" and "@email" mentions of the given users in
+// content. Names may contain spaces, so the longest matching name or email
+// wins; a mention that matches several users equally well is ambiguous and
+// skipped. Matching ignores case and needs the handle to end at a word
+// boundary.
+//
+// Start and Length are counted in UTF-16 code units, the way JavaScript
+// indexes strings, so the client can slice the content directly.
+func ParseMentions(content string, users []models.User) []models.CommentMention {
+	runes := []rune(content)
+	mentions := []models.CommentMention{}
+
+	// offsets[i] is the UTF-16 offset of runes[i]; characters outside the
+	// Basic Multilingual Plane take a surrogate pair.
+	offsets := make([]int, len(runes)+1)
+	for i, r := range runes {
+		width := 1
+		if r > 0xFFFF {
+			width = 2
+		}
+		offsets[i+1] = offsets[i] + width
+	}
+
+	for i := 0; i < len(runes); i++ {
+		if runes[i] != '@' || (i > 0 && isWordRune(runes[i-1])) {
+			continue
+		}
+		rest := runes[i+1:]
+
+		var best *models.User
+		bestLen, ambiguous := 0, false
+		for u := range users {
+			for _, handle := range []string{users[u].Email, users[u].Name} {
+				h := []rune(handle)
+				if len(h) == 0 || len(h) > len(rest) || len(h) < bestLen {
+					continue
+				}
+				if !strings.EqualFold(string(rest[:len(h)]), handle) {
+					continue
+				}
+				if len(h) < len(rest) && isWordRune(rest[len(h)]) {
+					continue
+				}
+				if len(h) == bestLen && best != nil && best.ID != users[u].ID {
+					ambiguous = true
+					continue
+				}
+				if len(h) > bestLen {
+					ambiguous = false
+				}
+				best, bestLen = &users[u], len(h)
+			}
+		}
+		if best == nil || ambiguous {
+			continue
+		}
+
+		mentions = append(mentions, models.CommentMention{
+			UserID:   best.ID,
+			UserName: best.Name,
+			Start:    offsets[i],
+			Length:   offsets[i+1+bestLen] - offsets[i],
+		})
+		i += bestLen
+	}
+	return mentions
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_'
+}
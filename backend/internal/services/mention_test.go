@@ -0,0 +1,54 @@
+package services
+
+import (
+	"testing"
+	"unicode/utf16"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+func TestParseMentionsUTF16Offsets(t *testing.T) {
+	anna := models.User{ID: uuid.New(), Name: "Anna Petrova", Email: "anna@example.com"}
+	emoji := models.User{ID: uuid.New(), Name: "Борис 🚀", Email: "boris@example.com"}
+	users := []models.User{anna, emoji}
+
+	tests := []struct {
+		name    string
+		content string
+		want    []models.CommentMention
+	}{
+		{"ascii", "hi @Anna Petrova!", []models.CommentMention{
+			{UserID: anna.ID, UserName: anna.Name, Start: 3, Length: 13},
+		}},
+		{"cyrillic prefix", "привет @anna@example.com", []models.CommentMention{
+			{UserID: anna.ID, UserName: anna.Name, Start: 7, Length: 17},
+		}},
+		{"emoji before mention", "🎉🎉 @Anna Petrova", []models.CommentMention{
+			{UserID: anna.ID, UserName: anna.Name, Start: 5, Length: 13},
+		}},
+		{"emoji inside handle", "@Борис 🚀 and @anna@example.com", []models.CommentMention{
+			{UserID: emoji.ID, UserName: emoji.Name, Start: 0, Length: 9},
+			{UserID: anna.ID, UserName: anna.Name, Start: 14, Length: 17},
+		}},
+		{"word before at", "mail me:x@Anna Petrova", []models.CommentMention{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := ParseMentions(tt.content, users)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d mentions %+v, want %d", len(got), got, len(tt.want))
+			}
+			units := utf16.Encode([]rune(tt.content))
+			for i, m := range got {
+				if m != tt.want[i] {
+					t.Errorf("mention %d = %+v, want %+v", i, m, tt.want[i])
+				}
+				if span := string(utf16.Decode(units[m.Start : m.Start+m.Length])); span[0] != '@' {
+					t.Errorf("mention %d spans %q, want it to start with @", i, span)
+				}
+			}
+		})
+	}
+}
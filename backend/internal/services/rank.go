@@ -0,0 +1,90 @@
+package services
+
+import (
+	"errors"
+	"strings"
+)
+
+// Task ranks are base-36 fractions: "i" reads as 0.i, so keys sort the same
+// way as strings (in the "C" collation) and there is always room for another
+// key between two different ones. Generated keys never end in '0', which keeps
+// string order and numeric order identical.
+const rankDigits = "0123456789abcdefghijklmnopqrstuvwxyz"
+
+// MaxRankLength is the key length beyond which a column should be renumbered.
+const MaxRankLength = 32
+
+var ErrInvalidRankBounds = errors.New("invalid rank bounds")
+
+func rankDigit(c byte) int {
+	return strings.IndexByte(rankDigits, c)
+}
+
+// RankBetween returns a key that sorts strictly between lower and upper. An
+// empty lower or upper means the start or end of the column.
+func RankBetween(lower, upper string) (string, error) {
+	if upper != "" && lower >= upper {
+		return "", ErrInvalidRankBounds
+	}
+	for i := 0; i < len(lower); i++ {
+		if rankDigit(lower[i]) < 0 {
+			return "", ErrInvalidRankBounds
+		}
+	}
+	for i := 0; i < len(upper); i++ {
+		if rankDigit(upper[i]) < 0 {
+			return "", ErrInvalidRankBounds
+		}
+	}
+
+	var key []byte
+	upperOpen := upper == ""
+	for i := 0; i <= MaxRankLength; i++ {
+		lo := 0
+		if i < len(lower) {
+			lo = rankDigit(lower[i])
+		}
+		hi := len(rankDigits)
+		if !upperOpen {
+			hi = 0
+			if i < len(upper) {
+				hi = rankDigit(upper[i])
+			}
+		}
+
+		switch {
+		case hi-lo >= 2:
+			return string(append(key, rankDigits[(lo+hi)/2])), nil
+		case hi-lo == 1:
+			// Taking lo puts the key below upper, so deeper digits are free.
+			key = append(key, rankDigits[lo])
+			upperOpen = true
+		case hi == lo:
+			key = append(key, rankDigits[lo])
+		default:
+			return "", ErrInvalidRankBounds
+		}
+	}
+	return "", ErrInvalidRankBounds
+}
+
+// EvenRanks returns n evenly spaced keys of equal length, used to renumber a
+// column when its keys grow too long.
+func EvenRanks(n int) []string {
+	width := 1
+	for capacity := len(rankDigits); capacity <= n; capacity *= len(rankDigits) {
+		width++
+	}
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		digits := make([]byte, width)
+		v := i + 1
+		for j := width - 1; j >= 0; j-- {
+			digits[j] = rankDigits[v%len(rankDigits)]
+			v /= len(rankDigits)
+		}
+		ranks[i] = string(digits) + "i"
+	}
+	return ranks
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// DependencyCreatesCycle reports whether adding the link blocker -> blocked
+// would close a cycle, that is whether blocker is already reachable from
+// blocked.
+func DependencyCreatesCycle(edges []models.TaskDependency, blocker, blocked uuid.UUID) bool {
+	if blocker == blocked {
+		return true
+	}
+
+	next := make(map[uuid.UUID][]uuid.UUID)
+	for _, e := range edges {
+		next[e.BlockerID] = append(next[e.BlockerID], e.BlockedID)
+	}
+
+	visited := map[uuid.UUID]bool{blocked: true}
+	stack := []uuid.UUID{blocked}
+	for len(stack) > 0 {
+		id := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		for _, n := range next[id] {
+			if n == blocker {
+				return true
+			}
+			if !visited[n] {
+				visited[n] = true
+				stack = append(stack, n)
+			}
+		}
+	}
+	return false
+}
+
+// AnalyzeTaskGraph computes the earliest finish of every open task and the
+// critical path: the chain of open tasks that ends with the latest earliest
+// finish. A task cannot finish before the tasks blocking it, so its earliest
+// finish is the latest due date among itself and its open blockers. Done
+// tasks no longer hold anything up.
+func AnalyzeTaskGraph(graph *models.TaskGraph) {
+	index := make(map[uuid.UUID]int, len(graph.Nodes))
+	for i, n := range graph.Nodes {
+		index[n.ID] = i
+	}
+
+	blockers := make([][]int, len(graph.Nodes))
+	dependents := make([][]int, len(graph.Nodes))
+	inDegree := make([]int, len(graph.Nodes))
+	for _, e := range graph.Edges {
+		from, okFrom := index[e.BlockerID]
+		to, okTo := index[e.BlockedID]
+		if !okFrom || !okTo {
+			continue
+		}
+		blockers[to] = append(blockers[to], from)
+		dependents[from] = append(dependents[from], to)
+		inDegree[to]++
+	}
+
+	order := make([]int, 0, len(graph.Nodes))
+	for i := range graph.Nodes {
+		if inDegree[i] == 0 {
+			order = append(order, i)
+		}
+	}
+	for head := 0; head < len(order); head++ {
+		for _, d := range dependents[order[head]] {
+			inDegree[d]--
+			if inDegree[d] == 0 {
+				order = append(order, d)
+			}
+		}
+	}
+
+	finish := make([]*time.Time, len(graph.Nodes))
+	length := make([]int, len(graph.Nodes))
+	prev := make([]int, len(graph.Nodes))
+	for _, i := range order {
+		node := &graph.Nodes[i]
+		prev[i] = -1
+		if node.Done {
+			continue
+		}
+
+		finish[i] = node.DueDate
+		length[i] = 1
+		for _, b := range blockers[i] {
+			if finish[b] == nil {
+				continue
+			}
+			if prev[i] < 0 || later(finish[b], length[b], finish[prev[i]], length[prev[i]]) {
+				prev[i] = b
+			}
+		}
+		if prev[i] >= 0 {
+			length[i] = length[prev[i]] + 1
+			if finish[i] == nil || finish[prev[i]].After(*finish[i]) {
+				finish[i] = finish[prev[i]]
+			}
+		}
+
+		node.EarliestFinish = finish[i]
+		node.Late = node.DueDate != nil && finish[i] != nil && finish[i].After(*node.DueDate)
+	}
+
+	end := -1
+	for _, i := range order {
+		if finish[i] == nil {
+			continue
+		}
+		if end < 0 || later(finish[i], length[i], finish[end], length[end]) {
+			end = i
+		}
+	}
+
+	graph.CriticalPath = []uuid.UUID{}
+	if end < 0 {
+		return
+	}
+	graph.FinishDate = finish[end]
+	for i := end; i >= 0; i = prev[i] {
+		graph.CriticalPath = append(graph.CriticalPath, graph.Nodes[i].ID)
+	}
+	for l, r := 0, len(graph.CriticalPath)-1; l < r; l, r = l+1, r-1 {
+		graph.CriticalPath[l], graph.CriticalPath[r] = graph.CriticalPath[r], graph.CriticalPath[l]
+	}
+}
+
+// later orders path ends by finish date, preferring the longer chain on ties.
+func later(a *time.Time, aLen int, b *time.Time, bLen int) bool {
+	if a.Equal(*b) {
+		return aLen > bLen
+	}
+	return a.After(*b)
+}
@@ -0,0 +1,247 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/expr"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/units"
+)
+
+const defaultMonteCarloSamples = 10000
+
+// PropagateUncertainty evaluates the formula like EvaluateFormula and
+// propagates the standard uncertainties of the given values and of the
+// substituted constants that are not exact.
+//
+// Linear propagation adds the contributions |∂f/∂x|·σx in quadrature, with
+// the derivatives taken symbolically. The Monte Carlo method evaluates the
+// formula for normally distributed inputs and reports the spread of the
+// results; each variable's contribution is the spread when only it varies.
+// Inputs are treated as uncorrelated either way.
+func PropagateUncertainty(latex string, req models.PropagateUncertaintyRequest, scope *FormulaScope) (*models.PropagateUncertaintyResponse, error) {
+	b, err := scope.bind(latex, req.Variables)
+	if err != nil {
+		return nil, err
+	}
+	q, err := expr.Eval(b.node, b.vars)
+	if err != nil {
+		return nil, err
+	}
+	to, unitName, err := resultUnit(q.Dim, req.Unit)
+	if err != nil {
+		return nil, err
+	}
+	si := units.Unit{Scale: 1, Dim: q.Dim}
+	value, err := units.Convert(q.Value, si, to)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &models.PropagateUncertaintyResponse{
+		Expression: b.eq.String(),
+		Method:     req.Method,
+		Result: models.UncertaintyResult{
+			EvaluationResult: models.EvaluationResult{
+				Symbol:    b.symbol,
+				Value:     value,
+				Unit:      unitName,
+				SIValue:   q.Value,
+				Dimension: q.Dim,
+			},
+		},
+		Substitutions: b.substitutions,
+		Unused:        b.unused,
+	}
+	if resp.Method == "" {
+		resp.Method = models.PropagationLinear
+	}
+
+	// Only variables with an uncertainty take part, in a stable order.
+	var names []string
+	for name, sigma := range b.sigmas {
+		if sigma > 0 {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	var sigma float64
+	if resp.Method == models.PropagationMonteCarlo {
+		sigma, err = monteCarlo(b, names, req, to, resp)
+	} else {
+		sigma, err = linearPropagation(b, names, resp)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	resp.Result.SIUncertainty = sigma
+	resp.Result.Uncertainty, _ = units.ConvertUncertainty(sigma, si, to)
+	if q.Value != 0 {
+		relative := sigma / math.Abs(q.Value)
+		resp.Result.RelativeUncertainty = &relative
+	}
+	for i := range resp.Contributions {
+		resp.Contributions[i].Contribution, _ = units.ConvertUncertainty(resp.Contributions[i].Contribution, si, to)
+	}
+	return resp, nil
+}
+
+// contribution starts the breakdown entry of a variable with its own
+// uncertainty as it was given.
+func (b *boundFormula) contribution(name string) models.UncertaintyContribution {
+	c := models.UncertaintyContribution{Symbol: name}
+	for _, sub := range b.substitutions {
+		if sub.Symbol == name {
+			c.Unit = sub.Unit
+			if sub.Uncertainty != nil {
+				c.Uncertainty = *sub.Uncertainty
+			}
+		}
+	}
+	return c
+}
+
+// linearPropagation fills in the contributions in SI units and returns the
+// combined standard uncertainty.
+func linearPropagation(b *boundFormula, names []string, resp *models.PropagateUncertaintyResponse) (float64, error) {
+	resp.Contributions = []models.UncertaintyContribution{}
+	var variance float64
+	for _, name := range names {
+		derivative := expr.Diff(b.node, name)
+		d, err := expr.Eval(derivative, b.vars)
+		if err != nil {
+			return 0, fmt.Errorf("cannot differentiate by %s: %w", name, err)
+		}
+		c := b.contribution(name)
+		c.Derivative = derivative.String()
+		c.Sensitivity = &d.Value
+		c.Contribution = math.Abs(d.Value) * b.sigmas[name]
+		variance += c.Contribution * c.Contribution
+		resp.Contributions = append(resp.Contributions, c)
+	}
+	setShares(resp.Contributions, variance)
+	return math.Sqrt(variance), nil
+}
+
+// monteCarlo samples the formula, describes the distribution of results in
+// the unit to, and returns their standard deviation.
+func monteCarlo(b *boundFormula, names []string, req models.PropagateUncertaintyRequest, to units.Unit, resp *models.PropagateUncertaintyResponse) (float64, error) {
+	n := req.Samples
+	if n == 0 {
+		n = defaultMonteCarloSamples
+	}
+	seed := time.Now().UnixNano()
+	if req.Seed != nil {
+		seed = *req.Seed
+	}
+	rng := rand.New(rand.NewSource(seed))
+	resp.Samples = n
+	resp.Seed = &seed
+
+	values, failed := sampleFormula(b, names, n, rng)
+	resp.FailedSamples = failed
+	// A few failures, such as a square root of a negative sample, only
+	// trim a tail; beyond that the distribution would be misleading.
+	if len(values) < n*99/100 {
+		return 0, errors.New("too many samples could not be evaluated, the uncertainties are too large for this formula")
+	}
+	mean, std := meanStd(values)
+	sort.Float64s(values)
+
+	si := units.Unit{Scale: 1, Dim: resp.Result.Dimension}
+	convert := func(v float64) *float64 {
+		out, _ := units.Convert(v, si, to)
+		return &out
+	}
+	resp.Result.Mean = convert(mean)
+	resp.Result.Median = convert(percentile(values, 0.5))
+	resp.Result.Interval = &[2]float64{*convert(percentile(values, 0.025)), *convert(percentile(values, 0.975))}
+
+	resp.Contributions = []models.UncertaintyContribution{}
+	var variance float64
+	for _, name := range names {
+		only, _ := sampleFormula(b, []string{name}, n, rng)
+		_, s := meanStd(only)
+		c := b.contribution(name)
+		c.Contribution = s
+		variance += s * s
+		resp.Contributions = append(resp.Contributions, c)
+	}
+	setShares(resp.Contributions, variance)
+	return std, nil
+}
+
+// sampleFormula evaluates the formula n times with the named variables
+// drawn from normal distributions and the others at their values. It
+// returns the results and the number of samples that failed to evaluate.
+func sampleFormula(b *boundFormula, names []string, n int, rng *rand.Rand) ([]float64, int) {
+	vars := make(map[string]expr.Quantity, len(b.vars))
+	for name, q := range b.vars {
+		vars[name] = q
+	}
+	values := make([]float64, 0, n)
+	failed := 0
+	for i := 0; i < n; i++ {
+		for _, name := range names {
+			q := b.vars[name]
+			q.Value += b.sigmas[name] * rng.NormFloat64()
+			vars[name] = q
+		}
+		q, err := expr.Eval(b.node, vars)
+		if err != nil {
+			failed++
+			continue
+		}
+		values = append(values, q.Value)
+	}
+	return values, failed
+}
+
+func setShares(contributions []models.UncertaintyContribution, variance float64) {
+	if variance == 0 {
+		return
+	}
+	for i := range contributions {
+		c := contributions[i].Contribution
+		contributions[i].Share = c * c / variance
+	}
+}
+
+func meanStd(values []float64) (mean, std float64) {
+	if len(values) == 0 {
+		return 0, 0
+	}
+	for _, v := range values {
+		mean += v
+	}
+	mean /= float64(len(values))
+	if len(values) < 2 {
+		return mean, 0
+	}
+	var sum float64
+	for _, v := range values {
+		sum += (v - mean) * (v - mean)
+	}
+	return mean, math.Sqrt(sum / float64(len(values)-1))
+}
+
+// percentile interpolates the p-quantile of sorted values.
+func percentile(sorted []float64, p float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+	pos := p * float64(len(sorted)-1)
+	i := int(pos)
+	if i+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(i)
+	return sorted[i] + frac*(sorted[i+1]-sorted[i])
+}
@@ -0,0 +1,93 @@
+package services
+
+import (
+	"math"
+	"testing"
+
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+func measured(value, uncertainty float64, unit string) models.VariableValue {
+	return models.VariableValue{Value: &value, Uncertainty: &uncertainty, Unit: unit}
+}
+
+// centripetal is F = m v²/r with relative uncertainties of 1%, 0.5% and 2%.
+func centripetal(method string, seed int64) models.PropagateUncertaintyRequest {
+	return models.PropagateUncertaintyRequest{
+		EvaluateFormulaRequest: models.EvaluateFormulaRequest{
+			Variables: map[string]models.VariableValue{
+				"m": measured(2, 0.02, "kg"),
+				"v": measured(3, 0.015, "m/s"),
+				"r": measured(0.5, 0.01, "m"),
+			},
+			Unit: "N",
+		},
+		Method:  method,
+		Samples: 20000,
+		Seed:    &seed,
+	}
+}
+
+func TestLinearPropagationProductQuotient(t *testing.T) {
+	resp, err := PropagateUncertainty(`F = \frac{m v^2}{r}`, centripetal(models.PropagationLinear, 0), NewFormulaScope(nil, nil, nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// For a product of powers the relative uncertainties add in quadrature,
+	// each multiplied by its exponent.
+	value := 2 * 3.0 * 3 / 0.5
+	relative := math.Sqrt(0.01*0.01 + (2*0.005)*(2*0.005) + 0.02*0.02)
+	if math.Abs(resp.Result.Value-value) > 1e-9 {
+		t.Errorf("value = %g, want %g", resp.Result.Value, value)
+	}
+	if math.Abs(resp.Result.Uncertainty-relative*value) > 1e-9 {
+		t.Errorf("uncertainty = %g, want %g", resp.Result.Uncertainty, relative*value)
+	}
+	if resp.Result.RelativeUncertainty == nil || math.Abs(*resp.Result.RelativeUncertainty-relative) > 1e-12 {
+		t.Errorf("relative uncertainty = %v, want %g", resp.Result.RelativeUncertainty, relative)
+	}
+
+	want := map[string]float64{"m": 0.01 * value, "v": 2 * 0.005 * value, "r": 0.02 * value}
+	var shares float64
+	for _, c := range resp.Contributions {
+		if math.Abs(c.Contribution-want[c.Symbol]) > 1e-9 {
+			t.Errorf("contribution of %s = %g, want %g", c.Symbol, c.Contribution, want[c.Symbol])
+		}
+		shares += c.Share
+	}
+	if len(resp.Contributions) != 3 || math.Abs(shares-1) > 1e-12 {
+		t.Errorf("got %d contributions with shares summing to %g", len(resp.Contributions), shares)
+	}
+}
+
+func TestMonteCarloAgreesWithLinear(t *testing.T) {
+	scope := NewFormulaScope(nil, nil, nil)
+	linear, err := PropagateUncertainty(`F = \frac{m v^2}{r}`, centripetal(models.PropagationLinear, 0), scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	mc, err := PropagateUncertainty(`F = \frac{m v^2}{r}`, centripetal(models.PropagationMonteCarlo, 1), scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// With 20000 samples the spread is known to about 0.5%.
+	if rel := math.Abs(mc.Result.Uncertainty/linear.Result.Uncertainty - 1); rel > 0.03 {
+		t.Errorf("Monte Carlo uncertainty = %g, linear %g", mc.Result.Uncertainty, linear.Result.Uncertainty)
+	}
+	if mc.Result.Mean == nil || math.Abs(*mc.Result.Mean-linear.Result.Value) > 0.1*linear.Result.Uncertainty {
+		t.Errorf("Monte Carlo mean = %v, want about %g", mc.Result.Mean, linear.Result.Value)
+	}
+	if mc.Samples != 20000 || mc.FailedSamples != 0 {
+		t.Errorf("samples = %d with %d failed", mc.Samples, mc.FailedSamples)
+	}
+
+	again, err := PropagateUncertainty(`F = \frac{m v^2}{r}`, centripetal(models.PropagationMonteCarlo, 1), scope)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Result.Uncertainty != mc.Result.Uncertainty {
+		t.Errorf("the same seed gave %g and %g", mc.Result.Uncertainty, again.Result.Uncertainty)
+	}
+}
@@ -0,0 +1,207 @@
+package store
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+// NewActivity builds an audit event for a mutation. before and after are
+// snapshots of the entity (either may be nil for creations and deletions);
+// when both are given only the fields that actually changed are kept.
+func NewActivity(projectID, actorID uuid.UUID, action, entityType string, entityID uuid.UUID, before, after interface{}) *models.ActivityEvent {
+	event := &models.ActivityEvent{
+		ID:         uuid.New(),
+		ProjectID:  projectID,
+		ActorID:    &actorID,
+		Action:     action,
+		EntityType: entityType,
+		EntityID:   entityID,
+		CreatedAt:  time.Now(),
+	}
+	event.Before, event.After = diffSnapshots(before, after)
+	return event
+}
+
+func diffSnapshots(before, after interface{}) (json.RawMessage, json.RawMessage) {
+	b := snapshotFields(before)
+	a := snapshotFields(after)
+
+	if b != nil && a != nil {
+		for key, value := range b {
+			if other, ok := a[key]; ok && bytes.Equal(value, other) {
+				delete(b, key)
+				delete(a, key)
+			}
+		}
+		delete(b, "updated_at")
+		delete(a, "updated_at")
+	}
+
+	return marshalSnapshot(b), marshalSnapshot(a)
+}
+
+func snapshotFields(v interface{}) map[string]json.RawMessage {
+	if v == nil {
+		return nil
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &fields); err != nil {
+		return nil
+	}
+	return fields
+}
+
+func marshalSnapshot(fields map[string]json.RawMessage) json.RawMessage {
+	if fields == nil {
+		return nil
+	}
+	raw, err := json.Marshal(fields)
+	if err != nil {
+		return nil
+	}
+	return raw
+}
+
+// withActivity runs fn in a transaction and records the activity event in the
+// same transaction, so a mutation is never committed without its audit entry.
+func (s *Store) withActivity(activity *models.ActivityEvent, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := insertActivity(tx, activity); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertActivity(tx *sqlx.Tx, activity *models.ActivityEvent) error {
+	if activity == nil {
+		return nil
+	}
+	query := `
+		INSERT INTO activity_events (id, project_id, actor_id, action, entity_type, entity_id, before, after, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+	`
+	_, err := tx.Exec(query, activity.ID, activity.ProjectID, activity.ActorID, activity.Action,
+		activity.EntityType, activity.EntityID, nullableJSON(activity.Before), nullableJSON(activity.After), activity.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record activity: %w", err)
+	}
+	return nil
+}
+
+func nullableJSON(raw json.RawMessage) interface{} {
+	if len(raw) == 0 {
+		return nil
+	}
+	return []byte(raw)
+}
+
+func (s *Store) GetActivityEvents(projectID uuid.UUID, filter models.ActivityFilter) (*models.ActivityPage, error) {
+	conditions := []string{"e.project_id = $1"}
+	args := []interface{}{projectID}
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if filter.UserID != nil {
+		conditions = append(conditions, "e.actor_id = "+arg(*filter.UserID))
+	}
+	if filter.EntityType != "" {
+		conditions = append(conditions, "e.entity_type = "+arg(filter.EntityType))
+	}
+	if filter.From != nil {
+		conditions = append(conditions, "e.created_at >= "+arg(*filter.From))
+	}
+	if filter.To != nil {
+		conditions = append(conditions, "e.created_at < "+arg(*filter.To))
+	}
+	if filter.Cursor != "" {
+		createdAt, id, err := decodeActivityCursor(filter.Cursor)
+		if err != nil {
+			return nil, err
+		}
+		conditions = append(conditions, fmt.Sprintf("(e.created_at, e.id) < (%s, %s)", arg(createdAt), arg(id)))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 || limit > 100 {
+		limit = 50
+	}
+
+	query := `
+		SELECT
+			e.id, e.project_id, e.actor_id, e.action, e.entity_type, e.entity_id,
+			COALESCE(e.before, 'null'::jsonb) as before,
+			COALESCE(e.after, 'null'::jsonb) as after,
+			e.created_at,
+			u.name as actor_name,
+			u.email as actor_email
+		FROM activity_events e
+		LEFT JOIN users u ON e.actor_id = u.id
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT ` + arg(limit+1)
+
+	var events []models.ActivityEventWithUser
+	if err := s.db.Select(&events, query, args...); err != nil {
+		return nil, fmt.Errorf("failed to get activity: %w", err)
+	}
+
+	page := &models.ActivityPage{Events: events}
+	if len(events) > limit {
+		page.Events = events[:limit]
+		last := page.Events[limit-1]
+		page.NextCursor = encodeActivityCursor(last.CreatedAt, last.ID)
+	}
+	if page.Events == nil {
+		page.Events = []models.ActivityEventWithUser{}
+	}
+	return page, nil
+}
+
+func encodeActivityCursor(createdAt time.Time, id uuid.UUID) string {
+	raw := createdAt.UTC().Format(time.RFC3339Nano) + "|" + id.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeActivityCursor(cursor string) (time.Time, uuid.UUID, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, uuid.Nil, models.ErrInvalidCursor
+	}
+	parts := strings.SplitN(string(raw), "|", 2)
+	if len(parts) != 2 {
+		return time.Time{}, uuid.Nil, models.ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, parts[0])
+	if err != nil {
+		return time.Time{}, uuid.Nil, models.ErrInvalidCursor
+	}
+	id, err := uuid.Parse(parts[1])
+	if err != nil {
+		return time.Time{}, uuid.Nil, models.ErrInvalidCursor
+	}
+	return createdAt, id, nil
+}
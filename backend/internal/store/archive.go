@@ -0,0 +1,551 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/markup"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// ImportedFile is an attachment file that has already been written to the
+// upload directory and only needs its database row.
+type ImportedFile struct {
+	Filename string
+	FilePath string
+}
+
+func (s *Store) ExportProject(projectID, exportedBy uuid.UUID) (*models.ProjectArchive, error) {
+	project, err := s.GetProjectByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if project == nil {
+		return nil, fmt.Errorf("project not found")
+	}
+
+	a := &models.ProjectArchive{
+		Manifest: models.ArchiveManifest{
+			SchemaVersion:   models.ArchiveSchemaVersion,
+			ExportedAt:      time.Now(),
+			ExportedBy:      exportedBy,
+			SourceProjectID: projectID,
+		},
+		Project: *project,
+	}
+
+	workflow, err := s.GetWorkflow(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if !workflow.IsDefault {
+		a.Workflow = workflow
+	}
+
+	members, err := s.GetProjectMembers(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		a.Members = append(a.Members, models.ArchiveMember{
+			UserID: m.UserID,
+			Email:  m.UserEmail,
+			Name:   m.UserName,
+			Role:   m.Role,
+		})
+	}
+
+	tasks, err := s.GetTasksByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	taskTags, err := s.GetTaskTagsByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	tagsByTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, tt := range taskTags {
+		tagsByTask[tt.TaskID] = append(tagsByTask[tt.TaskID], tt.TagID)
+	}
+	checklistItems, err := s.GetChecklistItemsByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	checklistByTask := make(map[uuid.UUID][]models.ChecklistItem)
+	for _, item := range checklistItems {
+		checklistByTask[item.TaskID] = append(checklistByTask[item.TaskID], item)
+	}
+	deps, err := s.GetDependenciesByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	blockersByTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, d := range deps {
+		blockersByTask[d.BlockedID] = append(blockersByTask[d.BlockedID], d.BlockerID)
+	}
+	assignees, err := s.GetTaskAssigneesByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	assigneesByTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, l := range assignees {
+		assigneesByTask[l.TaskID] = append(assigneesByTask[l.TaskID], l.UserID)
+	}
+	watchers, err := s.GetTaskWatchersByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	watchersByTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, l := range watchers {
+		watchersByTask[l.TaskID] = append(watchersByTask[l.TaskID], l.UserID)
+	}
+	for _, t := range tasks {
+		t.Assignees = assigneesByTask[t.ID]
+		a.Tasks = append(a.Tasks, models.ArchiveTask{
+			Task:      t,
+			TagIDs:    tagsByTask[t.ID],
+			Checklist: checklistByTask[t.ID],
+			BlockedBy: blockersByTask[t.ID],
+			Watchers:  watchersByTask[t.ID],
+		})
+	}
+
+	tags, err := s.GetTagsByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tags {
+		a.Tags = append(a.Tags, t.Tag)
+	}
+
+	if a.Comments, err = s.GetCommentsByProject(projectID); err != nil {
+		return nil, err
+	}
+
+	boards, err := s.GetBoardsByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, b := range boards {
+		a.Boards = append(a.Boards, models.ArchiveBoard{
+			ID:        b.ID,
+			Name:      b.Name,
+			Data:      b.Data,
+			Settings:  b.Settings,
+			CreatedAt: b.CreatedAt,
+			UpdatedAt: b.UpdatedAt,
+		})
+	}
+
+	if a.Constants, err = s.GetConstantsByProject(projectID); err != nil {
+		return nil, err
+	}
+	if a.Formulas, err = s.GetFormulasByProject(projectID); err != nil {
+		return nil, err
+	}
+	for i := range a.Formulas {
+		if a.Formulas[i].Variables, err = s.GetFormulaVariables(a.Formulas[i].ID); err != nil {
+			return nil, err
+		}
+	}
+
+	attachments, err := s.GetAttachmentsByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, att := range attachments {
+		a.Attachments = append(a.Attachments, models.ArchiveAttachment{Attachment: att})
+	}
+
+	return a, nil
+}
+
+// ImportProject recreates an exported project owned by ownerID. Every entity
+// gets a fresh ID; members are matched to local users by email to keep
+// authorship, and returned as suggested members rather than added, so only
+// the importer can be assigned or watch tasks. The workflow is restored and
+// tasks in statuses it lacks start in its initial status. Anything that
+// cannot be mapped is reported as a conflict instead of failing the import.
+func (s *Store) ImportProject(a *models.ProjectArchive, ownerID uuid.UUID, files map[uuid.UUID]ImportedFile) (*models.ImportReport, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	now := time.Now()
+	report := &models.ImportReport{
+		Counts:           make(map[string]int),
+		Conflicts:        []models.ImportConflict{},
+		SuggestedMembers: []models.SuggestedMember{},
+	}
+
+	name := a.Project.Name
+	var nameTaken bool
+	err = tx.Get(&nameTaken, `SELECT EXISTS(SELECT 1 FROM projects WHERE owner_id = $1 AND name = $2)`, ownerID, name)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check project name: %w", err)
+	}
+	if nameTaken {
+		name = fmt.Sprintf("%s (imported %s)", name, now.Format("2006-01-02 15:04"))
+		report.AddConflict("project", a.Project.ID, "a project with this name already exists, imported as \""+name+"\"")
+	}
+
+	project := models.Project{
+		ID:          uuid.New(),
+		Name:        name,
+		Description: a.Project.Description,
+		OwnerID:     ownerID,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	_, err = tx.Exec(`
+        INSERT INTO projects (id, name, description, owner_id, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6)
+    `, project.ID, project.Name, project.Description, project.OwnerID, project.CreatedAt, project.UpdatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	_, err = tx.Exec(`
+        INSERT INTO project_members (id, project_id, user_id, role, joined_at)
+        VALUES ($1, $2, $3, 'owner', $4)
+    `, uuid.New(), project.ID, ownerID, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add owner as member: %w", err)
+	}
+	report.Project = project
+
+	users := make(map[uuid.UUID]uuid.UUID)
+	for _, m := range a.Members {
+		var localID uuid.UUID
+		err := tx.Get(&localID, `SELECT id FROM users WHERE email = $1`, m.Email)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				report.AddConflict("member", m.UserID, "no local user with email "+m.Email+", membership skipped")
+				continue
+			}
+			return nil, fmt.Errorf("failed to resolve member: %w", err)
+		}
+		users[m.UserID] = localID
+		if localID == ownerID {
+			continue
+		}
+
+		// Matched users never agreed to join, so they are only suggested
+		// to the importer instead of being added as members.
+		role := m.Role
+		if role == "owner" {
+			role = "admin"
+		}
+		report.SuggestedMembers = append(report.SuggestedMembers, models.SuggestedMember{
+			UserID: localID,
+			Email:  m.Email,
+			Role:   role,
+		})
+	}
+	userOrOwner := func(id uuid.UUID) uuid.UUID {
+		if local, ok := users[id]; ok {
+			return local
+		}
+		return ownerID
+	}
+	// Only the importer is a member, so only they can be assigned or watch.
+	isImporter := func(id uuid.UUID) bool {
+		local, ok := users[id]
+		return ok && local == ownerID
+	}
+
+	// Tasks keep their statuses only if the workflow has them, so the board
+	// shows no columns the workflow does not know.
+	workflow := models.DefaultWorkflow(project.ID)
+	if a.Workflow != nil {
+		imported := *a.Workflow
+		imported.ProjectID = project.ID
+		imported.UpdatedAt = now
+		imported.IsDefault = false
+		if err := imported.Validate(); err != nil {
+			report.AddConflict("workflow", a.Project.ID, err.Error()+", the default workflow is used")
+		} else {
+			_, err := tx.Exec(`
+                INSERT INTO project_workflows (project_id, statuses, transitions, block_open_subtasks, block_on_dependencies, updated_at)
+                VALUES ($1, $2, $3, $4, $5, $6)
+            `, imported.ProjectID, imported.Statuses, imported.Transitions, imported.BlockOpenSubtasks,
+				imported.BlockOnDependencies, imported.UpdatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import workflow: %w", err)
+			}
+			workflow = &imported
+		}
+	}
+
+	tagIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, t := range a.Tags {
+		newID := uuid.New()
+		_, err := tx.Exec(`
+            INSERT INTO tags (id, project_id, name, color, created_by, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+        `, newID, project.ID, t.Name, t.Color, userOrOwner(t.CreatedBy), t.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import tag %q: %w", t.Name, err)
+		}
+		tagIDs[t.ID] = newID
+		report.Counts["tags"]++
+	}
+
+	taskIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, t := range a.Tasks {
+		newID := uuid.New()
+		var assignee *uuid.UUID
+		if t.AssignedTo != nil {
+			if isImporter(*t.AssignedTo) {
+				assignee = &ownerID
+			} else {
+				report.AddConflict("task", t.ID, "assignee is not a member of the imported project, task left unassigned")
+			}
+		}
+		status := t.Status
+		if _, ok := workflow.Status(status); !ok {
+			status = workflow.InitialStatus()
+			report.AddConflict("task", t.ID, fmt.Sprintf("status %q is not in the workflow, task imported as %q", t.Status, status))
+		}
+		_, err := tx.Exec(`
+            INSERT INTO tasks (id, project_id, title, description, description_html, status, priority, due_date, assigned_to, rank, created_by, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13)
+        `, newID, project.ID, t.Title, t.Description, markup.Safe(t.Description), status, t.Priority, t.DueDate,
+			assignee, t.Rank, userOrOwner(t.CreatedBy), t.CreatedAt, t.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import task %q: %w", t.Title, err)
+		}
+		snapshot := models.SnapshotOf(&t.Task)
+		snapshot.Status = status
+		snapshot.AssignedTo = assignee
+		createdBy := userOrOwner(t.CreatedBy)
+		if err := recordRevision(tx, newID, &createdBy, models.TaskSnapshot{}, snapshot, nil); err != nil {
+			return nil, err
+		}
+		taskIDs[t.ID] = newID
+		report.Counts["tasks"]++
+
+		var assignees []uuid.UUID
+		if assignee != nil {
+			assignees = append(assignees, *assignee)
+		}
+		for _, userID := range t.Assignees {
+			if !isImporter(userID) {
+				report.AddConflict("task_assignee", userID, "assignee is not a member of the imported project, assignment skipped")
+				continue
+			}
+			assignees = append(assignees, ownerID)
+		}
+		if err := insertAssignees(tx, newID, assignees, &createdBy, t.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		for _, userID := range t.Watchers {
+			if !isImporter(userID) {
+				report.AddConflict("task_watcher", userID, "watcher is not a member of the imported project, watch skipped")
+				continue
+			}
+			_, err := tx.Exec(`
+                INSERT INTO task_watchers (task_id, user_id, created_at)
+                VALUES ($1, $2, $3)
+                ON CONFLICT DO NOTHING
+            `, newID, ownerID, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import task watcher: %w", err)
+			}
+		}
+
+		for _, tagID := range t.TagIDs {
+			newTagID, ok := tagIDs[tagID]
+			if !ok {
+				report.AddConflict("task_tag", tagID, "tag referenced by task is not in the archive")
+				continue
+			}
+			_, err := tx.Exec(`INSERT INTO task_tags (task_id, tag_id, created_at) VALUES ($1, $2, $3)`, newID, newTagID, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import task tag: %w", err)
+			}
+		}
+
+		for _, item := range t.Checklist {
+			var createdBy *uuid.UUID
+			if item.CreatedBy != nil {
+				local := userOrOwner(*item.CreatedBy)
+				createdBy = &local
+			}
+			_, err := tx.Exec(`
+                INSERT INTO checklist_items (id, task_id, content, done, position, created_by, created_at, updated_at)
+                VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+            `, uuid.New(), newID, item.Content, item.Done, item.Position, createdBy, item.CreatedAt, item.UpdatedAt)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import checklist item: %w", err)
+			}
+			report.Counts["checklist_items"]++
+		}
+	}
+
+	// Parents and dependencies are linked once all tasks exist, since a task
+	// may be listed before the tasks it refers to.
+	for _, t := range a.Tasks {
+		if t.ParentID != nil {
+			parentID, ok := taskIDs[*t.ParentID]
+			if !ok {
+				report.AddConflict("task", t.ID, "parent task is not in the archive, imported as a top-level task")
+			} else if _, err := tx.Exec(`UPDATE tasks SET parent_id = $1 WHERE id = $2`, parentID, taskIDs[t.ID]); err != nil {
+				return nil, fmt.Errorf("failed to link subtask %q: %w", t.Title, err)
+			}
+		}
+
+		for _, blockerID := range t.BlockedBy {
+			newBlockerID, ok := taskIDs[blockerID]
+			if !ok {
+				report.AddConflict("task_dependency", blockerID, "blocking task is not in the archive")
+				continue
+			}
+			res, err := tx.Exec(`
+                INSERT INTO task_dependencies (blocker_id, blocked_id, created_by, created_at)
+                VALUES ($1, $2, $3, $4)
+                ON CONFLICT DO NOTHING
+            `, newBlockerID, taskIDs[t.ID], ownerID, now)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import task dependency: %w", err)
+			}
+			if n, _ := res.RowsAffected(); n == 1 {
+				report.Counts["dependencies"]++
+			}
+		}
+	}
+
+	// Comments are exported oldest first, so parents precede their replies.
+	commentIDs := make(map[uuid.UUID]uuid.UUID, len(a.Comments))
+	for _, c := range a.Comments {
+		taskID, ok := taskIDs[c.TaskID]
+		if !ok {
+			report.AddConflict("comment", c.ID, "comment refers to a task that is not in the archive")
+			continue
+		}
+		var parentID *uuid.UUID
+		if c.ParentID != nil {
+			newParentID, ok := commentIDs[*c.ParentID]
+			if !ok {
+				report.AddConflict("comment", c.ID, "reply refers to a comment that is not in the archive")
+				continue
+			}
+			parentID = &newParentID
+		}
+		if _, ok := users[c.UserID]; !ok {
+			report.AddConflict("comment", c.ID, "author is not a local user, comment attributed to the importer")
+		}
+		commentIDs[c.ID] = uuid.New()
+		_, err := tx.Exec(`
+            INSERT INTO task_comments (id, task_id, parent_id, user_id, content, content_html, created_at, updated_at, deleted_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+        `, commentIDs[c.ID], taskID, parentID, userOrOwner(c.UserID), c.Content, markup.Safe(c.Content),
+			c.CreatedAt, c.UpdatedAt, c.DeletedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import comment: %w", err)
+		}
+		report.Counts["comments"]++
+	}
+
+	for _, b := range a.Boards {
+		_, err := tx.Exec(`
+            INSERT INTO boards (id, project_id, name, data, settings, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7)
+        `, uuid.New(), project.ID, b.Name, b.Data, b.Settings, b.CreatedAt, b.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import board %q: %w", b.Name, err)
+		}
+		report.Counts["boards"]++
+	}
+
+	constantIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, c := range a.Constants {
+		newID := uuid.New()
+		_, err := tx.Exec(`
+            INSERT INTO constants (id, name, symbol, value, unit, numeric_value, uncertainty, is_exact, unit_scale, dimensions,
+                description, scope, scope_id, created_by, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'project', $12, $13, $14, $15)
+        `, newID, c.Name, c.Symbol, c.Value, c.Unit, c.NumericValue, c.Uncertainty, c.Exact, c.UnitScale, c.Dimensions,
+			c.Description, project.ID, userOrOwner(c.CreatedBy), c.CreatedAt, c.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import constant %q: %w", c.Name, err)
+		}
+		constantIDs[c.ID] = newID
+		report.Counts["constants"]++
+	}
+
+	formulaIDs := make(map[uuid.UUID]uuid.UUID)
+	for _, f := range a.Formulas {
+		newID := uuid.New()
+		_, err := tx.Exec(`
+            INSERT INTO formulas (id, title, latex, description, project_id, created_by, created_at, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        `, newID, f.Title, f.Latex, f.Description, project.ID, userOrOwner(f.CreatedBy), f.CreatedAt, f.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import formula %q: %w", f.Title, err)
+		}
+		formulaIDs[f.ID] = newID
+
+		// Links to the project's constants follow them to their new IDs;
+		// links to other constants are kept only if they exist here.
+		for _, v := range f.Variables {
+			constantID := v.ConstantID
+			if constantID != nil {
+				if newConstantID, ok := constantIDs[*constantID]; ok {
+					constantID = &newConstantID
+				}
+			}
+			_, err := tx.Exec(`
+                INSERT INTO formula_variables (formula_id, symbol, unit, constant_id, description)
+                VALUES ($1, $2, $3, (SELECT id FROM constants WHERE id = $4), $5)
+            `, newID, v.Symbol, v.Unit, constantID, v.Description)
+			if err != nil {
+				return nil, fmt.Errorf("failed to import variable %s of formula %q: %w", v.Symbol, f.Title, err)
+			}
+		}
+		report.Counts["formulas"]++
+	}
+
+	for _, att := range a.Attachments {
+		file, ok := files[att.ID]
+		if !ok {
+			continue
+		}
+
+		var entityID uuid.UUID
+		switch att.EntityType {
+		case "project":
+			entityID, ok = project.ID, true
+		case "task":
+			entityID, ok = taskIDs[att.EntityID]
+		case "formula":
+			entityID, ok = formulaIDs[att.EntityID]
+		default:
+			ok = false
+		}
+		if !ok {
+			report.AddConflict("attachment", att.ID, "attachment refers to an entity that is not in the archive")
+			continue
+		}
+
+		_, err := tx.Exec(`
+            INSERT INTO attachments (id, filename, original_name, file_path, file_size, mime_type, entity_type, entity_id, uploaded_by, created_at)
+            VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+        `, uuid.New(), file.Filename, att.OriginalName, file.FilePath, att.FileSize, att.MimeType,
+			att.EntityType, entityID, userOrOwner(att.UploadedBy), att.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to import attachment: %w", err)
+		}
+		report.Counts["attachments"]++
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	return report, nil
+}
@@ -0,0 +1,265 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrAssigneeNotFound = errors.New("user is not assigned to this task")
+	ErrWatcherNotFound  = errors.New("user is not watching this task")
+)
+
+func insertAssignees(tx *sqlx.Tx, taskID uuid.UUID, userIDs []uuid.UUID, assignedBy *uuid.UUID, at time.Time) error {
+	for _, userID := range userIDs {
+		_, err := tx.Exec(`
+            INSERT INTO task_assignees (task_id, user_id, assigned_by, created_at)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT DO NOTHING
+        `, taskID, userID, assignedBy, at)
+		if err != nil {
+			return fmt.Errorf("failed to add task assignee: %w", err)
+		}
+	}
+	return nil
+}
+
+// unassignUser removes a user from a locked task. If they were the primary
+// assignee, the longest-standing remaining assignee takes over.
+func unassignUser(tx *sqlx.Tx, task *models.Task, userID uuid.UUID, changedBy *uuid.UUID) (bool, error) {
+	res, err := tx.Exec(`DELETE FROM task_assignees WHERE task_id = $1 AND user_id = $2`, task.ID, userID)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove task assignee: %w", err)
+	}
+	removed, _ := res.RowsAffected()
+
+	if task.AssignedTo == nil || *task.AssignedTo != userID {
+		return removed > 0, nil
+	}
+
+	var next []uuid.UUID
+	err = tx.Select(&next, `
+        SELECT user_id FROM task_assignees WHERE task_id = $1
+        ORDER BY created_at ASC LIMIT 1
+    `, task.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to get task assignees: %w", err)
+	}
+
+	before := models.SnapshotOf(task)
+	task.AssignedTo = nil
+	if len(next) > 0 {
+		task.AssignedTo = &next[0]
+	}
+	task.UpdatedAt = time.Now()
+	_, err = tx.Exec(`UPDATE tasks SET assigned_to = $1, updated_at = $2 WHERE id = $3`, task.AssignedTo, task.UpdatedAt, task.ID)
+	if err != nil {
+		return false, fmt.Errorf("failed to update primary assignee: %w", err)
+	}
+	return true, recordRevision(tx, task.ID, changedBy, before, models.SnapshotOf(task), nil)
+}
+
+// AddTaskAssignee assigns a user to a task and reports whether they were not
+// assigned already. A task without a primary assignee gets the new user as
+// primary.
+func (s *Store) AddTaskAssignee(task *models.Task, userID uuid.UUID, activity *models.ActivityEvent) (bool, error) {
+	added := false
+	err := s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := tx.Get(task, `SELECT * FROM tasks WHERE id = $1 FOR UPDATE`, task.ID); err != nil {
+			return fmt.Errorf("failed to lock task: %w", err)
+		}
+
+		var assignedBy *uuid.UUID
+		if activity != nil {
+			assignedBy = activity.ActorID
+		}
+		res, err := tx.Exec(`
+            INSERT INTO task_assignees (task_id, user_id, assigned_by, created_at)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT DO NOTHING
+        `, task.ID, userID, assignedBy, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to add task assignee: %w", err)
+		}
+		n, _ := res.RowsAffected()
+		added = n > 0
+
+		if task.AssignedTo != nil {
+			return nil
+		}
+		before := models.SnapshotOf(task)
+		task.AssignedTo = &userID
+		task.UpdatedAt = time.Now()
+		_, err = tx.Exec(`UPDATE tasks SET assigned_to = $1, updated_at = $2 WHERE id = $3`, task.AssignedTo, task.UpdatedAt, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update primary assignee: %w", err)
+		}
+		return recordRevision(tx, task.ID, assignedBy, before, models.SnapshotOf(task), nil)
+	})
+	return added, err
+}
+
+func (s *Store) RemoveTaskAssignee(task *models.Task, userID uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := tx.Get(task, `SELECT * FROM tasks WHERE id = $1 FOR UPDATE`, task.ID); err != nil {
+			return fmt.Errorf("failed to lock task: %w", err)
+		}
+
+		var changedBy *uuid.UUID
+		if activity != nil {
+			changedBy = activity.ActorID
+		}
+		removed, err := unassignUser(tx, task, userID, changedBy)
+		if err != nil {
+			return err
+		}
+		if !removed {
+			return ErrAssigneeNotFound
+		}
+		return nil
+	})
+}
+
+// removeMemberFromTasks drops a former member's assignments and watches in a
+// project.
+func removeMemberFromTasks(tx *sqlx.Tx, projectID, userID uuid.UUID, changedBy *uuid.UUID) error {
+	var tasks []models.Task
+	err := tx.Select(&tasks, `
+        SELECT t.* FROM tasks t
+        WHERE t.project_id = $1
+          AND (t.assigned_to = $2 OR EXISTS (
+              SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = $2
+          ))
+        FOR UPDATE
+    `, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get member tasks: %w", err)
+	}
+	for i := range tasks {
+		if _, err := unassignUser(tx, &tasks[i], userID, changedBy); err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.Exec(`
+        DELETE FROM task_watchers w USING tasks t
+        WHERE w.task_id = t.id AND t.project_id = $1 AND w.user_id = $2
+    `, projectID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove member watches: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetTaskAssignees(taskID uuid.UUID) ([]models.TaskUser, error) {
+	users := []models.TaskUser{}
+	query := `
+        SELECT a.task_id, a.user_id, u.name as user_name, u.email as user_email, a.created_at
+        FROM task_assignees a
+        INNER JOIN users u ON u.id = a.user_id
+        WHERE a.task_id = $1
+        ORDER BY a.created_at ASC
+    `
+	if err := s.db.Select(&users, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task assignees: %w", err)
+	}
+	return users, nil
+}
+
+func (s *Store) GetTaskAssigneesByProject(projectID uuid.UUID) ([]models.TaskUserLink, error) {
+	var links []models.TaskUserLink
+	query := `
+        SELECT a.task_id, a.user_id FROM task_assignees a
+        INNER JOIN tasks t ON t.id = a.task_id
+        WHERE t.project_id = $1
+        ORDER BY a.created_at ASC
+    `
+	if err := s.db.Select(&links, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get task assignees: %w", err)
+	}
+	return links, nil
+}
+
+func (s *Store) attachTaskAssignees(tasks []*models.Task, ids []string) error {
+	var links []models.TaskUserLink
+	query := `
+        SELECT task_id, user_id FROM task_assignees
+        WHERE task_id = ANY($1::uuid[])
+        ORDER BY created_at ASC
+    `
+	if err := s.db.Select(&links, query, pq.Array(ids)); err != nil {
+		return fmt.Errorf("failed to get task assignees: %w", err)
+	}
+
+	byTask := make(map[uuid.UUID][]uuid.UUID)
+	for _, l := range links {
+		byTask[l.TaskID] = append(byTask[l.TaskID], l.UserID)
+	}
+	for _, t := range tasks {
+		t.Assignees = byTask[t.ID]
+	}
+	return nil
+}
+
+func (s *Store) AddTaskWatcher(taskID, userID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `
+        INSERT INTO task_watchers (task_id, user_id, created_at)
+        VALUES ($1, $2, $3)
+        ON CONFLICT DO NOTHING
+    `
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, taskID, userID, time.Now()); err != nil {
+			return fmt.Errorf("failed to add task watcher: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) RemoveTaskWatcher(taskID, userID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `DELETE FROM task_watchers WHERE task_id = $1 AND user_id = $2`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, taskID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove task watcher: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrWatcherNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetTaskWatchers(taskID uuid.UUID) ([]models.TaskUser, error) {
+	users := []models.TaskUser{}
+	query := `
+        SELECT w.task_id, w.user_id, u.name as user_name, u.email as user_email, w.created_at
+        FROM task_watchers w
+        INNER JOIN users u ON u.id = w.user_id
+        WHERE w.task_id = $1
+        ORDER BY w.created_at ASC
+    `
+	if err := s.db.Select(&users, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task watchers: %w", err)
+	}
+	return users, nil
+}
+
+func (s *Store) GetTaskWatchersByProject(projectID uuid.UUID) ([]models.TaskUserLink, error) {
+	var links []models.TaskUserLink
+	query := `
+        SELECT w.task_id, w.user_id FROM task_watchers w
+        INNER JOIN tasks t ON t.id = w.task_id
+        WHERE t.project_id = $1
+        ORDER BY w.created_at ASC
+    `
+	if err := s.db.Select(&links, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get task watchers: %w", err)
+	}
+	return links, nil
+}
@@ -7,20 +7,23 @@ import (
 
     "github.com/google/uuid"
     "github.com/itmo-pride/student-taskboard/backend/internal/models"
+    "github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateAttachment(attachment *models.Attachment) error {
+func (s *Store) CreateAttachment(attachment *models.Attachment, activity *models.ActivityEvent) error {
     query := `
         INSERT INTO attachments (id, filename, original_name, file_path, file_size, mime_type, entity_type, entity_id, uploaded_by, created_at)
         VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
     `
-    _, err := s.db.Exec(query, attachment.ID, attachment.Filename, attachment.OriginalName,
-        attachment.FilePath, attachment.FileSize, attachment.MimeType, attachment.EntityType,
-        attachment.EntityID, attachment.UploadedBy, attachment.CreatedAt)
-    if err != nil {
-        return fmt.Errorf("failed to create attachment: %w", err)
-    }
-    return nil
+    return s.withActivity(activity, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, attachment.ID, attachment.Filename, attachment.OriginalName,
+            attachment.FilePath, attachment.FileSize, attachment.MimeType, attachment.EntityType,
+            attachment.EntityID, attachment.UploadedBy, attachment.CreatedAt)
+        if err != nil {
+            return fmt.Errorf("failed to create attachment: %w", err)
+        }
+        return nil
+    })
 }
 
 func (s *Store) GetAttachmentByID(id uuid.UUID) (*models.Attachment, error) {
@@ -46,11 +49,28 @@ func (s *Store) GetAttachmentsByEntity(entityType string, entityID uuid.UUID) ([
     return attachments, nil
 }
 
-func (s *Store) DeleteAttachment(id uuid.UUID) error {
+func (s *Store) DeleteAttachment(id uuid.UUID, activity *models.ActivityEvent) error {
     query := `DELETE FROM attachments WHERE id = $1`
-    _, err := s.db.Exec(query, id)
-    if err != nil {
-        return fmt.Errorf("failed to delete attachment: %w", err)
+    return s.withActivity(activity, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, id)
+        if err != nil {
+            return fmt.Errorf("failed to delete attachment: %w", err)
+        }
+        return nil
+    })
+}
+
+func (s *Store) GetAttachmentsByProject(projectID uuid.UUID) ([]models.Attachment, error) {
+    var attachments []models.Attachment
+    query := `
+        SELECT a.* FROM attachments a
+        WHERE (a.entity_type = 'project' AND a.entity_id = $1)
+           OR (a.entity_type = 'task' AND a.entity_id IN (SELECT id FROM tasks WHERE project_id = $1))
+           OR (a.entity_type = 'formula' AND a.entity_id IN (SELECT id FROM formulas WHERE project_id = $1))
+        ORDER BY a.created_at ASC
+    `
+    if err := s.db.Select(&attachments, query, projectID); err != nil {
+        return nil, fmt.Errorf("failed to get project attachments: %w", err)
     }
-    return nil
+    return attachments, nil
 }
@@ -9,19 +9,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateBoard(board *models.Board) error {
+func (s *Store) CreateBoard(board *models.Board, activity *models.ActivityEvent) error {
 	query := `
 		INSERT INTO boards (id, project_id, name, data, settings, created_at, updated_at)
 		VALUES ($1, $2, $3, $4, $5, $6, $7)
 	`
-	_, err := s.db.Exec(query, board.ID, board.ProjectID, board.Name,
-		board.Data, board.Settings, board.CreatedAt, board.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create board: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, board.ID, board.ProjectID, board.Name,
+			board.Data, board.Settings, board.CreatedAt, board.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create board: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Store) GetBoardsByProject(projectID uuid.UUID) ([]models.Board, error) {
@@ -60,26 +63,30 @@ func (s *Store) UpdateBoardData(boardID uuid.UUID, data json.RawMessage) error {
 	return nil
 }
 
-func (s *Store) UpdateBoard(board *models.Board) error {
+func (s *Store) UpdateBoard(board *models.Board, activity *models.ActivityEvent) error {
 	query := `
 		UPDATE boards 
 		SET name = $1, updated_at = $2 
 		WHERE id = $3
 	`
-	_, err := s.db.Exec(query, board.Name, time.Now(), board.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update board: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, board.Name, time.Now(), board.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update board: %w", err)
+		}
+		return nil
+	})
 }
 
-func (s *Store) DeleteBoard(id uuid.UUID) error {
+func (s *Store) DeleteBoard(id uuid.UUID, activity *models.ActivityEvent) error {
 	query := `DELETE FROM boards WHERE id = $1`
-	_, err := s.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete board: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete board: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Store) AddObjectToBoard(boardID uuid.UUID, object models.DrawObject) error {
@@ -127,7 +134,7 @@ func (s *Store) RemoveObjectFromBoard(boardID uuid.UUID, objectID string) error
 	return nil
 }
 
-func (s *Store) ClearBoard(boardID uuid.UUID) error {
+func (s *Store) ClearBoard(boardID uuid.UUID, activity *models.ActivityEvent) error {
 	query := `
 		UPDATE boards 
 		SET 
@@ -135,9 +142,11 @@ func (s *Store) ClearBoard(boardID uuid.UUID) error {
 			updated_at = $1
 		WHERE id = $2
 	`
-	_, err := s.db.Exec(query, time.Now(), boardID)
-	if err != nil {
-		return fmt.Errorf("failed to clear board: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, time.Now(), boardID)
+		if err != nil {
+			return fmt.Errorf("failed to clear board: %w", err)
+		}
+		return nil
+	})
 }
@@ -0,0 +1,270 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+var ErrSystemUserMissing = errors.New("system user not found, run the migrations first")
+
+// codataAliases maps the names used by the seeded library to CODATA names.
+var codataAliases = map[string]string{
+	"vacuum permittivity": "vacuum electric permittivity",
+	"vacuum permeability": "vacuum mag. permeability",
+}
+
+// codataSymbols gives symbols to well-known constants added by an import.
+// CODATA tables have no symbols, so the others are added without one.
+var codataSymbols = map[string]string{
+	"speed of light in vacuum":                  "c",
+	"planck constant":                           "h",
+	"reduced planck constant":                   "ħ",
+	"elementary charge":                         "e",
+	"avogadro constant":                         "N_A",
+	"boltzmann constant":                        "k_B",
+	"newtonian constant of gravitation":         "G",
+	"electron mass":                             "m_e",
+	"proton mass":                               "m_p",
+	"neutron mass":                              "m_n",
+	"muon mass":                                 "m_μ",
+	"alpha particle mass":                       "m_α",
+	"deuteron mass":                             "m_d",
+	"atomic mass constant":                      "m_u",
+	"vacuum electric permittivity":              "ε0",
+	"vacuum mag. permeability":                  "μ0",
+	"characteristic impedance of vacuum":        "Z_0",
+	"molar gas constant":                        "R",
+	"faraday constant":                          "F",
+	"stefan-boltzmann constant":                 "σ",
+	"wien wavelength displacement law constant": "b",
+	"first radiation constant":                  "c_1",
+	"second radiation constant":                 "c_2",
+	"fine-structure constant":                   "α",
+	"rydberg constant":                          "R_∞",
+	"bohr radius":                               "a_0",
+	"classical electron radius":                 "r_e",
+	"thomson cross section":                     "σ_e",
+	"hartree energy":                            "E_h",
+	"bohr magneton":                             "μ_B",
+	"nuclear magneton":                          "μ_N",
+	"magnetic flux quantum":                     "Φ_0",
+	"conductance quantum":                       "G_0",
+	"josephson constant":                        "K_J",
+	"von klitzing constant":                     "R_K",
+	"standard acceleration of gravity":          "g_n",
+	"standard atmosphere":                       "atm",
+}
+
+func normalizeConstantName(name string) string {
+	name = strings.ToLower(strings.Join(strings.Fields(name), " "))
+	name = strings.NewReplacer("–", "-", "—", "-").Replace(name)
+	if alias, ok := codataAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+type codataPlanItem struct {
+	entry  models.CODATAEntry
+	change models.CODATAChange
+}
+
+// ImportCODATA compares table with the approved global constants, matching
+// them by name, and reports what would be added and updated. With apply set
+// it then makes those changes, owned by the system user, records the dataset
+// and logs every change in the moderation trail under actorID (nil for the
+// command line). Constants whose unit has a different dimension than the
+// library's are skipped rather than overwritten.
+func (s *Store) ImportCODATA(table *models.CODATATable, actorID *uuid.UUID, apply bool) (*models.CODATAReport, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var systemID uuid.UUID
+	if err := tx.Get(&systemID, `SELECT id FROM users WHERE email = $1`, SystemUserEmail); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSystemUserMissing
+		}
+		return nil, fmt.Errorf("failed to get system user: %w", err)
+	}
+
+	report := &models.CODATAReport{
+		Version: table.Version,
+		Added:   []models.CODATAChange{},
+		Updated: []models.CODATAChange{},
+		Skipped: append([]models.CODATASkipped{}, table.Skipped...),
+	}
+	err = tx.Get(&report.PreviousVersion, `
+		SELECT version FROM constant_datasets
+		WHERE source = $1
+		ORDER BY imported_at DESC
+		LIMIT 1
+	`, models.DatasetSourceCODATA)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("failed to get previous dataset: %w", err)
+	}
+
+	existing := []models.Constant{}
+	err = tx.Select(&existing, `
+		SELECT * FROM constants
+		WHERE scope = 'global' AND review_status = 'approved'
+		ORDER BY created_at ASC
+		FOR UPDATE
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get global constants: %w", err)
+	}
+	byName := make(map[string]*models.Constant, len(existing))
+	for i := range existing {
+		key := normalizeConstantName(existing[i].Name)
+		if _, ok := byName[key]; !ok {
+			byName[key] = &existing[i]
+		}
+	}
+
+	var added, updated []codataPlanItem
+	seen := make(map[string]bool)
+	for _, entry := range table.Entries {
+		key := normalizeConstantName(entry.Name)
+		if seen[key] {
+			report.Skipped = append(report.Skipped, models.CODATASkipped{Name: entry.Name, Reason: "listed more than once"})
+			continue
+		}
+		seen[key] = true
+
+		change := models.CODATAChange{Name: entry.Name, Unit: entry.Unit, NewValue: entry.Number, NewExact: entry.Exact}
+		if entry.Uncertainty != 0 {
+			uncertainty := entry.Uncertainty
+			change.NewUncertainty = &uncertainty
+		}
+
+		c := byName[key]
+		if c == nil {
+			change.ConstantID = uuid.New()
+			change.Symbol = codataSymbols[key]
+			added = append(added, codataPlanItem{entry: entry, change: change})
+			report.Added = append(report.Added, change)
+			continue
+		}
+
+		change.ConstantID = c.ID
+		change.Symbol = c.Symbol
+		change.Unit = c.Unit
+		if c.Dimensions != nil && *c.Dimensions != entry.ParsedUnit.Dim {
+			report.Skipped = append(report.Skipped, models.CODATASkipped{
+				Name:   entry.Name,
+				Reason: fmt.Sprintf("unit %s does not match the library's %s", entry.ParsedUnit.Dim, c.Dimensions),
+			})
+			continue
+		}
+		if c.NumericValue != nil && *c.NumericValue == entry.Number && c.Exact == entry.Exact &&
+			equalFloatPtr(c.Uncertainty, change.NewUncertainty) &&
+			c.UnitScale != nil && *c.UnitScale == entry.ParsedUnit.Scale {
+			report.Unchanged++
+			continue
+		}
+		if c.UnitScale == nil || *c.UnitScale != entry.ParsedUnit.Scale {
+			change.Unit = entry.Unit
+		}
+		exact := c.Exact
+		change.OldValue, change.OldUncertainty, change.OldExact = c.NumericValue, c.Uncertainty, &exact
+		updated = append(updated, codataPlanItem{entry: entry, change: change})
+		report.Updated = append(report.Updated, change)
+	}
+
+	if !apply {
+		return report, nil
+	}
+
+	now := time.Now()
+	dataset := models.ConstantDataset{
+		ID:         uuid.New(),
+		Source:     models.DatasetSourceCODATA,
+		Version:    table.Version,
+		Added:      len(report.Added),
+		Updated:    len(report.Updated),
+		Unchanged:  report.Unchanged,
+		ImportedBy: actorID,
+		ImportedAt: now,
+	}
+	_, err = tx.Exec(`
+		INSERT INTO constant_datasets (id, source, version, added, updated, unchanged, imported_by, imported_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`, dataset.ID, dataset.Source, dataset.Version, dataset.Added, dataset.Updated, dataset.Unchanged,
+		dataset.ImportedBy, dataset.ImportedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record dataset: %w", err)
+	}
+
+	description := fmt.Sprintf("%s %s recommended value", models.DatasetSourceCODATA, table.Version)
+	for _, item := range added {
+		e := item.entry
+		_, err := tx.Exec(`
+			INSERT INTO constants (id, name, symbol, value, unit, numeric_value, uncertainty, is_exact, unit_scale, dimensions,
+				description, scope, review_status, dataset_id, created_by, created_at, updated_at)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, 'global', 'approved', $12, $13, $14, $14)
+		`, item.change.ConstantID, e.Name, item.change.Symbol, e.Value, e.Unit, e.Number, item.change.NewUncertainty,
+			e.Exact, e.ParsedUnit.Scale, e.ParsedUnit.Dim, description, dataset.ID, systemID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add constant %q: %w", e.Name, err)
+		}
+		if err := logCODATAChange(tx, actorID, models.ModerationPublished, item.change, table.Version); err != nil {
+			return nil, err
+		}
+	}
+	for _, item := range updated {
+		e := item.entry
+		_, err := tx.Exec(`
+			UPDATE constants
+			SET value = $1, unit = $2, numeric_value = $3, uncertainty = $4, is_exact = $5, unit_scale = $6,
+				dimensions = $7, dataset_id = $8, updated_at = $9
+			WHERE id = $10
+		`, e.Value, item.change.Unit, e.Number, item.change.NewUncertainty, e.Exact, e.ParsedUnit.Scale,
+			e.ParsedUnit.Dim, dataset.ID, now, item.change.ConstantID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update constant %q: %w", e.Name, err)
+		}
+		if err := logCODATAChange(tx, actorID, models.ModerationUpdated, item.change, table.Version); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit import: %w", err)
+	}
+	report.Applied = true
+	report.DatasetID = &dataset.ID
+	return report, nil
+}
+
+func logCODATAChange(tx *sqlx.Tx, actorID *uuid.UUID, action string, change models.CODATAChange, version string) error {
+	event := NewModerationEvent(uuid.Nil, action, models.ModerationConstant, change.ConstantID,
+		models.DatasetSourceCODATA+" "+version, change)
+	event.ActorID = actorID
+	return insertModerationEvent(tx, event)
+}
+
+func (s *Store) GetConstantDatasets() ([]models.ConstantDataset, error) {
+	datasets := []models.ConstantDataset{}
+	query := `SELECT * FROM constant_datasets ORDER BY imported_at DESC`
+	if err := s.db.Select(&datasets, query); err != nil {
+		return nil, fmt.Errorf("failed to get constant datasets: %w", err)
+	}
+	return datasets, nil
+}
+
+func equalFloatPtr(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
@@ -2,47 +2,150 @@ package store
 
 import (
 	"database/sql"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateComment(comment *models.TaskComment) error {
+func (s *Store) CreateComment(comment *models.TaskComment, activity *models.ActivityEvent) error {
 	query := `
-		INSERT INTO task_comments (id, task_id, user_id, content, created_at, updated_at)
-		VALUES ($1, $2, $3, $4, $5, $6)
+		INSERT INTO task_comments (id, task_id, parent_id, user_id, content, content_html, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 	`
-	_, err := s.db.Exec(query, comment.ID, comment.TaskID, comment.UserID,
-		comment.Content, comment.CreatedAt, comment.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create comment: %w", err)
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, comment.ID, comment.TaskID, comment.ParentID, comment.UserID,
+			comment.Content, comment.ContentHTML, comment.CreatedAt, comment.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create comment: %w", err)
+		}
+		return replaceCommentMentions(tx, comment)
+	})
+}
+
+// replaceCommentMentions stores the mentions of a comment, dropping the ones
+// of its previous content.
+func replaceCommentMentions(tx *sqlx.Tx, comment *models.TaskComment) error {
+	if _, err := tx.Exec(`DELETE FROM comment_mentions WHERE comment_id = $1`, comment.ID); err != nil {
+		return fmt.Errorf("failed to clear comment mentions: %w", err)
+	}
+	for _, m := range comment.Mentions {
+		_, err := tx.Exec(`
+			INSERT INTO comment_mentions (comment_id, user_id, start, length)
+			VALUES ($1, $2, $3, $4)
+		`, comment.ID, m.UserID, m.Start, m.Length)
+		if err != nil {
+			return fmt.Errorf("failed to add comment mention: %w", err)
+		}
 	}
 	return nil
 }
 
-func (s *Store) GetCommentsByTaskID(taskID uuid.UUID) ([]models.TaskCommentWithUser, error) {
-	var comments []models.TaskCommentWithUser
+func (s *Store) GetCommentMentions(commentID uuid.UUID) ([]models.CommentMention, error) {
+	mentions := []models.CommentMention{}
+	query := `
+		SELECT m.comment_id, m.user_id, u.name as user_name, m.start, m.length
+		FROM comment_mentions m
+		INNER JOIN users u ON u.id = m.user_id
+		WHERE m.comment_id = $1
+		ORDER BY m.start ASC
+	`
+	if err := s.db.Select(&mentions, query, commentID); err != nil {
+		return nil, fmt.Errorf("failed to get comment mentions: %w", err)
+	}
+	return mentions, nil
+}
+
+// GetCommentsByTaskID returns the top-level comments of a task, oldest
+// first, with their replies nested. Reactions are flagged for viewerID.
+func (s *Store) GetCommentsByTaskID(taskID, viewerID uuid.UUID) ([]models.TaskCommentWithUser, error) {
+	flat, err := s.getTaskComments(taskID, viewerID)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := []models.TaskCommentWithUser{}
+	index := make(map[uuid.UUID]int)
+	for _, c := range flat {
+		if c.ParentID != nil {
+			if i, ok := index[*c.ParentID]; ok {
+				comments[i].Replies = append(comments[i].Replies, c)
+				continue
+			}
+		}
+		index[c.ID] = len(comments)
+		c.Replies = []models.TaskCommentWithUser{}
+		comments = append(comments, c)
+	}
+	return comments, nil
+}
+
+// getTaskComments loads all comments of a task in creation order, together
+// with their mentions and aggregated reactions, in a single query.
+func (s *Store) getTaskComments(taskID, viewerID uuid.UUID) ([]models.TaskCommentWithUser, error) {
+	var rows []struct {
+		models.TaskCommentWithUser
+		MentionsJSON  []byte `db:"mentions_json"`
+		ReactionsJSON []byte `db:"reactions_json"`
+	}
 	query := `
-		SELECT 
+		SELECT
 			c.id,
 			c.task_id,
+			c.parent_id,
 			c.user_id,
 			c.content,
+			c.content_html,
 			c.created_at,
 			c.updated_at,
+			c.deleted_at,
 			u.name as user_name,
-			u.email as user_email
+			u.email as user_email,
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'user_id', m.user_id, 'user_name', mu.name, 'start', m.start, 'length', m.length
+				) ORDER BY m.start)
+				FROM comment_mentions m
+				INNER JOIN users mu ON mu.id = m.user_id
+				WHERE m.comment_id = c.id
+			), '[]') as mentions_json,
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'emoji', r.emoji, 'count', r.count, 'reacted', r.reacted
+				) ORDER BY r.first_at, r.emoji)
+				FROM (
+					SELECT emoji, COUNT(*) as count, BOOL_OR(user_id = $2) as reacted, MIN(created_at) as first_at
+					FROM comment_reactions
+					WHERE comment_id = c.id
+					GROUP BY emoji
+				) r
+			), '[]') as reactions_json
 		FROM task_comments c
 		INNER JOIN users u ON c.user_id = u.id
 		WHERE c.task_id = $1
 		ORDER BY c.created_at ASC
 	`
-	err := s.db.Select(&comments, query, taskID)
-	if err != nil {
+	if err := s.db.Select(&rows, query, taskID, viewerID); err != nil {
 		return nil, fmt.Errorf("failed to get comments: %w", err)
 	}
+
+	comments := make([]models.TaskCommentWithUser, len(rows))
+	for i, row := range rows {
+		comments[i] = row.TaskCommentWithUser
+		if err := json.Unmarshal(row.MentionsJSON, &comments[i].Mentions); err != nil {
+			return nil, fmt.Errorf("failed to decode comment mentions: %w", err)
+		}
+		if err := json.Unmarshal(row.ReactionsJSON, &comments[i].Reactions); err != nil {
+			return nil, fmt.Errorf("failed to decode comment reactions: %w", err)
+		}
+		for j := range comments[i].Mentions {
+			comments[i].Mentions[j].CommentID = comments[i].ID
+		}
+	}
 	return comments, nil
 }
 
@@ -59,34 +162,132 @@ func (s *Store) GetCommentByID(id uuid.UUID) (*models.TaskComment, error) {
 	return &comment, nil
 }
 
-func (s *Store) UpdateComment(comment *models.TaskComment) error {
+func (s *Store) UpdateComment(comment *models.TaskComment, activity *models.ActivityEvent) error {
 	query := `
 		UPDATE task_comments
-		SET content = $1, updated_at = $2
-		WHERE id = $3
+		SET content = $1, content_html = $2, updated_at = $3
+		WHERE id = $4
 	`
-	_, err := s.db.Exec(query, comment.Content, comment.UpdatedAt, comment.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update comment: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, comment.Content, comment.ContentHTML, comment.UpdatedAt, comment.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update comment: %w", err)
+		}
+		return replaceCommentMentions(tx, comment)
+	})
 }
 
-func (s *Store) DeleteComment(id uuid.UUID) error {
-	query := `DELETE FROM task_comments WHERE id = $1`
-	_, err := s.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete comment: %w", err)
+// DeleteComment removes a comment. A comment with replies is blanked into a
+// tombstone instead, and a tombstone goes away with its last reply.
+func (s *Store) DeleteComment(comment *models.TaskComment, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		// Locking the comment keeps replies from being added while it is
+		// deleted, which would cascade them away.
+		if _, err := tx.Exec(`SELECT id FROM task_comments WHERE id = $1 FOR UPDATE`, comment.ID); err != nil {
+			return fmt.Errorf("failed to lock comment: %w", err)
+		}
+
+		var replies int
+		if err := tx.Get(&replies, `SELECT COUNT(*) FROM task_comments WHERE parent_id = $1`, comment.ID); err != nil {
+			return fmt.Errorf("failed to count replies: %w", err)
+		}
+		if replies > 0 {
+			_, err := tx.Exec(`
+				UPDATE task_comments SET content = '', content_html = '', deleted_at = $2 WHERE id = $1
+			`, comment.ID, time.Now())
+			if err != nil {
+				return fmt.Errorf("failed to delete comment: %w", err)
+			}
+			if _, err := tx.Exec(`DELETE FROM comment_mentions WHERE comment_id = $1`, comment.ID); err != nil {
+				return fmt.Errorf("failed to clear comment mentions: %w", err)
+			}
+			if _, err := tx.Exec(`DELETE FROM comment_reactions WHERE comment_id = $1`, comment.ID); err != nil {
+				return fmt.Errorf("failed to clear comment reactions: %w", err)
+			}
+			return nil
+		}
+
+		if _, err := tx.Exec(`DELETE FROM task_comments WHERE id = $1`, comment.ID); err != nil {
+			return fmt.Errorf("failed to delete comment: %w", err)
+		}
+		if comment.ParentID != nil {
+			_, err := tx.Exec(`
+				DELETE FROM task_comments p
+				WHERE p.id = $1 AND p.deleted_at IS NOT NULL
+				  AND NOT EXISTS (SELECT 1 FROM task_comments r WHERE r.parent_id = p.id)
+			`, *comment.ParentID)
+			if err != nil {
+				return fmt.Errorf("failed to delete comment tombstone: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ToggleCommentReaction adds the user's reaction with emoji to a comment, or
+// removes it if it is already there. It reports whether it was added.
+func (s *Store) ToggleCommentReaction(commentID, userID uuid.UUID, emoji string) (bool, error) {
+	added := false
+	err := s.withActivity(nil, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(`
+			DELETE FROM comment_reactions WHERE comment_id = $1 AND user_id = $2 AND emoji = $3
+		`, commentID, userID, emoji)
+		if err != nil {
+			return fmt.Errorf("failed to remove reaction: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n > 0 {
+			return nil
+		}
+		_, err = tx.Exec(`
+			INSERT INTO comment_reactions (comment_id, user_id, emoji, created_at)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT DO NOTHING
+		`, commentID, userID, emoji, time.Now())
+		if err != nil {
+			return fmt.Errorf("failed to add reaction: %w", err)
+		}
+		added = true
+		return nil
+	})
+	return added, err
+}
+
+func (s *Store) GetCommentReactions(commentID, viewerID uuid.UUID) ([]models.CommentReaction, error) {
+	reactions := []models.CommentReaction{}
+	query := `
+		SELECT emoji, COUNT(*) as count, BOOL_OR(user_id = $2) as reacted
+		FROM comment_reactions
+		WHERE comment_id = $1
+		GROUP BY emoji
+		ORDER BY MIN(created_at), emoji
+	`
+	if err := s.db.Select(&reactions, query, commentID, viewerID); err != nil {
+		return nil, fmt.Errorf("failed to get comment reactions: %w", err)
 	}
-	return nil
+	return reactions, nil
 }
 
 func (s *Store) GetCommentCountByTaskID(taskID uuid.UUID) (int, error) {
 	var count int
-	query := `SELECT COUNT(*) FROM task_comments WHERE task_id = $1`
+	query := `SELECT COUNT(*) FROM task_comments WHERE task_id = $1 AND deleted_at IS NULL`
 	err := s.db.Get(&count, query, taskID)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get comment count: %w", err)
 	}
 	return count, nil
 }
+
+func (s *Store) GetCommentsByProject(projectID uuid.UUID) ([]models.TaskComment, error) {
+	var comments []models.TaskComment
+	query := `
+		SELECT c.* FROM task_comments c
+		INNER JOIN tasks t ON t.id = c.task_id
+		WHERE t.project_id = $1
+		ORDER BY c.created_at ASC
+	`
+	err := s.db.Select(&comments, query, projectID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get project comments: %w", err)
+	}
+	return comments, nil
+}
@@ -4,18 +4,29 @@ import (
     "database/sql"
     "errors"
     "fmt"
+    "sort"
+    "strings"
 
     "github.com/google/uuid"
     "github.com/itmo-pride/student-taskboard/backend/internal/models"
+    "github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateConstant(constant *models.Constant) error {
+func (s *Store) CreateConstant(constant *models.Constant, event *models.ModerationEvent) error {
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        return insertConstant(tx, constant)
+    })
+}
+
+func insertConstant(tx *sqlx.Tx, constant *models.Constant) error {
     query := `
-        INSERT INTO constants (id, name, symbol, value, unit, description, scope, scope_id, created_by, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        INSERT INTO constants (id, name, symbol, value, unit, numeric_value, uncertainty, is_exact, unit_scale, dimensions,
+            description, scope, scope_id, review_status, created_by, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
     `
-    _, err := s.db.Exec(query, constant.ID, constant.Name, constant.Symbol, constant.Value,
-        constant.Unit, constant.Description, constant.Scope, constant.ScopeID, 
+    _, err := tx.Exec(query, constant.ID, constant.Name, constant.Symbol, constant.Value, constant.Unit,
+        constant.NumericValue, constant.Uncertainty, constant.Exact, constant.UnitScale, constant.Dimensions,
+        constant.Description, constant.Scope, constant.ScopeID, constant.ReviewStatus,
         constant.CreatedBy, constant.CreatedAt, constant.UpdatedAt)
     if err != nil {
         return fmt.Errorf("failed to create constant: %w", err)
@@ -23,24 +34,77 @@ func (s *Store) CreateConstant(constant *models.Constant) error {
     return nil
 }
 
+// GetConstants lists the constants a user can see: approved global ones, their own
+// and, when projectID is set and they are a member, the project's. A
+// non-empty scope keeps only that layer.
 func (s *Store) GetConstants(userID uuid.UUID, projectID *uuid.UUID, scope string) ([]models.Constant, error) {
-    var constants []models.Constant
-    
+    constants := []models.Constant{}
+
     query := `
-        SELECT * FROM constants 
-        WHERE (scope = 'global') 
-           OR (scope = 'user' AND created_by = $1)
-           OR (scope = 'project' AND scope_id = $2)
-        ORDER BY created_at DESC
+        SELECT c.* FROM constants c
+        WHERE ($3 = '' OR c.scope = $3)
+          AND ((c.scope = 'global' AND c.review_status = 'approved')
+               OR (c.scope = 'user' AND c.created_by = $1)
+               OR (c.scope = 'project' AND c.scope_id = $2 AND EXISTS (
+                   SELECT 1 FROM project_members pm
+                   WHERE pm.project_id = c.scope_id AND pm.user_id = $1
+               )))
+        ORDER BY c.created_at DESC
     `
-    
-    err := s.db.Select(&constants, query, userID, projectID)
+
+    err := s.db.Select(&constants, query, userID, projectID, scope)
     if err != nil {
         return nil, fmt.Errorf("failed to get constants: %w", err)
     }
     return constants, nil
 }
 
+// GetEffectiveConstants merges the global, project and user layers into one
+// constant per symbol, preferring the most specific scope.
+func (s *Store) GetEffectiveConstants(userID uuid.UUID, projectID *uuid.UUID) ([]models.Constant, error) {
+    constants, err := s.GetConstants(userID, projectID, "")
+    if err != nil {
+        return nil, err
+    }
+    return mergeConstantLayers(constants), nil
+}
+
+// mergeConstantLayers keeps one constant per symbol: the one from the most
+// specific scope, and within a scope the most recently updated. The others
+// are recorded in the winner's Overrides. The result is sorted by symbol.
+func mergeConstantLayers(constants []models.Constant) []models.Constant {
+    bySymbol := make(map[string][]models.Constant)
+    for _, c := range constants {
+        symbol := strings.TrimSpace(c.Symbol)
+        bySymbol[symbol] = append(bySymbol[symbol], c)
+    }
+
+    merged := make([]models.Constant, 0, len(bySymbol))
+    for _, group := range bySymbol {
+        sort.SliceStable(group, func(i, j int) bool {
+            pi, pj := models.ConstantScopePrecedence[group[i].Scope], models.ConstantScopePrecedence[group[j].Scope]
+            if pi != pj {
+                return pi > pj
+            }
+            if !group[i].UpdatedAt.Equal(group[j].UpdatedAt) {
+                return group[i].UpdatedAt.After(group[j].UpdatedAt)
+            }
+            return group[i].ID.String() < group[j].ID.String()
+        })
+        winner := group[0]
+        winner.Overrides = nil
+        for _, hidden := range group[1:] {
+            winner.Overrides = append(winner.Overrides, hidden.ID)
+        }
+        merged = append(merged, winner)
+    }
+
+    sort.Slice(merged, func(i, j int) bool {
+        return merged[i].Symbol < merged[j].Symbol
+    })
+    return merged
+}
+
 func (s *Store) GetConstantByID(id uuid.UUID) (*models.Constant, error) {
     var constant models.Constant
     query := `SELECT * FROM constants WHERE id = $1`
@@ -54,25 +118,50 @@ func (s *Store) GetConstantByID(id uuid.UUID) (*models.Constant, error) {
     return &constant, nil
 }
 
-func (s *Store) UpdateConstant(constant *models.Constant) error {
+func (s *Store) UpdateConstant(constant *models.Constant, event *models.ModerationEvent) error {
     query := `
         UPDATE constants
-        SET name = $1, symbol = $2, value = $3, unit = $4, description = $5, updated_at = $6
-        WHERE id = $7
+        SET name = $1, symbol = $2, value = $3, unit = $4, numeric_value = $5, uncertainty = $6, is_exact = $7,
+            unit_scale = $8, dimensions = $9, description = $10, review_status = $11, updated_at = $12
+        WHERE id = $13
     `
-    _, err := s.db.Exec(query, constant.Name, constant.Symbol, constant.Value,
-        constant.Unit, constant.Description, constant.UpdatedAt, constant.ID)
-    if err != nil {
-        return fmt.Errorf("failed to update constant: %w", err)
-    }
-    return nil
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, constant.Name, constant.Symbol, constant.Value, constant.Unit,
+            constant.NumericValue, constant.Uncertainty, constant.Exact, constant.UnitScale, constant.Dimensions,
+            constant.Description, constant.ReviewStatus, constant.UpdatedAt, constant.ID)
+        if err != nil {
+            return fmt.Errorf("failed to update constant: %w", err)
+        }
+        return nil
+    })
 }
 
-func (s *Store) DeleteConstant(id uuid.UUID) error {
+func (s *Store) DeleteConstant(id uuid.UUID, event *models.ModerationEvent) error {
     query := `DELETE FROM constants WHERE id = $1`
-    _, err := s.db.Exec(query, id)
-    if err != nil {
-        return fmt.Errorf("failed to delete constant: %w", err)
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, id)
+        if err != nil {
+            return fmt.Errorf("failed to delete constant: %w", err)
+        }
+        return nil
+    })
+}
+
+// GetPendingConstants lists proposed global constants, oldest first.
+func (s *Store) GetPendingConstants() ([]models.Constant, error) {
+    constants := []models.Constant{}
+    query := `SELECT * FROM constants WHERE review_status = 'pending' ORDER BY updated_at ASC`
+    if err := s.db.Select(&constants, query); err != nil {
+        return nil, fmt.Errorf("failed to get pending constants: %w", err)
     }
-    return nil
+    return constants, nil
+}
+
+func (s *Store) GetConstantsByProject(projectID uuid.UUID) ([]models.Constant, error) {
+    var constants []models.Constant
+    query := `SELECT * FROM constants WHERE scope = 'project' AND scope_id = $1 ORDER BY created_at ASC`
+    if err := s.db.Select(&constants, query, projectID); err != nil {
+        return nil, fmt.Errorf("failed to get project constants: %w", err)
+    }
+    return constants, nil
 }
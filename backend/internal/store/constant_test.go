@@ -0,0 +1,203 @@
+package store
+
+import (
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+func TestMergeConstantLayers(t *testing.T) {
+	now := time.Now()
+	global := models.Constant{ID: uuid.New(), Symbol: "g", Value: "9.81", Scope: models.ConstantScopeGlobal, UpdatedAt: now}
+	project := models.Constant{ID: uuid.New(), Symbol: "g", Value: "9.8", Scope: models.ConstantScopeProject, UpdatedAt: now.Add(-time.Hour)}
+	user := models.Constant{ID: uuid.New(), Symbol: "g", Value: "10", Scope: models.ConstantScopeUser, UpdatedAt: now.Add(-2 * time.Hour)}
+	olderUser := models.Constant{ID: uuid.New(), Symbol: "g", Value: "9", Scope: models.ConstantScopeUser, UpdatedAt: now.Add(-3 * time.Hour)}
+	other := models.Constant{ID: uuid.New(), Symbol: "c", Value: "299792458", Scope: models.ConstantScopeGlobal, UpdatedAt: now}
+
+	tests := []struct {
+		name       string
+		in         []models.Constant
+		wantValues map[string]string
+		overrides  int
+	}{
+		{"global only", []models.Constant{global, other}, map[string]string{"g": "9.81", "c": "299792458"}, 0},
+		{"project over global", []models.Constant{global, project}, map[string]string{"g": "9.8"}, 1},
+		{"user over project and global", []models.Constant{project, global, user}, map[string]string{"g": "10"}, 2},
+		{"newest within a scope", []models.Constant{olderUser, user}, map[string]string{"g": "10"}, 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			merged := mergeConstantLayers(tt.in)
+			if len(merged) != len(tt.wantValues) {
+				t.Fatalf("got %d constants, want %d", len(merged), len(tt.wantValues))
+			}
+			for _, c := range merged {
+				if want := tt.wantValues[c.Symbol]; c.Value != want {
+					t.Errorf("%s = %s, want %s", c.Symbol, c.Value, want)
+				}
+				if c.Symbol == "g" && len(c.Overrides) != tt.overrides {
+					t.Errorf("g overrides %d constants, want %d", len(c.Overrides), tt.overrides)
+				}
+			}
+		})
+	}
+}
+
+func TestGetConstantsScopes(t *testing.T) {
+	s := newTestStore(t)
+
+	alice := createTestUser(t, s, "alice@example.com")
+	bob := createTestUser(t, s, "bob@example.com")
+	aliceProject := createTestProject(t, s, alice)
+	bobProject := createTestProject(t, s, bob)
+
+	globalG := createTestConstant(t, s, alice, "g", "9.81", models.ConstantScopeGlobal, nil)
+	projectG := createTestConstant(t, s, alice, "g", "9.8", models.ConstantScopeProject, &aliceProject)
+	aliceG := createTestConstant(t, s, alice, "g", "10", models.ConstantScopeUser, nil)
+	bobSecret := createTestConstant(t, s, bob, "k", "42", models.ConstantScopeProject, &bobProject)
+	bobOwn := createTestConstant(t, s, bob, "m", "1", models.ConstantScopeUser, nil)
+
+	t.Run("scope filter keeps one layer", func(t *testing.T) {
+		for scope, want := range map[string]uuid.UUID{
+			models.ConstantScopeProject: projectG,
+			models.ConstantScopeUser:    aliceG,
+		} {
+			got, err := s.GetConstants(alice, &aliceProject, scope)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ids := constantIDs(got); len(ids) != 1 || !ids[want] {
+				t.Errorf("scope %s returned %v, want only %s", scope, ids, want)
+			}
+		}
+
+		got, err := s.GetConstants(alice, &aliceProject, models.ConstantScopeGlobal)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range got {
+			if c.Scope != models.ConstantScopeGlobal {
+				t.Errorf("global scope returned %s constant %s", c.Scope, c.ID)
+			}
+		}
+		if !constantIDs(got)[globalG] {
+			t.Errorf("global scope is missing %s", globalG)
+		}
+	})
+
+	t.Run("other projects and users stay private", func(t *testing.T) {
+		got, err := s.GetConstants(alice, &bobProject, "")
+		if err != nil {
+			t.Fatal(err)
+		}
+		ids := constantIDs(got)
+		if ids[bobSecret] {
+			t.Error("non-member sees another project's constants")
+		}
+		if ids[bobOwn] {
+			t.Error("user sees another user's constants")
+		}
+	})
+
+	t.Run("merged listing prefers the most specific scope", func(t *testing.T) {
+		got, err := s.GetEffectiveConstants(alice, &aliceProject)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var g *models.Constant
+		for i := range got {
+			if got[i].Symbol == "g" {
+				if g != nil {
+					t.Fatal("symbol g listed twice")
+				}
+				g = &got[i]
+			}
+		}
+		if g == nil || g.ID != aliceG {
+			t.Fatalf("g resolved to %v, want the user constant %s", g, aliceG)
+		}
+		if overridden := uuidSet(g.Overrides); !overridden[projectG] || !overridden[globalG] {
+			t.Errorf("g overrides %v, want %s and %s", g.Overrides, projectG, globalG)
+		}
+
+		got, err = s.GetEffectiveConstants(bob, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, c := range got {
+			if c.Symbol == "g" && c.ID != globalG {
+				t.Errorf("without the project bob should get the global g, got %s", c.ID)
+			}
+		}
+	})
+}
+
+func createTestUser(t *testing.T, s *Store, email string) uuid.UUID {
+	t.Helper()
+	user := &models.User{
+		ID:        uuid.New(),
+		Email:     email,
+		Password:  "x",
+		Name:      email,
+		Timezone:  models.DefaultTimezone,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.CreateUser(user); err != nil {
+		t.Fatal(err)
+	}
+	return user.ID
+}
+
+func createTestProject(t *testing.T, s *Store, ownerID uuid.UUID) uuid.UUID {
+	t.Helper()
+	project := &models.Project{
+		ID:        uuid.New(),
+		Name:      "project",
+		OwnerID:   ownerID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+	if err := s.CreateProject(project); err != nil {
+		t.Fatal(err)
+	}
+	return project.ID
+}
+
+func createTestConstant(t *testing.T, s *Store, userID uuid.UUID, symbol, value, scope string, scopeID *uuid.UUID) uuid.UUID {
+	t.Helper()
+	constant := &models.Constant{
+		ID:        uuid.New(),
+		Name:      symbol,
+		Symbol:    symbol,
+		Value:     value,
+		Scope:     scope,
+		ScopeID:   scopeID,
+		CreatedBy: userID,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+		Review:    models.Review{ReviewStatus: models.ReviewApproved},
+	}
+	if err := s.CreateConstant(constant, nil); err != nil {
+		t.Fatal(err)
+	}
+	return constant.ID
+}
+
+func constantIDs(constants []models.Constant) map[uuid.UUID]bool {
+	ids := make(map[uuid.UUID]bool, len(constants))
+	for _, c := range constants {
+		ids[c.ID] = true
+	}
+	return ids
+}
+
+func uuidSet(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
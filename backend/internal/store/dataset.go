@@ -0,0 +1,466 @@
+package store
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+const datasetSelect = `
+	SELECT d.*, (SELECT COUNT(*) FROM dataset_rows r WHERE r.dataset_id = d.id) AS row_count
+	FROM datasets d
+`
+
+// appendDatasetColumn inserts a column after the last one of its dataset.
+const appendDatasetColumn = `
+	INSERT INTO dataset_columns (id, dataset_id, name, kind, unit, uncertainty, formula_id, position)
+	SELECT $1, $2, $3, $4, $5, $6, $7, COALESCE(MAX(position), -1) + 1
+	FROM dataset_columns WHERE dataset_id = $2
+	RETURNING position
+`
+
+// CreateDataset saves a dataset with its columns and rows, and with the
+// file it was imported from when attachment is set.
+func (s *Store) CreateDataset(dataset *models.Dataset, rows []models.DatasetRow, attachment *models.Attachment, activity *models.ActivityEvent) error {
+	query := `
+		INSERT INTO datasets (id, project_id, name, description, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+	`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, dataset.ID, dataset.ProjectID, dataset.Name, dataset.Description,
+			dataset.CreatedBy, dataset.CreatedAt, dataset.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create dataset: %w", err)
+		}
+		for _, col := range dataset.Columns {
+			if err := insertDatasetColumn(tx, &col); err != nil {
+				return err
+			}
+		}
+		for _, row := range rows {
+			_, err := tx.Exec(`
+				INSERT INTO dataset_rows (id, dataset_id, position, cells, created_at, updated_at)
+				VALUES ($1, $2, $3, $4, $5, $6)
+			`, row.ID, row.DatasetID, row.Position, row.Cells, row.CreatedAt, row.UpdatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to create dataset row: %w", err)
+			}
+		}
+		if attachment != nil {
+			_, err := tx.Exec(`
+				INSERT INTO attachments (id, filename, original_name, file_path, file_size, mime_type, entity_type, entity_id, uploaded_by, created_at)
+				VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+			`, attachment.ID, attachment.Filename, attachment.OriginalName, attachment.FilePath,
+				attachment.FileSize, attachment.MimeType, attachment.EntityType, attachment.EntityID,
+				attachment.UploadedBy, attachment.CreatedAt)
+			if err != nil {
+				return fmt.Errorf("failed to create attachment: %w", err)
+			}
+		}
+		dataset.RowCount = len(rows)
+		return nil
+	})
+}
+
+func insertDatasetColumn(tx *sqlx.Tx, col *models.DatasetColumn) error {
+	query := `
+		INSERT INTO dataset_columns (id, dataset_id, name, kind, unit, uncertainty, formula_id, position)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := tx.Exec(query, col.ID, col.DatasetID, col.Name, col.Kind, col.Unit, col.Uncertainty, col.FormulaID, col.Position)
+	if err != nil {
+		return fmt.Errorf("failed to create dataset column: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) GetDatasetsByProject(projectID uuid.UUID) ([]models.Dataset, error) {
+	datasets := []models.Dataset{}
+	query := datasetSelect + `WHERE d.project_id = $1 ORDER BY d.name ASC, d.created_at ASC`
+	if err := s.db.Select(&datasets, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get datasets: %w", err)
+	}
+	return datasets, nil
+}
+
+func (s *Store) GetDatasetsByTask(taskID uuid.UUID) ([]models.Dataset, error) {
+	datasets := []models.Dataset{}
+	query := datasetSelect + `
+		INNER JOIN dataset_tasks dt ON dt.dataset_id = d.id
+		WHERE dt.task_id = $1
+		ORDER BY d.name ASC
+	`
+	if err := s.db.Select(&datasets, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task datasets: %w", err)
+	}
+	return datasets, nil
+}
+
+func (s *Store) GetDatasetByID(id uuid.UUID) (*models.Dataset, error) {
+	var dataset models.Dataset
+	err := s.db.Get(&dataset, datasetSelect+`WHERE d.id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dataset: %w", err)
+	}
+	return &dataset, nil
+}
+
+// LoadDatasetDetails fills in the columns of a dataset and the tasks and
+// formulas linked to it.
+func (s *Store) LoadDatasetDetails(dataset *models.Dataset) error {
+	columns, err := s.GetDatasetColumns(dataset.ID)
+	if err != nil {
+		return err
+	}
+	dataset.Columns = columns
+
+	dataset.TaskIDs = []uuid.UUID{}
+	query := `SELECT task_id FROM dataset_tasks WHERE dataset_id = $1 ORDER BY created_at ASC`
+	if err := s.db.Select(&dataset.TaskIDs, query, dataset.ID); err != nil {
+		return fmt.Errorf("failed to get dataset tasks: %w", err)
+	}
+	dataset.FormulaIDs = []uuid.UUID{}
+	query = `SELECT formula_id FROM dataset_formulas WHERE dataset_id = $1 ORDER BY created_at ASC`
+	if err := s.db.Select(&dataset.FormulaIDs, query, dataset.ID); err != nil {
+		return fmt.Errorf("failed to get dataset formulas: %w", err)
+	}
+	return nil
+}
+
+func (s *Store) UpdateDataset(dataset *models.Dataset, activity *models.ActivityEvent) error {
+	query := `UPDATE datasets SET name = $1, description = $2, updated_at = $3 WHERE id = $4`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, dataset.Name, dataset.Description, dataset.UpdatedAt, dataset.ID); err != nil {
+			return fmt.Errorf("failed to update dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteDataset(id uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM datasets WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetDatasetColumns(datasetID uuid.UUID) ([]models.DatasetColumn, error) {
+	columns := []models.DatasetColumn{}
+	query := `SELECT * FROM dataset_columns WHERE dataset_id = $1 ORDER BY position ASC, name ASC`
+	if err := s.db.Select(&columns, query, datasetID); err != nil {
+		return nil, fmt.Errorf("failed to get dataset columns: %w", err)
+	}
+	return columns, nil
+}
+
+func (s *Store) GetDatasetColumnByID(id uuid.UUID) (*models.DatasetColumn, error) {
+	var col models.DatasetColumn
+	err := s.db.Get(&col, `SELECT * FROM dataset_columns WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dataset column: %w", err)
+	}
+	return &col, nil
+}
+
+// CreateDatasetColumn appends a column to its dataset.
+func (s *Store) CreateDatasetColumn(col *models.DatasetColumn, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		err := tx.Get(&col.Position, appendDatasetColumn, col.ID, col.DatasetID, col.Name, col.Kind, col.Unit, col.Uncertainty, col.FormulaID)
+		if err != nil {
+			return fmt.Errorf("failed to create dataset column: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateDatasetColumn saves a column, renumbering the other columns when
+// its position changed, and the cells of rows whose values were converted
+// to a new unit.
+func (s *Store) UpdateDatasetColumn(col *models.DatasetColumn, rows []models.DatasetRow, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		var ids []uuid.UUID
+		err := tx.Select(&ids, `
+			SELECT id FROM dataset_columns
+			WHERE dataset_id = $1 AND id != $2
+			ORDER BY position ASC, name ASC
+			FOR UPDATE
+		`, col.DatasetID, col.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock dataset columns: %w", err)
+		}
+
+		if col.Position > len(ids) {
+			col.Position = len(ids)
+		}
+		ordered := make([]uuid.UUID, 0, len(ids)+1)
+		ordered = append(ordered, ids[:col.Position]...)
+		ordered = append(ordered, col.ID)
+		ordered = append(ordered, ids[col.Position:]...)
+
+		for pos, id := range ordered {
+			if id == col.ID {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE dataset_columns SET position = $1 WHERE id = $2`, pos, id); err != nil {
+				return fmt.Errorf("failed to reorder dataset columns: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(`
+			UPDATE dataset_columns SET name = $1, unit = $2, uncertainty = $3, position = $4
+			WHERE id = $5
+		`, col.Name, col.Unit, col.Uncertainty, col.Position, col.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update dataset column: %w", err)
+		}
+
+		for _, row := range rows {
+			if _, err := tx.Exec(`UPDATE dataset_rows SET cells = $1 WHERE id = $2`, row.Cells, row.ID); err != nil {
+				return fmt.Errorf("failed to update dataset row: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// DeleteDatasetColumn removes a column and its cells.
+func (s *Store) DeleteDatasetColumn(col *models.DatasetColumn, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM dataset_columns WHERE id = $1`, col.ID); err != nil {
+			return fmt.Errorf("failed to delete dataset column: %w", err)
+		}
+		_, err := tx.Exec(`UPDATE dataset_rows SET cells = cells - $1::text WHERE dataset_id = $2`, col.ID.String(), col.DatasetID)
+		if err != nil {
+			return fmt.Errorf("failed to delete dataset column: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveDerivedColumn creates or updates a column evaluated with a formula
+// and writes its cells, clearing it in rows missing from cells. The
+// formula is linked to the dataset.
+func (s *Store) SaveDerivedColumn(col *models.DatasetColumn, cells map[uuid.UUID]models.DatasetCell, create bool, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if create {
+			err := tx.Get(&col.Position, appendDatasetColumn, col.ID, col.DatasetID, col.Name, col.Kind,
+				col.Unit, col.Uncertainty, col.FormulaID)
+			if err != nil {
+				return fmt.Errorf("failed to create dataset column: %w", err)
+			}
+		} else {
+			_, err := tx.Exec(`
+				UPDATE dataset_columns SET kind = $1, unit = $2, uncertainty = $3, formula_id = $4
+				WHERE id = $5
+			`, col.Kind, col.Unit, col.Uncertainty, col.FormulaID, col.ID)
+			if err != nil {
+				return fmt.Errorf("failed to update dataset column: %w", err)
+			}
+		}
+
+		key := col.ID.String()
+		_, err := tx.Exec(`UPDATE dataset_rows SET cells = cells - $1::text WHERE dataset_id = $2`, key, col.DatasetID)
+		if err != nil {
+			return fmt.Errorf("failed to clear dataset column: %w", err)
+		}
+		for rowID, cell := range cells {
+			data, err := json.Marshal(cell)
+			if err != nil {
+				return fmt.Errorf("failed to encode dataset cell: %w", err)
+			}
+			_, err = tx.Exec(`
+				UPDATE dataset_rows SET cells = cells || jsonb_build_object($1::text, $2::jsonb)
+				WHERE id = $3
+			`, key, string(data), rowID)
+			if err != nil {
+				return fmt.Errorf("failed to update dataset row: %w", err)
+			}
+		}
+
+		if col.FormulaID != nil {
+			_, err := tx.Exec(`
+				INSERT INTO dataset_formulas (dataset_id, formula_id, created_at)
+				VALUES ($1, $2, NOW())
+				ON CONFLICT (dataset_id, formula_id) DO NOTHING
+			`, col.DatasetID, *col.FormulaID)
+			if err != nil {
+				return fmt.Errorf("failed to link formula to dataset: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetDatasetRows(datasetID uuid.UUID) ([]models.DatasetRow, error) {
+	rows := []models.DatasetRow{}
+	query := `SELECT * FROM dataset_rows WHERE dataset_id = $1 ORDER BY position ASC, created_at ASC`
+	if err := s.db.Select(&rows, query, datasetID); err != nil {
+		return nil, fmt.Errorf("failed to get dataset rows: %w", err)
+	}
+	return rows, nil
+}
+
+func (s *Store) GetDatasetRowByID(id uuid.UUID) (*models.DatasetRow, error) {
+	var row models.DatasetRow
+	err := s.db.Get(&row, `SELECT * FROM dataset_rows WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get dataset row: %w", err)
+	}
+	return &row, nil
+}
+
+// CreateDatasetRow appends a row to its dataset.
+func (s *Store) CreateDatasetRow(row *models.DatasetRow, activity *models.ActivityEvent) error {
+	query := `
+		INSERT INTO dataset_rows (id, dataset_id, position, cells, created_at, updated_at)
+		SELECT $1, $2, COALESCE(MAX(position), -1) + 1, $3, $4, $5
+		FROM dataset_rows WHERE dataset_id = $2
+		RETURNING position
+	`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		err := tx.Get(&row.Position, query, row.ID, row.DatasetID, row.Cells, row.CreatedAt, row.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create dataset row: %w", err)
+		}
+		return nil
+	})
+}
+
+// UpdateDatasetRow saves a row; when its position changed the other rows of
+// the dataset are renumbered around it.
+func (s *Store) UpdateDatasetRow(row *models.DatasetRow, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		var ids []uuid.UUID
+		err := tx.Select(&ids, `
+			SELECT id FROM dataset_rows
+			WHERE dataset_id = $1 AND id != $2
+			ORDER BY position ASC, created_at ASC
+			FOR UPDATE
+		`, row.DatasetID, row.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock dataset rows: %w", err)
+		}
+
+		if row.Position > len(ids) {
+			row.Position = len(ids)
+		}
+		ordered := make([]uuid.UUID, 0, len(ids)+1)
+		ordered = append(ordered, ids[:row.Position]...)
+		ordered = append(ordered, row.ID)
+		ordered = append(ordered, ids[row.Position:]...)
+
+		for pos, id := range ordered {
+			if id == row.ID {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE dataset_rows SET position = $1 WHERE id = $2 AND position != $1`, pos, id); err != nil {
+				return fmt.Errorf("failed to reorder dataset rows: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(`
+			UPDATE dataset_rows SET cells = $1, position = $2, updated_at = $3
+			WHERE id = $4
+		`, row.Cells, row.Position, row.UpdatedAt, row.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update dataset row: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteDatasetRow(id uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM dataset_rows WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete dataset row: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) AddDatasetTask(datasetID, taskID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `
+		INSERT INTO dataset_tasks (dataset_id, task_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (dataset_id, task_id) DO NOTHING
+	`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, datasetID, taskID); err != nil {
+			return fmt.Errorf("failed to link task to dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) RemoveDatasetTask(datasetID, taskID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `DELETE FROM dataset_tasks WHERE dataset_id = $1 AND task_id = $2`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, datasetID, taskID); err != nil {
+			return fmt.Errorf("failed to unlink task from dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) AddDatasetFormula(datasetID, formulaID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `
+		INSERT INTO dataset_formulas (dataset_id, formula_id, created_at)
+		VALUES ($1, $2, NOW())
+		ON CONFLICT (dataset_id, formula_id) DO NOTHING
+	`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, datasetID, formulaID); err != nil {
+			return fmt.Errorf("failed to link formula to dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) RemoveDatasetFormula(datasetID, formulaID uuid.UUID, activity *models.ActivityEvent) error {
+	query := `DELETE FROM dataset_formulas WHERE dataset_id = $1 AND formula_id = $2`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, datasetID, formulaID); err != nil {
+			return fmt.Errorf("failed to unlink formula from dataset: %w", err)
+		}
+		return nil
+	})
+}
+
+// SaveDatasetFit creates the constants holding fitted parameters and the
+// formula that uses them, and links the formula to the dataset.
+func (s *Store) SaveDatasetFit(datasetID uuid.UUID, formula *models.Formula, constants []models.Constant, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		for i := range constants {
+			if err := insertConstant(tx, &constants[i]); err != nil {
+				return err
+			}
+		}
+		if err := insertFormula(tx, formula); err != nil {
+			return err
+		}
+		_, err := tx.Exec(`
+			INSERT INTO dataset_formulas (dataset_id, formula_id, created_at)
+			VALUES ($1, $2, NOW())
+		`, datasetID, formula.ID)
+		if err != nil {
+			return fmt.Errorf("failed to link formula to dataset: %w", err)
+		}
+		return nil
+	})
+}
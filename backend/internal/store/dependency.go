@@ -0,0 +1,196 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrDependencyCycle    = errors.New("dependency would create a cycle")
+	ErrDependencyNotFound = errors.New("dependency not found")
+)
+
+func getDependenciesByProject(q sqlx.Queryer, projectID uuid.UUID) ([]models.TaskDependency, error) {
+	var deps []models.TaskDependency
+	query := `
+        SELECT d.* FROM task_dependencies d
+        INNER JOIN tasks t ON t.id = d.blocked_id
+        WHERE t.project_id = $1
+        ORDER BY d.created_at ASC
+    `
+	if err := sqlx.Select(q, &deps, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get task dependencies: %w", err)
+	}
+	return deps, nil
+}
+
+func (s *Store) GetDependenciesByProject(projectID uuid.UUID) ([]models.TaskDependency, error) {
+	return getDependenciesByProject(s.db, projectID)
+}
+
+// AddTaskDependency links two tasks of the same project, rejecting links that
+// would make the dependency graph cyclic.
+func (s *Store) AddTaskDependency(projectID uuid.UUID, dep *models.TaskDependency, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := lockProject(tx, projectID); err != nil {
+			return err
+		}
+
+		edges, err := getDependenciesByProject(tx, projectID)
+		if err != nil {
+			return err
+		}
+		if services.DependencyCreatesCycle(edges, dep.BlockerID, dep.BlockedID) {
+			return ErrDependencyCycle
+		}
+
+		_, err = tx.Exec(`
+            INSERT INTO task_dependencies (blocker_id, blocked_id, created_by, created_at)
+            VALUES ($1, $2, $3, $4)
+            ON CONFLICT DO NOTHING
+        `, dep.BlockerID, dep.BlockedID, dep.CreatedBy, dep.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add task dependency: %w", err)
+		}
+		return nil
+	})
+}
+
+// RemoveTaskDependency removes the link between two tasks in whichever
+// direction it exists.
+func (s *Store) RemoveTaskDependency(taskID, otherID uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(`
+            DELETE FROM task_dependencies
+            WHERE (blocker_id = $1 AND blocked_id = $2) OR (blocker_id = $2 AND blocked_id = $1)
+        `, taskID, otherID)
+		if err != nil {
+			return fmt.Errorf("failed to remove task dependency: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return ErrDependencyNotFound
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetTaskDependencies(taskID uuid.UUID) (*models.TaskDependencies, error) {
+	deps := &models.TaskDependencies{BlockedBy: []models.Task{}, Blocks: []models.Task{}}
+
+	err := s.db.Select(&deps.BlockedBy, `
+        SELECT t.* FROM tasks t
+        INNER JOIN task_dependencies d ON d.blocker_id = t.id
+        WHERE d.blocked_id = $1
+        ORDER BY t.rank COLLATE "C", t.created_at DESC
+    `, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocking tasks: %w", err)
+	}
+
+	err = s.db.Select(&deps.Blocks, `
+        SELECT t.* FROM tasks t
+        INNER JOIN task_dependencies d ON d.blocked_id = t.id
+        WHERE d.blocker_id = $1
+        ORDER BY t.rank COLLATE "C", t.created_at DESC
+    `, taskID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+
+	refs := make([]*models.Task, 0, len(deps.BlockedBy)+len(deps.Blocks))
+	for i := range deps.BlockedBy {
+		refs = append(refs, &deps.BlockedBy[i])
+	}
+	for i := range deps.Blocks {
+		refs = append(refs, &deps.Blocks[i])
+	}
+	if err := s.AttachTaskDetails(refs...); err != nil {
+		return nil, err
+	}
+	return deps, nil
+}
+
+func (s *Store) CountOpenBlockers(taskID uuid.UUID) (int, error) {
+	var count int
+	query := `
+        SELECT COUNT(*) FROM task_dependencies d
+        INNER JOIN tasks t ON t.id = d.blocker_id
+        WHERE d.blocked_id = $1 AND NOT ` + taskDoneCondition("t")
+	if err := s.db.Get(&count, query, taskID); err != nil {
+		return 0, fmt.Errorf("failed to count open blockers: %w", err)
+	}
+	return count, nil
+}
+
+func (s *Store) attachTaskBlocked(tasks []*models.Task, ids []string) error {
+	var blocked []uuid.UUID
+	err := s.db.Select(&blocked, `
+        SELECT DISTINCT d.blocked_id FROM task_dependencies d
+        INNER JOIN tasks t ON t.id = d.blocker_id
+        WHERE d.blocked_id = ANY($1::uuid[]) AND NOT `+taskDoneCondition("t"),
+		pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get blocked tasks: %w", err)
+	}
+
+	isBlocked := make(map[uuid.UUID]bool, len(blocked))
+	for _, id := range blocked {
+		isBlocked[id] = true
+	}
+	for _, t := range tasks {
+		t.Blocked = isBlocked[t.ID]
+	}
+	return nil
+}
+
+// GetTaskGraph returns all tasks of the project with their dependency links,
+// annotated with earliest finish dates and the critical path.
+func (s *Store) GetTaskGraph(projectID uuid.UUID) (*models.TaskGraph, error) {
+	tasks, err := s.GetTasksByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	edges, err := s.GetDependenciesByProject(projectID)
+	if err != nil {
+		return nil, err
+	}
+	workflow, err := s.GetWorkflow(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make([]*models.Task, len(tasks))
+	for i := range tasks {
+		refs[i] = &tasks[i]
+	}
+	if err := s.AttachTaskDetails(refs...); err != nil {
+		return nil, err
+	}
+
+	graph := &models.TaskGraph{
+		Nodes: make([]models.TaskGraphNode, len(tasks)),
+		Edges: edges,
+	}
+	if graph.Edges == nil {
+		graph.Edges = []models.TaskDependency{}
+	}
+	for i, t := range tasks {
+		graph.Nodes[i] = models.TaskGraphNode{
+			ID:      t.ID,
+			Title:   t.Title,
+			Status:  t.Status,
+			DueDate: t.DueDate,
+			Done:    workflow.IsDone(t.Status),
+			Blocked: t.Blocked,
+		}
+	}
+
+	services.AnalyzeTaskGraph(graph)
+	return graph, nil
+}
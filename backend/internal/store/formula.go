@@ -7,29 +7,41 @@ import (
 
     "github.com/google/uuid"
     "github.com/itmo-pride/student-taskboard/backend/internal/models"
+    "github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateFormula(formula *models.Formula) error {
+func (s *Store) CreateFormula(formula *models.Formula, event *models.ModerationEvent) error {
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        return insertFormula(tx, formula)
+    })
+}
+
+func insertFormula(tx *sqlx.Tx, formula *models.Formula) error {
     query := `
-        INSERT INTO formulas (id, title, latex, description, project_id, created_by, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+        INSERT INTO formulas (id, title, latex, description, project_id, is_global, review_status, created_by, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
     `
-    _, err := s.db.Exec(query, formula.ID, formula.Title, formula.Latex, 
-        formula.Description, formula.ProjectID, formula.CreatedBy, formula.CreatedAt, formula.UpdatedAt)
+    _, err := tx.Exec(query, formula.ID, formula.Title, formula.Latex, formula.Description,
+        formula.ProjectID, formula.Global, formula.ReviewStatus, formula.CreatedBy, formula.CreatedAt, formula.UpdatedAt)
     if err != nil {
         return fmt.Errorf("failed to create formula: %w", err)
     }
-    return nil
+    return replaceFormulaVariables(tx, formula.ID, formula.Variables)
 }
 
+// GetFormulas lists the user's own formulas, approved global ones and, when
+// projectID is set and they are a member, the project's.
 func (s *Store) GetFormulas(userID uuid.UUID, projectID *uuid.UUID) ([]models.Formula, error) {
-    var formulas []models.Formula
+    formulas := []models.Formula{}
     query := `
-        SELECT * FROM formulas 
-        WHERE created_by = $1
-           OR project_id = $2
-           OR created_by = (SELECT id FROM users WHERE email = 'physics-constants@system.local')
-        ORDER BY created_at DESC
+        SELECT f.* FROM formulas f
+        WHERE f.created_by = $1
+           OR (f.is_global AND f.review_status = 'approved')
+           OR (f.project_id = $2 AND EXISTS (
+               SELECT 1 FROM project_members pm
+               WHERE pm.project_id = f.project_id AND pm.user_id = $1
+           ))
+        ORDER BY f.created_at DESC
     `
     if err := s.db.Select(&formulas, query, userID, projectID); err != nil {
         return nil, fmt.Errorf("failed to get formulas: %w", err)
@@ -37,7 +49,6 @@ func (s *Store) GetFormulas(userID uuid.UUID, projectID *uuid.UUID) ([]models.Fo
     return formulas, nil
 }
 
-
 func (s *Store) GetFormulaByID(id uuid.UUID) (*models.Formula, error) {
     var formula models.Formula
     query := `SELECT * FROM formulas WHERE id = $1`
@@ -51,25 +62,88 @@ func (s *Store) GetFormulaByID(id uuid.UUID) (*models.Formula, error) {
     return &formula, nil
 }
 
-func (s *Store) UpdateFormula(formula *models.Formula) error {
+func (s *Store) UpdateFormula(formula *models.Formula, event *models.ModerationEvent) error {
     query := `
         UPDATE formulas
-        SET title = $1, latex = $2, description = $3, updated_at = $4
-        WHERE id = $5
+        SET title = $1, latex = $2, description = $3, review_status = $4, updated_at = $5
+        WHERE id = $6
     `
-    _, err := s.db.Exec(query, formula.Title, formula.Latex, 
-        formula.Description, formula.UpdatedAt, formula.ID)
-    if err != nil {
-        return fmt.Errorf("failed to update formula: %w", err)
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, formula.Title, formula.Latex,
+            formula.Description, formula.ReviewStatus, formula.UpdatedAt, formula.ID)
+        if err != nil {
+            return fmt.Errorf("failed to update formula: %w", err)
+        }
+        return replaceFormulaVariables(tx, formula.ID, formula.Variables)
+    })
+}
+
+// replaceFormulaVariables makes variables the formula's declared variables.
+func replaceFormulaVariables(tx *sqlx.Tx, formulaID uuid.UUID, variables []models.FormulaVariable) error {
+    if _, err := tx.Exec(`DELETE FROM formula_variables WHERE formula_id = $1`, formulaID); err != nil {
+        return fmt.Errorf("failed to clear formula variables: %w", err)
+    }
+    query := `
+        INSERT INTO formula_variables (formula_id, symbol, unit, constant_id, description)
+        VALUES ($1, $2, $3, $4, $5)
+    `
+    for _, v := range variables {
+        if _, err := tx.Exec(query, formulaID, v.Symbol, v.Unit, v.ConstantID, v.Description); err != nil {
+            return fmt.Errorf("failed to save formula variable %s: %w", v.Symbol, err)
+        }
     }
     return nil
 }
 
-func (s *Store) DeleteFormula(id uuid.UUID) error {
+func (s *Store) GetFormulaVariables(formulaID uuid.UUID) ([]models.FormulaVariable, error) {
+    variables := []models.FormulaVariable{}
+    query := `SELECT * FROM formula_variables WHERE formula_id = $1 ORDER BY symbol`
+    if err := s.db.Select(&variables, query, formulaID); err != nil {
+        return nil, fmt.Errorf("failed to get formula variables: %w", err)
+    }
+    return variables, nil
+}
+
+// GetLinkedConstants returns the constants the formula's variables link to.
+func (s *Store) GetLinkedConstants(formulaID uuid.UUID) ([]models.Constant, error) {
+    constants := []models.Constant{}
+    query := `
+        SELECT c.* FROM constants c
+        JOIN formula_variables v ON v.constant_id = c.id
+        WHERE v.formula_id = $1
+    `
+    if err := s.db.Select(&constants, query, formulaID); err != nil {
+        return nil, fmt.Errorf("failed to get linked constants: %w", err)
+    }
+    return constants, nil
+}
+
+func (s *Store) DeleteFormula(id uuid.UUID, event *models.ModerationEvent) error {
     query := `DELETE FROM formulas WHERE id = $1`
-    _, err := s.db.Exec(query, id)
-    if err != nil {
-        return fmt.Errorf("failed to delete formula: %w", err)
+    return s.withModeration(event, func(tx *sqlx.Tx) error {
+        _, err := tx.Exec(query, id)
+        if err != nil {
+            return fmt.Errorf("failed to delete formula: %w", err)
+        }
+        return nil
+    })
+}
+
+// GetPendingFormulas lists proposed global formulas, oldest first.
+func (s *Store) GetPendingFormulas() ([]models.Formula, error) {
+    formulas := []models.Formula{}
+    query := `SELECT * FROM formulas WHERE review_status = 'pending' ORDER BY updated_at ASC`
+    if err := s.db.Select(&formulas, query); err != nil {
+        return nil, fmt.Errorf("failed to get pending formulas: %w", err)
     }
-    return nil
+    return formulas, nil
+}
+
+func (s *Store) GetFormulasByProject(projectID uuid.UUID) ([]models.Formula, error) {
+    var formulas []models.Formula
+    query := `SELECT * FROM formulas WHERE project_id = $1 ORDER BY created_at ASC`
+    if err := s.db.Select(&formulas, query, projectID); err != nil {
+        return nil, fmt.Errorf("failed to get project formulas: %w", err)
+    }
+    return formulas, nil
 }
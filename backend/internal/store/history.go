@@ -0,0 +1,103 @@
+package store
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+)
+
+// GetTaskTimeline merges field revisions, comments and tag changes of a task
+// into a single chronological list.
+func (s *Store) GetTaskTimeline(taskID uuid.UUID) ([]models.TimelineEntry, error) {
+	var revisions []struct {
+		models.TaskRevision
+		UserName *string `db:"user_name"`
+	}
+	query := `
+		SELECT r.*, u.name as user_name
+		FROM task_revisions r
+		LEFT JOIN users u ON r.changed_by = u.id
+		WHERE r.task_id = $1
+		ORDER BY r.revision ASC
+	`
+	if err := s.db.Select(&revisions, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task revisions: %w", err)
+	}
+
+	comments, err := s.getTaskComments(taskID, uuid.Nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var tagEvents []models.ActivityEventWithUser
+	query = `
+		SELECT
+			e.id, e.project_id, e.actor_id, e.action, e.entity_type, e.entity_id,
+			COALESCE(e.before, 'null'::jsonb) as before,
+			COALESCE(e.after, 'null'::jsonb) as after,
+			e.created_at,
+			u.name as actor_name,
+			u.email as actor_email
+		FROM activity_events e
+		LEFT JOIN users u ON e.actor_id = u.id
+		WHERE e.entity_type = 'task_tag' AND e.entity_id = $1
+		ORDER BY e.created_at ASC
+	`
+	if err := s.db.Select(&tagEvents, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task tag changes: %w", err)
+	}
+
+	timeline := make([]models.TimelineEntry, 0, len(revisions)+len(comments)+len(tagEvents))
+	for i := range revisions {
+		r := revisions[i]
+		entry := models.TimelineEntry{
+			Type:     models.TimelineRevision,
+			At:       r.CreatedAt,
+			ActorID:  r.ChangedBy,
+			Revision: &r.TaskRevision,
+		}
+		if r.UserName != nil {
+			entry.ActorName = *r.UserName
+		}
+		timeline = append(timeline, entry)
+	}
+	for i := range comments {
+		c := &comments[i]
+		if c.DeletedAt != nil {
+			continue
+		}
+		userID := c.UserID
+		timeline = append(timeline, models.TimelineEntry{
+			Type:      models.TimelineComment,
+			At:        c.CreatedAt,
+			ActorID:   &userID,
+			ActorName: c.UserName,
+			Comment:   c,
+		})
+	}
+	for _, e := range tagEvents {
+		entry := models.TimelineEntry{
+			At:      e.CreatedAt,
+			ActorID: e.ActorID,
+		}
+		if e.Action == models.ActivityAdded {
+			entry.Type = models.TimelineTagAdded
+			entry.Tag = e.After
+		} else {
+			entry.Type = models.TimelineTagRemoved
+			entry.Tag = e.Before
+		}
+		if e.ActorName != nil {
+			entry.ActorName = *e.ActorName
+		}
+		timeline = append(timeline, entry)
+	}
+
+	sort.SliceStable(timeline, func(i, j int) bool {
+		return timeline[i].At.Before(timeline[j].At)
+	})
+
+	return timeline, nil
+}
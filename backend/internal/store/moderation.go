@@ -0,0 +1,153 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var ErrNotPending = errors.New("entry is not awaiting review")
+
+// NewModerationEvent builds an entry of the moderation audit trail. data is
+// an optional snapshot stored as JSON.
+func NewModerationEvent(actorID uuid.UUID, action, entityType string, entityID uuid.UUID, note string, data interface{}) *models.ModerationEvent {
+	event := &models.ModerationEvent{
+		ID:         uuid.New(),
+		EntityType: entityType,
+		EntityID:   entityID,
+		ActorID:    &actorID,
+		Action:     action,
+		Note:       note,
+		CreatedAt:  time.Now(),
+	}
+	if data != nil {
+		if raw, err := json.Marshal(data); err == nil {
+			event.Data = raw
+		}
+	}
+	return event
+}
+
+// withModeration runs fn in a transaction and records the moderation event,
+// if any, in the same transaction.
+func (s *Store) withModeration(event *models.ModerationEvent, fn func(tx *sqlx.Tx) error) error {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := fn(tx); err != nil {
+		return err
+	}
+	if err := insertModerationEvent(tx, event); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func insertModerationEvent(tx *sqlx.Tx, event *models.ModerationEvent) error {
+	if event == nil {
+		return nil
+	}
+	query := `
+		INSERT INTO moderation_events (id, entity_type, entity_id, actor_id, action, note, data, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+	`
+	_, err := tx.Exec(query, event.ID, event.EntityType, event.EntityID, event.ActorID,
+		event.Action, event.Note, nullableJSON(event.Data), event.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record moderation event: %w", err)
+	}
+	return nil
+}
+
+// ReviewConstant approves or rejects a pending global constant. It returns
+// ErrNotPending if the constant has already been reviewed.
+func (s *Store) ReviewConstant(id, reviewerID uuid.UUID, status, note string) error {
+	return s.reviewEntry("constants", models.ModerationConstant, id, reviewerID, status, note)
+}
+
+// ReviewFormula approves or rejects a pending global formula. It returns
+// ErrNotPending if the formula has already been reviewed.
+func (s *Store) ReviewFormula(id, reviewerID uuid.UUID, status, note string) error {
+	return s.reviewEntry("formulas", models.ModerationFormula, id, reviewerID, status, note)
+}
+
+func (s *Store) reviewEntry(table, entityType string, id, reviewerID uuid.UUID, status, note string) error {
+	action := models.ModerationApproved
+	if status == models.ReviewRejected {
+		action = models.ModerationRejected
+	}
+	event := NewModerationEvent(reviewerID, action, entityType, id, note, nil)
+
+	query := `
+		UPDATE ` + table + `
+		SET review_status = $1, reviewed_by = $2, reviewed_at = $3, review_note = $4
+		WHERE id = $5 AND review_status = 'pending'
+	`
+	return s.withModeration(event, func(tx *sqlx.Tx) error {
+		result, err := tx.Exec(query, status, reviewerID, event.CreatedAt, note, id)
+		if err != nil {
+			return fmt.Errorf("failed to review %s: %w", entityType, err)
+		}
+		if n, err := result.RowsAffected(); err != nil {
+			return fmt.Errorf("failed to review %s: %w", entityType, err)
+		} else if n == 0 {
+			return ErrNotPending
+		}
+		return nil
+	})
+}
+
+// GetModerationLog returns the newest moderation events, optionally only
+// those of one entity type or entity.
+func (s *Store) GetModerationLog(entityType string, entityID *uuid.UUID, limit int) ([]models.ModerationEventWithUser, error) {
+	events := []models.ModerationEventWithUser{}
+	query := `
+		SELECT e.*, u.name AS actor_name
+		FROM moderation_events e
+		LEFT JOIN users u ON u.id = e.actor_id
+		WHERE ($1 = '' OR e.entity_type = $1)
+		  AND ($2::uuid IS NULL OR e.entity_id = $2)
+		ORDER BY e.created_at DESC, e.id DESC
+		LIMIT $3
+	`
+	if err := s.db.Select(&events, query, entityType, entityID, limit); err != nil {
+		return nil, fmt.Errorf("failed to get moderation log: %w", err)
+	}
+	return events, nil
+}
+
+func (s *Store) SetUserSiteRole(userID uuid.UUID, role string, event *models.ModerationEvent) error {
+	query := `UPDATE users SET site_role = $1, updated_at = $2 WHERE id = $3`
+	return s.withModeration(event, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(query, role, time.Now(), userID); err != nil {
+			return fmt.Errorf("failed to set site role: %w", err)
+		}
+		return nil
+	})
+}
+
+// PromoteSiteAdmins grants the admin role to the users with the given
+// emails. It bootstraps the first administrators from the configuration.
+func (s *Store) PromoteSiteAdmins(emails []string) error {
+	if len(emails) == 0 {
+		return nil
+	}
+	query := `
+		UPDATE users SET site_role = 'admin', updated_at = NOW()
+		WHERE email = ANY($1) AND site_role != 'admin'
+	`
+	if _, err := s.db.Exec(query, pq.Array(emails)); err != nil {
+		return fmt.Errorf("failed to promote site admins: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+func (s *Store) CreateNotification(n *models.Notification) error {
+	return insertNotification(s.db, n)
+}
+
+func insertNotification(db sqlx.Execer, n *models.Notification) error {
+	query := `
+        INSERT INTO notifications (id, user_id, kind, project_id, task_id, actor_id, title, body, created_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+	_, err := db.Exec(query, n.ID, n.UserID, n.Kind, n.ProjectID, n.TaskID, n.ActorID, n.Title, n.Body, n.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// GetNotifications lists a user's notifications, newest first. before pages
+// back from the given creation time.
+func (s *Store) GetNotifications(userID uuid.UUID, unreadOnly bool, before *time.Time, limit int) ([]models.Notification, error) {
+	notifications := []models.Notification{}
+	query := `
+        SELECT * FROM notifications
+        WHERE user_id = $1
+          AND ($2::boolean = FALSE OR read_at IS NULL)
+          AND ($3::timestamptz IS NULL OR created_at < $3)
+        ORDER BY created_at DESC
+        LIMIT $4
+    `
+	if err := s.db.Select(&notifications, query, userID, unreadOnly, before, limit); err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	return notifications, nil
+}
+
+func (s *Store) CountUnreadNotifications(userID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`
+	if err := s.db.Get(&count, query, userID); err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkNotificationsRead marks the given notifications of a user read; with no
+// ids all of them are marked.
+func (s *Store) MarkNotificationsRead(userID uuid.UUID, ids []uuid.UUID) error {
+	query := `UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`
+	args := []interface{}{time.Now(), userID}
+	if len(ids) > 0 {
+		idStrings := make([]string, len(ids))
+		for i, id := range ids {
+			idStrings[i] = id.String()
+		}
+		query += ` AND id = ANY($3::uuid[])`
+		args = append(args, pq.Array(idStrings))
+	}
+	if _, err := s.db.Exec(query, args...); err != nil {
+		return fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return nil
+}
+
+// ClaimDeadlineReminder records that a reminder of the given kind goes out
+// for a task's current due date and stores the reminder's in-app
+// notification in the same transaction, so a claimed reminder cannot be
+// lost. It reports false if one already went out, so concurrent schedulers
+// send each reminder once.
+func (s *Store) ClaimDeadlineReminder(taskID, userID uuid.UUID, kind string, dueDate time.Time, n *models.Notification) (bool, error) {
+	tx, err := s.db.Beginx()
+	if err != nil {
+		return false, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+        INSERT INTO deadline_reminders (task_id, user_id, kind, due_date, sent_at)
+        VALUES ($1, $2, $3, $4, $5)
+        ON CONFLICT DO NOTHING
+    `
+	res, err := tx.Exec(query, taskID, userID, kind, dueDate, time.Now())
+	if err != nil {
+		return false, fmt.Errorf("failed to record deadline reminder: %w", err)
+	}
+	if claimed, _ := res.RowsAffected(); claimed == 0 {
+		return false, nil
+	}
+	if err := insertNotification(tx, n); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to commit deadline reminder: %w", err)
+	}
+	return true, nil
+}
+
+// GetTasksDueForReminders lists the open tasks of a project due within the
+// given duration and at most lookback overdue that still have an assignee
+// without the reminder for the task's current due date: the overdue one
+// once the due date has passed, the deadline one before.
+func (s *Store) GetTasksDueForReminders(projectID uuid.UUID, within, lookback time.Duration) ([]models.Task, error) {
+	var tasks []models.Task
+	query := `
+        SELECT t.* FROM tasks t
+        WHERE t.project_id = $1
+          AND t.due_date IS NOT NULL
+          AND t.due_date <= NOW() + $2::interval
+          AND t.due_date > NOW() - $3::interval
+          AND NOT ` + taskDoneCondition("t") + `
+          AND EXISTS (
+              SELECT 1 FROM task_assignees a
+              WHERE a.task_id = t.id
+                AND NOT EXISTS (
+                    SELECT 1 FROM deadline_reminders r
+                    WHERE r.task_id = t.id AND r.user_id = a.user_id AND r.due_date = t.due_date
+                      AND r.kind = CASE WHEN t.due_date < NOW() THEN $4 ELSE $5 END
+                )
+          )
+        ORDER BY t.due_date ASC
+    `
+	err := s.db.Select(&tasks, query, projectID,
+		fmt.Sprintf("%d seconds", int64(within.Seconds())),
+		fmt.Sprintf("%d seconds", int64(lookback.Seconds())),
+		models.NotificationOverdue, models.NotificationDeadlineSoon)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get tasks due for reminders: %w", err)
+	}
+	return tasks, nil
+}
@@ -8,8 +8,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
 )
 
+// ErrMemberNotFound is returned when the user is not a removable member of
+// the project: they never joined, already left, or own it.
+var ErrMemberNotFound = errors.New("member not found")
+
 type ProjectMemberWithUser struct {
 	ID        uuid.UUID `json:"id" db:"id"`
 	ProjectID uuid.UUID `json:"project_id" db:"project_id"`
@@ -86,6 +91,14 @@ func (s *Store) GetProjectsByUser(userID uuid.UUID) ([]models.Project, error) {
 	return projects, nil
 }
 
+func (s *Store) GetAllProjectIDs() ([]uuid.UUID, error) {
+	var ids []uuid.UUID
+	if err := s.db.Select(&ids, `SELECT id FROM projects ORDER BY created_at`); err != nil {
+		return nil, fmt.Errorf("failed to get projects: %w", err)
+	}
+	return ids, nil
+}
+
 func (s *Store) GetProjectByID(id uuid.UUID) (*models.Project, error) {
 	var project models.Project
 	query := `SELECT * FROM projects WHERE id = $1`
@@ -131,25 +144,41 @@ func (s *Store) IsProjectMember(projectID, userID uuid.UUID) (bool, error) {
 	return exists, nil
 }
 
-func (s *Store) AddProjectMember(member *models.ProjectMember) error {
+func (s *Store) AddProjectMember(member *models.ProjectMember, activity *models.ActivityEvent) error {
 	query := `
         INSERT INTO project_members (id, project_id, user_id, role, joined_at)
         VALUES ($1, $2, $3, $4, $5)
     `
-	_, err := s.db.Exec(query, member.ID, member.ProjectID, member.UserID, member.Role, member.JoinedAt)
-	if err != nil {
-		return fmt.Errorf("failed to add member: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, member.ID, member.ProjectID, member.UserID, member.Role, member.JoinedAt)
+		if err != nil {
+			return fmt.Errorf("failed to add member: %w", err)
+		}
+		return nil
+	})
 }
 
-func (s *Store) RemoveProjectMember(projectID, userID uuid.UUID) error {
+func (s *Store) RemoveProjectMember(projectID, userID uuid.UUID, activity *models.ActivityEvent) error {
 	query := `DELETE FROM project_members WHERE project_id = $1 AND user_id = $2 AND role != 'owner'`
-	_, err := s.db.Exec(query, projectID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to remove member: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		res, err := tx.Exec(query, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to remove member: %w", err)
+		}
+		n, err := res.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if n == 0 {
+			return ErrMemberNotFound
+		}
+
+		var changedBy *uuid.UUID
+		if activity != nil {
+			changedBy = activity.ActorID
+		}
+		return removeMemberFromTasks(tx, projectID, userID, changedBy)
+	})
 }
 
 func (s *Store) GetMemberRole(projectID, userID uuid.UUID) (string, error) {
@@ -165,29 +194,31 @@ func (s *Store) GetMemberRole(projectID, userID uuid.UUID) (string, error) {
 	return role, nil
 }
 
-func (s *Store) UpdateMemberRole(projectID, userID uuid.UUID, newRole string) error {
+func (s *Store) UpdateMemberRole(projectID, userID uuid.UUID, newRole string, activity *models.ActivityEvent) error {
 	query := `
         UPDATE project_members 
         SET role = $1 
         WHERE project_id = $2 AND user_id = $3 AND role != 'owner'
     `
-	result, err := s.db.Exec(query, newRole, projectID, userID)
-	if err != nil {
-		return fmt.Errorf("failed to update member role: %w", err)
-	}
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		result, err := tx.Exec(query, newRole, projectID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to update member role: %w", err)
+		}
 
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected: %w", err)
-	}
-	if rowsAffected == 0 {
-		return fmt.Errorf("member not found or cannot change owner role")
-	}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return fmt.Errorf("failed to get rows affected: %w", err)
+		}
+		if rowsAffected == 0 {
+			return fmt.Errorf("member not found or cannot change owner role")
+		}
 
-	return nil
+		return nil
+	})
 }
 
-func (s *Store) TransferOwnership(projectID, currentOwnerID, newOwnerID uuid.UUID) error {
+func (s *Store) TransferOwnership(projectID, currentOwnerID, newOwnerID uuid.UUID, activity *models.ActivityEvent) error {
 	tx, err := s.db.Beginx()
 	if err != nil {
 		return fmt.Errorf("failed to begin transaction: %w", err)
@@ -227,5 +258,9 @@ func (s *Store) TransferOwnership(projectID, currentOwnerID, newOwnerID uuid.UUI
 		return fmt.Errorf("failed to update project owner: %w", err)
 	}
 
+	if err := insertActivity(tx, activity); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
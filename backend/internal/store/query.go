@@ -0,0 +1,219 @@
+package store
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/lib/pq"
+)
+
+// taskSearchVector must match the expression of idx_tasks_search for the
+// index to be used.
+const taskSearchVector = `to_tsvector('simple', t.title || ' ' || coalesce(t.description, ''))`
+
+const taskPriorityOrder = `CASE t.priority WHEN 'low' THEN 1 WHEN 'medium' THEN 2 WHEN 'high' THEN 3 ELSE 0 END`
+
+// taskSort is the SQL behind a sort key. The cursor of a page holds the sort
+// value of its last task, which param casts back for the keyset comparison.
+type taskSort struct {
+	expr  string
+	param string
+	desc  bool
+}
+
+var taskSorts = map[string]taskSort{
+	"rank":        {`t.rank COLLATE "C"`, `%s::text COLLATE "C"`, false},
+	"due_date":    {`COALESCE(t.due_date, 'infinity'::timestamptz)`, `%s::timestamptz`, false},
+	"-due_date":   {`COALESCE(t.due_date, '-infinity'::timestamptz)`, `%s::timestamptz`, true},
+	"priority":    {taskPriorityOrder, `%s::int`, false},
+	"-priority":   {taskPriorityOrder, `%s::int`, true},
+	"created_at":  {`t.created_at`, `%s::timestamptz`, false},
+	"-created_at": {`t.created_at`, `%s::timestamptz`, true},
+	"updated_at":  {`t.updated_at`, `%s::timestamptz`, false},
+	"-updated_at": {`t.updated_at`, `%s::timestamptz`, true},
+	"title":       {`lower(t.title)`, `%s::text`, false},
+	"-title":      {`lower(t.title)`, `%s::text`, true},
+}
+
+type taskCursor struct {
+	Sort  string    `json:"s"`
+	Value string    `json:"v"`
+	ID    uuid.UUID `json:"id"`
+}
+
+func encodeTaskCursor(c taskCursor) string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+func decodeTaskCursor(raw, sort string) (*taskCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err != nil {
+		return nil, models.ErrInvalidCursor
+	}
+	var c taskCursor
+	if err := json.Unmarshal(data, &c); err != nil || c.Sort != sort {
+		return nil, models.ErrInvalidCursor
+	}
+	return &c, nil
+}
+
+// taskQueryConditions builds the WHERE clause of a task query. "me" resolves
+// to viewerID. Placeholders are numbered after the existing args.
+func taskQueryConditions(q *models.TaskQuery, viewerID uuid.UUID, args []interface{}) ([]string, []interface{}) {
+	var conditions []string
+	arg := func(v interface{}) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+	resolveUsers := func(values []string) (ids []string, none bool) {
+		for _, v := range values {
+			switch v {
+			case models.QueryUserMe:
+				ids = append(ids, viewerID.String())
+			case models.QueryUserNone:
+				none = true
+			default:
+				ids = append(ids, v)
+			}
+		}
+		return ids, none
+	}
+
+	if len(q.Statuses) > 0 {
+		conditions = append(conditions, "t.status = ANY("+arg(pq.Array(q.Statuses))+")")
+	}
+	if len(q.Priorities) > 0 {
+		conditions = append(conditions, "t.priority = ANY("+arg(pq.Array(q.Priorities))+")")
+	}
+
+	if len(q.Assignees) > 0 {
+		ids, none := resolveUsers(q.Assignees)
+		var alternatives []string
+		if len(ids) > 0 {
+			alternatives = append(alternatives, "EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = ANY("+arg(pq.Array(ids))+"::uuid[]))")
+		}
+		if none {
+			alternatives = append(alternatives, "NOT EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id)")
+		}
+		conditions = append(conditions, "("+strings.Join(alternatives, " OR ")+")")
+	}
+	if len(q.Creators) > 0 {
+		ids, _ := resolveUsers(q.Creators)
+		conditions = append(conditions, "t.created_by = ANY("+arg(pq.Array(ids))+"::uuid[])")
+	}
+
+	if q.DueFrom != "" {
+		conditions = append(conditions, "t.due_date >= "+arg(q.DueFrom)+"::date")
+	}
+	if q.DueTo != "" {
+		conditions = append(conditions, "t.due_date < "+arg(q.DueTo)+"::date + 1")
+	}
+	if q.Overdue {
+		conditions = append(conditions, "(t.due_date < NOW() AND NOT "+taskDoneCondition("t")+")")
+	}
+	if q.NoDue {
+		conditions = append(conditions, "t.due_date IS NULL")
+	}
+
+	if len(q.Tags) > 0 {
+		tagStrings := make([]string, len(q.Tags))
+		for i, id := range q.Tags {
+			tagStrings[i] = id.String()
+		}
+		tags := arg(pq.Array(tagStrings))
+		if q.TagMatch == models.TagMatchAll {
+			conditions = append(conditions, fmt.Sprintf(
+				"(SELECT COUNT(DISTINCT tt.tag_id) FROM task_tags tt WHERE tt.task_id = t.id AND tt.tag_id = ANY(%s::uuid[])) = %d",
+				tags, len(uniqueTagIDs(q.Tags))))
+		} else {
+			conditions = append(conditions,
+				"EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = t.id AND tt.tag_id = ANY("+tags+"::uuid[]))")
+		}
+	}
+
+	if search := strings.TrimSpace(q.Search); search != "" {
+		conditions = append(conditions, taskSearchVector+" @@ websearch_to_tsquery('simple', "+arg(search)+")")
+	}
+
+	return conditions, args
+}
+
+func uniqueTagIDs(ids []uuid.UUID) map[uuid.UUID]bool {
+	set := make(map[uuid.UUID]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+// QueryTasks lists the tasks of a project matching q, with their tags and
+// details. With a positive limit at most limit tasks are returned along with
+// a cursor for the next page; the cursor is empty on the last page.
+func (s *Store) QueryTasks(projectID, viewerID uuid.UUID, q *models.TaskQuery, limit int, cursor string) ([]models.Task, string, error) {
+	sortKey := q.SortKey()
+	sort, ok := taskSorts[sortKey]
+	if !ok {
+		return nil, "", fmt.Errorf("%w: unknown sort %q", models.ErrInvalidTaskQuery, sortKey)
+	}
+
+	conditions, args := taskQueryConditions(q, viewerID, []interface{}{projectID})
+	conditions = append([]string{"t.project_id = $1"}, conditions...)
+
+	cmp, dir := ">", "ASC"
+	if sort.desc {
+		cmp, dir = "<", "DESC"
+	}
+	if cursor != "" {
+		after, err := decodeTaskCursor(cursor, sortKey)
+		if err != nil {
+			return nil, "", err
+		}
+		args = append(args, after.Value, after.ID)
+		value := fmt.Sprintf(sort.param, fmt.Sprintf("$%d", len(args)-1))
+		conditions = append(conditions, fmt.Sprintf("(%s, t.id) %s (%s, $%d)", sort.expr, cmp, value, len(args)))
+	}
+
+	query := fmt.Sprintf(`SELECT t.*, (%s)::text AS sort_value FROM tasks t WHERE %s ORDER BY %s %s, t.id %s`,
+		sort.expr, strings.Join(conditions, " AND "), sort.expr, dir, dir)
+	if limit > 0 {
+		query += fmt.Sprintf(" LIMIT %d", limit+1)
+	}
+
+	var rows []struct {
+		models.Task
+		SortValue string `db:"sort_value"`
+	}
+	if err := s.db.Select(&rows, query, args...); err != nil {
+		return nil, "", fmt.Errorf("failed to query tasks: %w", err)
+	}
+
+	next := ""
+	if limit > 0 && len(rows) > limit {
+		rows = rows[:limit]
+		last := rows[limit-1]
+		next = encodeTaskCursor(taskCursor{Sort: sortKey, Value: last.SortValue, ID: last.ID})
+	}
+
+	tasks := make([]models.Task, len(rows))
+	refs := make([]*models.Task, len(rows))
+	for i := range rows {
+		tasks[i] = rows[i].Task
+		tags, err := s.GetTagsByTask(tasks[i].ID)
+		if err != nil {
+			return nil, "", err
+		}
+		tasks[i].Tags = tags
+		refs[i] = &tasks[i]
+	}
+
+	if err := s.AttachTaskDetails(refs...); err != nil {
+		return nil, "", err
+	}
+
+	return tasks, next, nil
+}
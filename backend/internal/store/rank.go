@@ -0,0 +1,114 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/services"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	ErrNeighborNotFound = errors.New("neighbor task not found in target column")
+	ErrStaleNeighbors   = errors.New("neighbor tasks are no longer adjacent")
+)
+
+type rankedTask struct {
+	ID   uuid.UUID `db:"id"`
+	Rank string    `db:"rank"`
+}
+
+// lockProject serializes rank and status changes within a project. NO KEY
+// UPDATE does not block inserts that only reference the project.
+func lockProject(tx *sqlx.Tx, projectID uuid.UUID) error {
+	if _, err := tx.Exec(`SELECT id FROM projects WHERE id = $1 FOR NO KEY UPDATE`, projectID); err != nil {
+		return fmt.Errorf("failed to lock project: %w", err)
+	}
+	return nil
+}
+
+// columnTasks returns the tasks of a status column in display order, without
+// the task being placed.
+func columnTasks(tx *sqlx.Tx, projectID uuid.UUID, status string, exclude uuid.UUID) ([]rankedTask, error) {
+	var column []rankedTask
+	query := `
+        SELECT id, rank FROM tasks
+        WHERE project_id = $1 AND status = $2 AND id != $3
+        ORDER BY rank COLLATE "C", created_at DESC
+    `
+	if err := tx.Select(&column, query, projectID, status, exclude); err != nil {
+		return nil, fmt.Errorf("failed to get column tasks: %w", err)
+	}
+	return column, nil
+}
+
+// rankAt returns a rank for a task inserted at position idx of column. When
+// the neighbors leave no usable gap the whole column is renumbered.
+func rankAt(tx *sqlx.Tx, column []rankedTask, idx int) (string, error) {
+	var lower, upper string
+	usable := true
+	if idx > 0 {
+		lower = column[idx-1].Rank
+		usable = lower != ""
+	}
+	if idx < len(column) {
+		upper = column[idx].Rank
+		usable = usable && upper != ""
+	}
+
+	if usable {
+		rank, err := services.RankBetween(lower, upper)
+		if err == nil && len(rank) <= services.MaxRankLength {
+			return rank, nil
+		}
+	}
+
+	ranks := services.EvenRanks(len(column) + 1)
+	for i, t := range column {
+		newRank := ranks[i]
+		if i >= idx {
+			newRank = ranks[i+1]
+		}
+		if newRank == t.Rank {
+			continue
+		}
+		if _, err := tx.Exec(`UPDATE tasks SET rank = $1 WHERE id = $2`, newRank, t.ID); err != nil {
+			return "", fmt.Errorf("failed to rebalance column: %w", err)
+		}
+	}
+	return ranks[idx], nil
+}
+
+// neighborIndex resolves the requested neighbors to an insertion position in
+// column. Both neighbors given must still be adjacent.
+func neighborIndex(column []rankedTask, beforeID, afterID *uuid.UUID) (int, error) {
+	find := func(id uuid.UUID) int {
+		for i, t := range column {
+			if t.ID == id {
+				return i
+			}
+		}
+		return -1
+	}
+
+	idx := len(column)
+	if beforeID != nil {
+		pos := find(*beforeID)
+		if pos < 0 {
+			return 0, ErrNeighborNotFound
+		}
+		idx = pos + 1
+	}
+	if afterID != nil {
+		pos := find(*afterID)
+		if pos < 0 {
+			return 0, ErrNeighborNotFound
+		}
+		if beforeID != nil && pos != idx {
+			return 0, ErrStaleNeighbors
+		}
+		idx = pos
+	}
+	return idx, nil
+}
@@ -0,0 +1,272 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var (
+	ErrParentNotFound    = errors.New("parent task not found in this project")
+	ErrTaskCycle         = errors.New("a task cannot be nested under itself or its subtasks")
+	ErrTaskDepthExceeded = errors.New("task nesting is too deep")
+)
+
+// checkParent verifies that task.ParentID is a task of the same project and
+// that nesting the task (with its own subtasks) there creates neither a cycle
+// nor a tree deeper than models.MaxTaskDepth.
+func checkParent(tx *sqlx.Tx, task *models.Task) error {
+	if task.ParentID == nil {
+		return nil
+	}
+	if *task.ParentID == task.ID {
+		return ErrTaskCycle
+	}
+
+	var parentProject uuid.UUID
+	err := tx.Get(&parentProject, `SELECT project_id FROM tasks WHERE id = $1`, *task.ParentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrParentNotFound
+		}
+		return fmt.Errorf("failed to get parent task: %w", err)
+	}
+	if parentProject != task.ProjectID {
+		return ErrParentNotFound
+	}
+
+	var ancestors []uuid.UUID
+	err = tx.Select(&ancestors, `
+        WITH RECURSIVE ancestors AS (
+            SELECT id, parent_id, 1 AS level FROM tasks WHERE id = $1
+            UNION ALL
+            SELECT t.id, t.parent_id, a.level + 1
+            FROM tasks t INNER JOIN ancestors a ON t.id = a.parent_id
+            WHERE a.level <= $2
+        )
+        SELECT id FROM ancestors
+    `, *task.ParentID, models.MaxTaskDepth)
+	if err != nil {
+		return fmt.Errorf("failed to get task ancestors: %w", err)
+	}
+	for _, id := range ancestors {
+		if id == task.ID {
+			return ErrTaskCycle
+		}
+	}
+
+	var height int
+	err = tx.Get(&height, `
+        WITH RECURSIVE subtree AS (
+            SELECT id, 1 AS level FROM tasks WHERE id = $1
+            UNION ALL
+            SELECT t.id, s.level + 1
+            FROM tasks t INNER JOIN subtree s ON t.parent_id = s.id
+            WHERE s.level <= $2
+        )
+        SELECT COALESCE(MAX(level), 1) FROM subtree
+    `, task.ID, models.MaxTaskDepth)
+	if err != nil {
+		return fmt.Errorf("failed to get subtask depth: %w", err)
+	}
+
+	if len(ancestors)+height > models.MaxTaskDepth {
+		return fmt.Errorf("%w: at most %d levels are allowed", ErrTaskDepthExceeded, models.MaxTaskDepth)
+	}
+	return nil
+}
+
+func (s *Store) GetSubtasks(taskID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	query := `SELECT * FROM tasks WHERE parent_id = $1 ORDER BY rank COLLATE "C", created_at DESC`
+	if err := s.db.Select(&tasks, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get subtasks: %w", err)
+	}
+	return tasks, nil
+}
+
+func (s *Store) CountOpenSubtasks(taskID uuid.UUID) (int, error) {
+	var count int
+	query := `SELECT COUNT(*) FROM tasks t WHERE t.parent_id = $1 AND NOT ` + taskDoneCondition("t")
+	if err := s.db.Get(&count, query, taskID); err != nil {
+		return 0, fmt.Errorf("failed to count open subtasks: %w", err)
+	}
+	return count, nil
+}
+
+// AttachTaskDetails fills in the computed fields of tasks: Progress for tasks
+// that have subtasks or checklist items, Blocked and Assignees.
+func (s *Store) AttachTaskDetails(tasks ...*models.Task) error {
+	if len(tasks) == 0 {
+		return nil
+	}
+	ids := make([]string, len(tasks))
+	for i, t := range tasks {
+		ids[i] = t.ID.String()
+	}
+
+	if err := s.attachTaskProgress(tasks, ids); err != nil {
+		return err
+	}
+	if err := s.attachTaskBlocked(tasks, ids); err != nil {
+		return err
+	}
+	return s.attachTaskAssignees(tasks, ids)
+}
+
+func (s *Store) attachTaskProgress(tasks []*models.Task, ids []string) error {
+	type counts struct {
+		TaskID uuid.UUID `db:"task_id"`
+		Total  int       `db:"total"`
+		Done   int       `db:"done"`
+	}
+
+	var subtasks []counts
+	err := s.db.Select(&subtasks, `
+        SELECT t.parent_id AS task_id, COUNT(*) AS total,
+               COUNT(*) FILTER (WHERE `+taskDoneCondition("t")+`) AS done
+        FROM tasks t
+        WHERE t.parent_id = ANY($1::uuid[])
+        GROUP BY t.parent_id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get subtask progress: %w", err)
+	}
+
+	var checklist []counts
+	err = s.db.Select(&checklist, `
+        SELECT task_id, COUNT(*) AS total, COUNT(*) FILTER (WHERE done) AS done
+        FROM checklist_items
+        WHERE task_id = ANY($1::uuid[])
+        GROUP BY task_id
+    `, pq.Array(ids))
+	if err != nil {
+		return fmt.Errorf("failed to get checklist progress: %w", err)
+	}
+
+	subtasksByTask := make(map[uuid.UUID]counts, len(subtasks))
+	for _, c := range subtasks {
+		subtasksByTask[c.TaskID] = c
+	}
+	checklistByTask := make(map[uuid.UUID]counts, len(checklist))
+	for _, c := range checklist {
+		checklistByTask[c.TaskID] = c
+	}
+
+	for _, t := range tasks {
+		sub := subtasksByTask[t.ID]
+		items := checklistByTask[t.ID]
+		t.Progress = models.NewTaskProgress(sub.Total, sub.Done, items.Total, items.Done)
+	}
+	return nil
+}
+
+func (s *Store) CreateChecklistItem(item *models.ChecklistItem, activity *models.ActivityEvent) error {
+	query := `
+        INSERT INTO checklist_items (id, task_id, content, done, position, created_by, created_at, updated_at)
+        SELECT $1, $2, $3, $4, COALESCE(MAX(position), -1) + 1, $5, $6, $7
+        FROM checklist_items WHERE task_id = $2
+        RETURNING position
+    `
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		err := tx.Get(&item.Position, query, item.ID, item.TaskID, item.Content, item.Done,
+			item.CreatedBy, item.CreatedAt, item.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create checklist item: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) GetChecklistItems(taskID uuid.UUID) ([]models.ChecklistItem, error) {
+	items := []models.ChecklistItem{}
+	query := `SELECT * FROM checklist_items WHERE task_id = $1 ORDER BY position ASC, created_at ASC`
+	if err := s.db.Select(&items, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get checklist items: %w", err)
+	}
+	return items, nil
+}
+
+func (s *Store) GetChecklistItemsByProject(projectID uuid.UUID) ([]models.ChecklistItem, error) {
+	var items []models.ChecklistItem
+	query := `
+        SELECT ci.* FROM checklist_items ci
+        INNER JOIN tasks t ON t.id = ci.task_id
+        WHERE t.project_id = $1
+        ORDER BY ci.task_id, ci.position ASC
+    `
+	if err := s.db.Select(&items, query, projectID); err != nil {
+		return nil, fmt.Errorf("failed to get checklist items: %w", err)
+	}
+	return items, nil
+}
+
+func (s *Store) GetChecklistItemByID(id uuid.UUID) (*models.ChecklistItem, error) {
+	var item models.ChecklistItem
+	query := `SELECT * FROM checklist_items WHERE id = $1`
+	err := s.db.Get(&item, query, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get checklist item: %w", err)
+	}
+	return &item, nil
+}
+
+// UpdateChecklistItem saves an item; when its position changed the other
+// items of the task are renumbered around it.
+func (s *Store) UpdateChecklistItem(item *models.ChecklistItem, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		var ids []uuid.UUID
+		err := tx.Select(&ids, `
+            SELECT id FROM checklist_items
+            WHERE task_id = $1 AND id != $2
+            ORDER BY position ASC, created_at ASC
+            FOR UPDATE
+        `, item.TaskID, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock checklist: %w", err)
+		}
+
+		if item.Position > len(ids) {
+			item.Position = len(ids)
+		}
+		ordered := make([]uuid.UUID, 0, len(ids)+1)
+		ordered = append(ordered, ids[:item.Position]...)
+		ordered = append(ordered, item.ID)
+		ordered = append(ordered, ids[item.Position:]...)
+
+		for pos, id := range ordered {
+			if id == item.ID {
+				continue
+			}
+			if _, err := tx.Exec(`UPDATE checklist_items SET position = $1 WHERE id = $2`, pos, id); err != nil {
+				return fmt.Errorf("failed to reorder checklist: %w", err)
+			}
+		}
+
+		_, err = tx.Exec(`
+            UPDATE checklist_items SET content = $1, done = $2, position = $3, updated_at = $4
+            WHERE id = $5
+        `, item.Content, item.Done, item.Position, item.UpdatedAt, item.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update checklist item: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteChecklistItem(id uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM checklist_items WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete checklist item: %w", err)
+		}
+		return nil
+	})
+}
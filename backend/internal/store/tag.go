@@ -7,19 +7,22 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
 	"github.com/lib/pq"
 )
 
-func (s *Store) CreateTag(tag *models.Tag) error {
+func (s *Store) CreateTag(tag *models.Tag, activity *models.ActivityEvent) error {
 	query := `
 		INSERT INTO tags (id, project_id, name, color, created_by, created_at)
 		VALUES ($1, $2, $3, $4, $5, $6)
 	`
-	_, err := s.db.Exec(query, tag.ID, tag.ProjectID, tag.Name, tag.Color, tag.CreatedBy, tag.CreatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create tag: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, tag.ID, tag.ProjectID, tag.Name, tag.Color, tag.CreatedBy, tag.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create tag: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Store) GetTagsByProject(projectID uuid.UUID) ([]models.TagWithCount, error) {
@@ -54,48 +57,56 @@ func (s *Store) GetTagByID(id uuid.UUID) (*models.Tag, error) {
 	return &tag, nil
 }
 
-func (s *Store) UpdateTag(tag *models.Tag) error {
+func (s *Store) UpdateTag(tag *models.Tag, activity *models.ActivityEvent) error {
 	query := `
 		UPDATE tags
 		SET name = $1, color = $2
 		WHERE id = $3
 	`
-	_, err := s.db.Exec(query, tag.Name, tag.Color, tag.ID)
-	if err != nil {
-		return fmt.Errorf("failed to update tag: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, tag.Name, tag.Color, tag.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update tag: %w", err)
+		}
+		return nil
+	})
 }
 
-func (s *Store) DeleteTag(id uuid.UUID) error {
+func (s *Store) DeleteTag(id uuid.UUID, activity *models.ActivityEvent) error {
 	query := `DELETE FROM tags WHERE id = $1`
-	_, err := s.db.Exec(query, id)
-	if err != nil {
-		return fmt.Errorf("failed to delete tag: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete tag: %w", err)
+		}
+		return nil
+	})
 }
 
-func (s *Store) AddTagToTask(taskID, tagID uuid.UUID) error {
+func (s *Store) AddTagToTask(taskID, tagID uuid.UUID, activity *models.ActivityEvent) error {
 	query := `
 		INSERT INTO task_tags (task_id, tag_id, created_at)
 		VALUES ($1, $2, NOW())
 		ON CONFLICT (task_id, tag_id) DO NOTHING
 	`
-	_, err := s.db.Exec(query, taskID, tagID)
-	if err != nil {
-		return fmt.Errorf("failed to add tag to task: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, taskID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to add tag to task: %w", err)
+		}
+		return nil
+	})
 }
 
-func (s *Store) RemoveTagFromTask(taskID, tagID uuid.UUID) error {
+func (s *Store) RemoveTagFromTask(taskID, tagID uuid.UUID, activity *models.ActivityEvent) error {
 	query := `DELETE FROM task_tags WHERE task_id = $1 AND tag_id = $2`
-	_, err := s.db.Exec(query, taskID, tagID)
-	if err != nil {
-		return fmt.Errorf("failed to remove tag from task: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, taskID, tagID)
+		if err != nil {
+			return fmt.Errorf("failed to remove tag from task: %w", err)
+		}
+		return nil
+	})
 }
 
 func (s *Store) GetTagsByTask(taskID uuid.UUID) ([]models.Tag, error) {
@@ -120,10 +131,10 @@ func (s *Store) GetTasksByTags(projectID uuid.UUID, tagIDs []uuid.UUID) ([]model
 
 	var tasks []models.Task
 	query := `
-	SELECT DISTINCT t.* FROM tasks t
-	INNER JOIN task_tags tt ON t.id = tt.task_id
-	WHERE t.project_id = $1 AND tt.tag_id = ANY($2::uuid[])
-	ORDER BY t.created_at DESC
+	SELECT t.* FROM tasks t
+	WHERE t.project_id = $1
+	  AND EXISTS (SELECT 1 FROM task_tags tt WHERE tt.task_id = t.id AND tt.tag_id = ANY($2::uuid[]))
+	ORDER BY t.rank COLLATE "C", t.created_at DESC
 	`
 
 	tagStrings := make([]string, len(tagIDs))
@@ -138,27 +149,16 @@ func (s *Store) GetTasksByTags(projectID uuid.UUID, tagIDs []uuid.UUID) ([]model
 	return tasks, nil
 }
 
-func (s *Store) GetTasksWithTags(projectID uuid.UUID, filterTagIDs []uuid.UUID) ([]models.Task, error) {
-	var tasks []models.Task
-	var err error
-
-	if len(filterTagIDs) > 0 {
-		tasks, err = s.GetTasksByTags(projectID, filterTagIDs)
-	} else {
-		tasks, err = s.GetTasksByProject(projectID)
-	}
-
+func (s *Store) GetTaskTagsByProject(projectID uuid.UUID) ([]models.TaskTag, error) {
+	var taskTags []models.TaskTag
+	query := `
+		SELECT tt.* FROM task_tags tt
+		INNER JOIN tasks t ON t.id = tt.task_id
+		WHERE t.project_id = $1
+	`
+	err := s.db.Select(&taskTags, query, projectID)
 	if err != nil {
-		return nil, err
-	}
-
-	for i := range tasks {
-		tags, err := s.GetTagsByTask(tasks[i].ID)
-		if err != nil {
-			return nil, err
-		}
-		tasks[i].Tags = tags
+		return nil, fmt.Errorf("failed to get task tags: %w", err)
 	}
-
-	return tasks, nil
+	return taskTags, nil
 }
@@ -4,27 +4,53 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
 )
 
-func (s *Store) CreateTask(task *models.Task) error {
+func (s *Store) CreateTask(task *models.Task, activity *models.ActivityEvent) error {
 	query := `
-        INSERT INTO tasks (id, project_id, title, description, status, priority, due_date, assigned_to, created_by, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+        INSERT INTO tasks (id, project_id, title, description, description_html, status, priority, due_date, assigned_to, rank, parent_id, created_by, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
     `
-	_, err := s.db.Exec(query, task.ID, task.ProjectID, task.Title, task.Description,
-		task.Status, task.Priority, task.DueDate, task.AssignedTo, task.CreatedBy, task.CreatedAt, task.UpdatedAt)
-	if err != nil {
-		return fmt.Errorf("failed to create task: %w", err)
-	}
-	return nil
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := lockProject(tx, task.ProjectID); err != nil {
+			return err
+		}
+		if err := checkParent(tx, task); err != nil {
+			return err
+		}
+		if err := checkWIPLimit(tx, task.ProjectID, task.Status, task.ID); err != nil {
+			return err
+		}
+
+		// New tasks go to the top of their column.
+		column, err := columnTasks(tx, task.ProjectID, task.Status, task.ID)
+		if err != nil {
+			return err
+		}
+		if task.Rank, err = rankAt(tx, column, 0); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(query, task.ID, task.ProjectID, task.Title, task.Description, task.DescriptionHTML, task.Status,
+			task.Priority, task.DueDate, task.AssignedTo, task.Rank, task.ParentID, task.CreatedBy, task.CreatedAt, task.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create task: %w", err)
+		}
+		if err := insertAssignees(tx, task.ID, task.Assignees, &task.CreatedBy, task.CreatedAt); err != nil {
+			return err
+		}
+		return recordRevision(tx, task.ID, &task.CreatedBy, models.TaskSnapshot{}, models.SnapshotOf(task), nil)
+	})
 }
 
 func (s *Store) GetTasksByProject(projectID uuid.UUID) ([]models.Task, error) {
 	var tasks []models.Task
-	query := `SELECT * FROM tasks WHERE project_id = $1 ORDER BY created_at DESC`
+	query := `SELECT * FROM tasks WHERE project_id = $1 ORDER BY rank COLLATE "C", created_at DESC`
 	err := s.db.Select(&tasks, query, projectID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks: %w", err)
@@ -45,43 +71,222 @@ func (s *Store) GetTaskByID(id uuid.UUID) (*models.Task, error) {
 	return &task, nil
 }
 
-func (s *Store) UpdateTask(task *models.Task) error {
+func (s *Store) UpdateTask(task *models.Task, activity *models.ActivityEvent) error {
+	return s.updateTask(task, activity, nil)
+}
+
+// RevertTask saves a task whose fields were restored from an earlier revision;
+// the new revision records which one it was reverted from.
+func (s *Store) RevertTask(task *models.Task, revision int, activity *models.ActivityEvent) error {
+	return s.updateTask(task, activity, &revision)
+}
+
+func (s *Store) updateTask(task *models.Task, activity *models.ActivityEvent, revertedFrom *int) error {
 	query := `
         UPDATE tasks
-        SET title = $1, description = $2, status = $3, priority = $4, due_date = $5, assigned_to = $6, updated_at = $7
-        WHERE id = $8
+        SET title = $1, description = $2, description_html = $3, status = $4, priority = $5, due_date = $6,
+            assigned_to = $7, rank = $8, parent_id = $9, updated_at = $10
+        WHERE id = $11
     `
-	_, err := s.db.Exec(query, task.Title, task.Description, task.Status,
-		task.Priority, task.DueDate, task.AssignedTo, task.UpdatedAt, task.ID)
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := lockProject(tx, task.ProjectID); err != nil {
+			return err
+		}
+		var current models.Task
+		err := tx.Get(&current, `SELECT * FROM tasks WHERE id = $1 FOR UPDATE`, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock task: %w", err)
+		}
+		if !sameUUID(task.ParentID, current.ParentID) {
+			if err := checkParent(tx, task); err != nil {
+				return err
+			}
+		}
+
+		// A task entering another column is placed at its top.
+		task.Rank = current.Rank
+		if task.Status != current.Status {
+			if err := checkWIPLimit(tx, task.ProjectID, task.Status, task.ID); err != nil {
+				return err
+			}
+			column, err := columnTasks(tx, task.ProjectID, task.Status, task.ID)
+			if err != nil {
+				return err
+			}
+			if task.Rank, err = rankAt(tx, column, 0); err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Exec(query, task.Title, task.Description, task.DescriptionHTML, task.Status,
+			task.Priority, task.DueDate, task.AssignedTo, task.Rank, task.ParentID, task.UpdatedAt, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update task: %w", err)
+		}
+
+		var changedBy *uuid.UUID
+		if activity != nil {
+			changedBy = activity.ActorID
+		}
+		// The primary assignee is always one of the assignees.
+		if task.AssignedTo != nil && !sameUUID(task.AssignedTo, current.AssignedTo) {
+			if err := insertAssignees(tx, task.ID, []uuid.UUID{*task.AssignedTo}, changedBy, task.UpdatedAt); err != nil {
+				return err
+			}
+		}
+		return recordRevision(tx, task.ID, changedBy, models.SnapshotOf(&current), models.SnapshotOf(task), revertedFrom)
+	})
+}
+
+// MoveTask puts a task into its (possibly new) status column between the given
+// neighbors and records the move.
+func (s *Store) MoveTask(task *models.Task, beforeID, afterID *uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := lockProject(tx, task.ProjectID); err != nil {
+			return err
+		}
+		var current models.Task
+		err := tx.Get(&current, `SELECT * FROM tasks WHERE id = $1 FOR UPDATE`, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to lock task: %w", err)
+		}
+		if task.Status != current.Status {
+			if err := checkWIPLimit(tx, task.ProjectID, task.Status, task.ID); err != nil {
+				return err
+			}
+		}
+
+		column, err := columnTasks(tx, task.ProjectID, task.Status, task.ID)
+		if err != nil {
+			return err
+		}
+		idx, err := neighborIndex(column, beforeID, afterID)
+		if err != nil {
+			return err
+		}
+		if task.Rank, err = rankAt(tx, column, idx); err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`UPDATE tasks SET status = $1, rank = $2, updated_at = $3 WHERE id = $4`,
+			task.Status, task.Rank, task.UpdatedAt, task.ID)
+		if err != nil {
+			return fmt.Errorf("failed to move task: %w", err)
+		}
+
+		// The rank is only known here, so the event is diffed again.
+		var changedBy *uuid.UUID
+		if activity != nil {
+			activity.Before, activity.After = diffSnapshots(current, task)
+			changedBy = activity.ActorID
+		}
+		return recordRevision(tx, task.ID, changedBy, models.SnapshotOf(&current), models.SnapshotOf(task), nil)
+	})
+}
+
+func sameUUID(a, b *uuid.UUID) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func recordRevision(tx *sqlx.Tx, taskID uuid.UUID, changedBy *uuid.UUID, before, after models.TaskSnapshot, revertedFrom *int) error {
+	changes := models.DiffSnapshots(before, after)
+	if len(changes) == 0 && revertedFrom == nil {
+		return nil
+	}
+
+	query := `
+        INSERT INTO task_revisions (id, task_id, revision, changed_by, changes, snapshot, reverted_from, created_at)
+        SELECT $1, $2, COALESCE(MAX(revision), 0) + 1, $3, $4, $5, $6, $7
+        FROM task_revisions WHERE task_id = $2
+    `
+	_, err := tx.Exec(query, uuid.New(), taskID, changedBy, changes, after, revertedFrom, time.Now())
 	if err != nil {
-		return fmt.Errorf("failed to update task: %w", err)
+		return fmt.Errorf("failed to record task revision: %w", err)
 	}
 	return nil
 }
 
-func (s *Store) DeleteTask(id uuid.UUID) error {
-	query := `DELETE FROM tasks WHERE id = $1`
-	_, err := s.db.Exec(query, id)
+func (s *Store) GetTaskRevisions(taskID uuid.UUID) ([]models.TaskRevision, error) {
+	var revisions []models.TaskRevision
+	query := `SELECT * FROM task_revisions WHERE task_id = $1 ORDER BY revision ASC`
+	if err := s.db.Select(&revisions, query, taskID); err != nil {
+		return nil, fmt.Errorf("failed to get task revisions: %w", err)
+	}
+	return revisions, nil
+}
+
+func (s *Store) GetTaskRevision(taskID uuid.UUID, revision int) (*models.TaskRevision, error) {
+	var rev models.TaskRevision
+	query := `SELECT * FROM task_revisions WHERE task_id = $1 AND revision = $2`
+	err := s.db.Get(&rev, query, taskID, revision)
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get task revision: %w", err)
 	}
-	return nil
+	return &rev, nil
 }
 
-func (s *Store) GetTasksWithUpcomingDeadlines(projectID uuid.UUID, withinHours int) ([]models.Task, error) {
+func (s *Store) DeleteTask(id uuid.UUID, activity *models.ActivityEvent) error {
+	query := `DELETE FROM tasks WHERE id = $1`
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, id)
+		if err != nil {
+			return fmt.Errorf("failed to delete task: %w", err)
+		}
+		return nil
+	})
+}
+
+// taskInvolvesUser matches tasks the user is assigned to or watches.
+const taskInvolvesUser = `(
+    EXISTS (SELECT 1 FROM task_assignees a WHERE a.task_id = t.id AND a.user_id = $%[1]d)
+    OR EXISTS (SELECT 1 FROM task_watchers w WHERE w.task_id = t.id AND w.user_id = $%[1]d)
+)`
+
+// GetTasksWithUpcomingDeadlines lists the open tasks of a project due within
+// the given hours, overdue ones included. With userID set only tasks the user
+// is assigned to or watches are listed.
+func (s *Store) GetTasksWithUpcomingDeadlines(projectID uuid.UUID, withinHours int, userID *uuid.UUID) ([]models.Task, error) {
 	var tasks []models.Task
 	query := `
-        SELECT * FROM tasks 
-        WHERE project_id = $1 
-          AND due_date IS NOT NULL 
-          AND due_date <= NOW() + $2::interval
-          AND status != 'done'
-        ORDER BY due_date ASC
-    `
-	interval := fmt.Sprintf("%d hours", withinHours)
-	err := s.db.Select(&tasks, query, projectID, interval)
+        SELECT t.* FROM tasks t
+        WHERE t.project_id = $1
+          AND t.due_date IS NOT NULL
+          AND t.due_date <= NOW() + $2::interval
+          AND NOT ` + taskDoneCondition("t")
+	args := []interface{}{projectID, fmt.Sprintf("%d hours", withinHours)}
+	if userID != nil {
+		args = append(args, *userID)
+		query += " AND " + fmt.Sprintf(taskInvolvesUser, len(args))
+	}
+	query += ` ORDER BY t.due_date ASC`
+
+	err := s.db.Select(&tasks, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get tasks with deadlines: %w", err)
 	}
 	return tasks, nil
 }
+
+// GetUndatedTasksForUser lists the open tasks of a project without a due date
+// that the user is assigned to or watches.
+func (s *Store) GetUndatedTasksForUser(projectID, userID uuid.UUID) ([]models.Task, error) {
+	var tasks []models.Task
+	query := `
+        SELECT t.* FROM tasks t
+        WHERE t.project_id = $1
+          AND t.due_date IS NULL
+          AND NOT ` + taskDoneCondition("t") + `
+          AND ` + fmt.Sprintf(taskInvolvesUser, 2) + `
+        ORDER BY t.rank COLLATE "C", t.created_at DESC
+    `
+	if err := s.db.Select(&tasks, query, projectID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get undated tasks: %w", err)
+	}
+	return tasks, nil
+}
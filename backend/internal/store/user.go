@@ -51,10 +51,13 @@ func (s *Store) SearchUsers(query string, excludeProjectID *uuid.UUID, limit int
 
 func (s *Store) CreateUser(user *models.User) error {
 	query := `
-        INSERT INTO users (id, email, password, name, created_at, updated_at)
-        VALUES ($1, $2, $3, $4, $5, $6)
+        INSERT INTO users (id, email, password, name, timezone, site_role, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
     `
-	_, err := s.db.Exec(query, user.ID, user.Email, user.Password, user.Name, user.CreatedAt, user.UpdatedAt)
+	if user.SiteRole == "" {
+		user.SiteRole = models.SiteRoleUser
+	}
+	_, err := s.db.Exec(query, user.ID, user.Email, user.Password, user.Name, user.Timezone, user.SiteRole, user.CreatedAt, user.UpdatedAt)
 	if err != nil {
 		return fmt.Errorf("failed to create user: %w", err)
 	}
@@ -87,6 +90,19 @@ func (s *Store) GetUserByID(id uuid.UUID) (*models.User, error) {
 	return &user, nil
 }
 
+func (s *Store) UpdateUserProfile(user *models.User) error {
+	query := `UPDATE users SET name = $1, timezone = $2, updated_at = $3 WHERE id = $4`
+	_, err := s.db.Exec(query, user.Name, user.Timezone, user.UpdatedAt, user.ID)
+	if err != nil {
+		return fmt.Errorf("failed to update user: %w", err)
+	}
+	return nil
+}
+
+// SystemUserEmail identifies the user that owns the seeded and imported
+// global constants and formulas.
+const SystemUserEmail = "physics-constants@system.local"
+
 func (s *Store) IsSystemUser(userID uuid.UUID) (bool, error) {
 	var email string
 	query := `SELECT email FROM users WHERE id = $1`
@@ -97,7 +113,5 @@ func (s *Store) IsSystemUser(userID uuid.UUID) (bool, error) {
 		}
 		return false, fmt.Errorf("failed to check system user: %w", err)
 	}
-	const SystemUserEmail = "physics-constants@system.local"
-
 	return email == SystemUserEmail, nil
 }
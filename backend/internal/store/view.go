@@ -0,0 +1,77 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+)
+
+func (s *Store) CreateView(view *models.SavedView, activity *models.ActivityEvent) error {
+	query := `
+        INSERT INTO saved_views (id, project_id, owner_id, name, query, group_by, visibility, created_at, updated_at)
+        VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+    `
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, view.ID, view.ProjectID, view.OwnerID, view.Name, view.Query,
+			view.GroupBy, view.Visibility, view.CreatedAt, view.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to create view: %w", err)
+		}
+		return nil
+	})
+}
+
+// GetViewsForUser lists the views of a project the user can see: their own
+// and those shared with the project.
+func (s *Store) GetViewsForUser(projectID, userID uuid.UUID) ([]models.SavedView, error) {
+	views := []models.SavedView{}
+	query := `
+        SELECT * FROM saved_views
+        WHERE project_id = $1 AND (owner_id = $2 OR visibility = 'project')
+        ORDER BY name ASC, created_at ASC
+    `
+	if err := s.db.Select(&views, query, projectID, userID); err != nil {
+		return nil, fmt.Errorf("failed to get views: %w", err)
+	}
+	return views, nil
+}
+
+func (s *Store) GetViewByID(id uuid.UUID) (*models.SavedView, error) {
+	var view models.SavedView
+	err := s.db.Get(&view, `SELECT * FROM saved_views WHERE id = $1`, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get view: %w", err)
+	}
+	return &view, nil
+}
+
+func (s *Store) UpdateView(view *models.SavedView, activity *models.ActivityEvent) error {
+	query := `
+        UPDATE saved_views
+        SET name = $1, query = $2, group_by = $3, visibility = $4, updated_at = $5
+        WHERE id = $6
+    `
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		_, err := tx.Exec(query, view.Name, view.Query, view.GroupBy, view.Visibility, view.UpdatedAt, view.ID)
+		if err != nil {
+			return fmt.Errorf("failed to update view: %w", err)
+		}
+		return nil
+	})
+}
+
+func (s *Store) DeleteView(id uuid.UUID, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM saved_views WHERE id = $1`, id); err != nil {
+			return fmt.Errorf("failed to delete view: %w", err)
+		}
+		return nil
+	})
+}
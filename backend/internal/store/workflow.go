@@ -0,0 +1,155 @@
+package store
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/itmo-pride/student-taskboard/backend/internal/models"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+)
+
+var ErrStatusInUse = errors.New("status in use")
+
+// taskDoneCondition matches tasks (under the given alias) whose status belongs
+// to the done category of their project's workflow. Projects without a
+// workflow use the default one, where only "done" is done.
+func taskDoneCondition(alias string) string {
+	return fmt.Sprintf(`(
+    CASE WHEN EXISTS (SELECT 1 FROM project_workflows w WHERE w.project_id = %[1]s.project_id)
+    THEN EXISTS (
+        SELECT 1 FROM project_workflows w, jsonb_array_elements(w.statuses) st
+        WHERE w.project_id = %[1]s.project_id AND st->>'key' = %[1]s.status AND st->>'category' = 'done'
+    )
+    ELSE %[1]s.status = 'done' END
+)`, alias)
+}
+
+// GetWorkflow returns the project's workflow, or the default workflow when
+// the project has not defined one.
+func (s *Store) GetWorkflow(projectID uuid.UUID) (*models.Workflow, error) {
+	var workflow models.Workflow
+	query := `SELECT * FROM project_workflows WHERE project_id = $1`
+	err := s.db.Get(&workflow, query, projectID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return models.DefaultWorkflow(projectID), nil
+		}
+		return nil, fmt.Errorf("failed to get workflow: %w", err)
+	}
+	return &workflow, nil
+}
+
+// SaveWorkflow stores a validated workflow. Tasks in a renamed status are moved
+// to its new key; the save is rejected if any task would be left in a status
+// the workflow no longer has.
+func (s *Store) SaveWorkflow(workflow *models.Workflow, renames map[string]string, activity *models.ActivityEvent) error {
+	return s.withActivity(activity, func(tx *sqlx.Tx) error {
+		if err := lockProject(tx, workflow.ProjectID); err != nil {
+			return err
+		}
+
+		for from, to := range renames {
+			if err := renameTaskStatus(tx, workflow.ProjectID, from, to, activity); err != nil {
+				return err
+			}
+		}
+
+		keys := make([]string, len(workflow.Statuses))
+		for i, st := range workflow.Statuses {
+			keys[i] = st.Key
+		}
+
+		var orphaned []struct {
+			Status string `db:"status"`
+			Count  int    `db:"count"`
+		}
+		err := tx.Select(&orphaned, `
+            SELECT status, COUNT(*) as count FROM tasks
+            WHERE project_id = $1 AND NOT (status = ANY($2))
+            GROUP BY status ORDER BY status
+        `, workflow.ProjectID, pq.Array(keys))
+		if err != nil {
+			return fmt.Errorf("failed to check task statuses: %w", err)
+		}
+		if len(orphaned) > 0 {
+			parts := make([]string, len(orphaned))
+			for i, o := range orphaned {
+				parts[i] = fmt.Sprintf("%q (%d tasks)", o.Status, o.Count)
+			}
+			return fmt.Errorf("%w: %s still used, rename or move these tasks first", ErrStatusInUse, strings.Join(parts, ", "))
+		}
+
+		query := `
+            INSERT INTO project_workflows (project_id, statuses, transitions, block_open_subtasks, block_on_dependencies, updated_at)
+            VALUES ($1, $2, $3, $4, $5, $6)
+            ON CONFLICT (project_id) DO UPDATE
+            SET statuses = EXCLUDED.statuses, transitions = EXCLUDED.transitions,
+                block_open_subtasks = EXCLUDED.block_open_subtasks,
+                block_on_dependencies = EXCLUDED.block_on_dependencies, updated_at = EXCLUDED.updated_at
+        `
+		_, err = tx.Exec(query, workflow.ProjectID, workflow.Statuses, workflow.Transitions,
+			workflow.BlockOpenSubtasks, workflow.BlockOnDependencies, workflow.UpdatedAt)
+		if err != nil {
+			return fmt.Errorf("failed to save workflow: %w", err)
+		}
+		return nil
+	})
+}
+
+func renameTaskStatus(tx *sqlx.Tx, projectID uuid.UUID, from, to string, activity *models.ActivityEvent) error {
+	var tasks []models.Task
+	err := tx.Select(&tasks, `SELECT * FROM tasks WHERE project_id = $1 AND status = $2 FOR UPDATE`, projectID, from)
+	if err != nil {
+		return fmt.Errorf("failed to get tasks for status rename: %w", err)
+	}
+
+	var changedBy *uuid.UUID
+	if activity != nil {
+		changedBy = activity.ActorID
+	}
+
+	now := time.Now()
+	for i := range tasks {
+		before := models.SnapshotOf(&tasks[i])
+		tasks[i].Status = to
+		_, err := tx.Exec(`UPDATE tasks SET status = $1, updated_at = $2 WHERE id = $3`, to, now, tasks[i].ID)
+		if err != nil {
+			return fmt.Errorf("failed to rename task status: %w", err)
+		}
+		if err := recordRevision(tx, tasks[i].ID, changedBy, before, models.SnapshotOf(&tasks[i]), nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkWIPLimit rejects a task entering status when the column already
+// holds the workflow's WIP limit of other tasks. Callers hold lockProject, so
+// concurrent moves into the column cannot both pass.
+func checkWIPLimit(tx *sqlx.Tx, projectID uuid.UUID, status string, taskID uuid.UUID) error {
+	workflow := models.DefaultWorkflow(projectID)
+	err := tx.Get(workflow, `SELECT * FROM project_workflows WHERE project_id = $1`, projectID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("failed to get workflow: %w", err)
+	}
+	st, ok := workflow.Status(status)
+	if !ok || st.WIPLimit == nil {
+		return nil
+	}
+
+	var count int
+	query := `SELECT COUNT(*) FROM tasks WHERE project_id = $1 AND status = $2 AND id != $3`
+	if err := tx.Get(&count, query, projectID, status, taskID); err != nil {
+		return fmt.Errorf("failed to count tasks: %w", err)
+	}
+	if count >= *st.WIPLimit {
+		return fmt.Errorf("%w: status %q already has %d of %d tasks",
+			models.ErrWIPLimitReached, status, count, *st.WIPLimit)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package units
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// ErrOutOfRange reports a unit scale or converted value too large or too
+// small to represent.
+var ErrOutOfRange = errors.New("result out of range")
+
+// IncompatibleError reports a conversion between units of different
+// dimensions.
+type IncompatibleError struct {
+	From Dimension
+	To   Dimension
+}
+
+func (e *IncompatibleError) Error() string {
+	return fmt.Sprintf("incompatible units: %s cannot be converted to %s", e.From, e.To)
+}
+
+// Convert converts value from one unit to another of the same dimension.
+// The result is rounded to 15 significant digits, which hides the binary
+// rounding of scales like 1e-10 without losing any measured precision.
+// Results too large for a float64 fail with ErrOutOfRange.
+func Convert(value float64, from, to Unit) (float64, error) {
+	if from.Dim != to.Dim {
+		return 0, &IncompatibleError{From: from.Dim, To: to.Dim}
+	}
+	return checkRange(round15(to.FromSI(from.ToSI(value))))
+}
+
+// ConvertUncertainty converts an uncertainty, or any other difference of
+// values, which only scales: 1 K of uncertainty is 1 °C, not -272.15 °C.
+func ConvertUncertainty(delta float64, from, to Unit) (float64, error) {
+	if from.Dim != to.Dim {
+		return 0, &IncompatibleError{From: from.Dim, To: to.Dim}
+	}
+	return checkRange(round15(delta * from.Scale / to.Scale))
+}
+
+func checkRange(v float64) (float64, error) {
+	if math.IsInf(v, 0) || math.IsNaN(v) {
+		return 0, ErrOutOfRange
+	}
+	return v, nil
+}
+
+func round15(v float64) float64 {
+	rounded, err := strconv.ParseFloat(strconv.FormatFloat(v, 'g', 15, 64), 64)
+	if err != nil {
+		return v
+	}
+	return rounded
+}
+
+// namedUnits are the coherent SI units with special names that Name reports,
+// in order of preference where several share a dimension.
+var namedUnits = []string{"N", "J", "W", "Pa", "C", "V", "F", "Ω", "S", "Wb", "T", "H", "Hz"}
+
+// Name returns the symbol of the base or named coherent SI unit with
+// dimension d, such as "N" for kg·m·s^-2, or "" if there is none.
+func (d Dimension) Name() string {
+	for i, symbol := range baseSymbols {
+		var base Dimension
+		base[i] = 1
+		if d == base {
+			return symbol
+		}
+	}
+	for _, symbol := range namedUnits {
+		if registry[symbol].unit.Dim == d {
+			return symbol
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		value    float64
+		from, to string
+		want     float64
+	}{
+		{36, "km/h", "m/s", 10},
+		{1, "m/s", "km/h", 3.6},
+		{212, "°F", "°C", 100},
+		{-40, "°F", "°C", -40},
+		{0, "°C", "K", 273.15},
+		{1, "eV/K", "J/K", 1.602176634e-19},
+		{1, "Å", "nm", 0.1},
+		{1, "kWh", "MJ", 3.6},
+	}
+	for _, tt := range tests {
+		got, err := Convert(tt.value, MustParse(tt.from), MustParse(tt.to))
+		if err != nil {
+			t.Errorf("Convert(%g %s to %s): %v", tt.value, tt.from, tt.to, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-12*math.Max(1, math.Abs(tt.want)) {
+			t.Errorf("Convert(%g %s to %s) = %g, want %g", tt.value, tt.from, tt.to, got, tt.want)
+		}
+	}
+}
+
+func TestConvertErrors(t *testing.T) {
+	var incompatible *IncompatibleError
+	if _, err := Convert(1, MustParse("m"), MustParse("s")); !errors.As(err, &incompatible) {
+		t.Errorf("Convert(m to s) error = %v, want *IncompatibleError", err)
+	}
+
+	big, small := MustParse("Qm^10"), MustParse("qm^10")
+	if _, err := Convert(1, big, small); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Convert(Qm^10 to qm^10) error = %v, want ErrOutOfRange", err)
+	}
+	if _, err := ConvertUncertainty(1, big, small); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("ConvertUncertainty(Qm^10 to qm^10) error = %v, want ErrOutOfRange", err)
+	}
+	if _, err := Convert(math.MaxFloat64, MustParse("km"), MustParse("m")); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Convert(MaxFloat64 km to m) error = %v, want ErrOutOfRange", err)
+	}
+}
+
+func TestConvertUncertainty(t *testing.T) {
+	// Differences only scale: 1 K of uncertainty is 1 °C and 1.8 °F.
+	got, err := ConvertUncertainty(1, MustParse("K"), MustParse("°F"))
+	if err != nil || math.Abs(got-1.8) > 1e-12 {
+		t.Errorf("ConvertUncertainty(1 K to °F) = %g, %v, want 1.8", got, err)
+	}
+}
@@ -0,0 +1,227 @@
+package units
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Error reports a unit or value that cannot be parsed. Kind is "unit" or
+// "value".
+type Error struct {
+	Kind    string
+	Input   string
+	Message string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("invalid %s %q: %s", e.Kind, e.Input, e.Message)
+}
+
+var superscripts = strings.NewReplacer(
+	"⁰", "0", "¹", "1", "²", "2", "³", "3", "⁴", "4",
+	"⁵", "5", "⁶", "6", "⁷", "7", "⁸", "8", "⁹", "9", "⁻", "-", "⁺", "+",
+)
+
+// Parse parses a unit such as "m/s", "J·K^-1", "kg m² s⁻²" or "J/(mol·K)".
+// Factors are separated by "·", "*", "." or spaces; exponents are integers
+// written with "^", as superscripts or directly after the symbol ("s-1").
+// As the SI brochure recommends, a product directly after "/" must be
+// parenthesized, so "J/mol·K" is rejected as ambiguous. An empty string or
+// "1" is dimensionless.
+func Parse(input string) (Unit, error) {
+	src := strings.TrimSpace(input)
+	if src == "" || src == "1" {
+		return Unit{Scale: 1}, nil
+	}
+	if def, ok := registry[src]; ok && def.unit.Offset != 0 {
+		return def.unit, nil
+	}
+
+	p := &unitParser{input: input, src: []rune(superscripts.Replace(src))}
+	u, err := p.parseProduct()
+	if err != nil {
+		return Unit{}, err
+	}
+	if p.pos < len(p.src) {
+		return Unit{}, p.errorf("unexpected %q", string(p.src[p.pos]))
+	}
+	return u, nil
+}
+
+// MustParse is like Parse but panics on error. It is meant for units known
+// at compile time.
+func MustParse(input string) Unit {
+	u, err := Parse(input)
+	if err != nil {
+		panic(err)
+	}
+	return u
+}
+
+type unitParser struct {
+	input string
+	src   []rune
+	pos   int
+}
+
+func (p *unitParser) errorf(format string, args ...interface{}) error {
+	return &Error{Kind: "unit", Input: p.input, Message: fmt.Sprintf(format, args...)}
+}
+
+func (p *unitParser) peek() rune {
+	if p.pos >= len(p.src) {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *unitParser) skipSpaces() bool {
+	skipped := false
+	for p.pos < len(p.src) && unicode.IsSpace(p.src[p.pos]) {
+		p.pos++
+		skipped = true
+	}
+	return skipped
+}
+
+// parseProduct parses factors joined by multiplication and division.
+func (p *unitParser) parseProduct() (Unit, error) {
+	u, err := p.parseFactor()
+	if err != nil {
+		return Unit{}, err
+	}
+	divided := false
+	for {
+		spaced := p.skipSpaces()
+		op := p.peek()
+		switch {
+		case op == '·' || op == '⋅' || op == '*' || op == '.' || op == '×':
+			p.pos++
+			p.skipSpaces()
+		case op == '/':
+			p.pos++
+			p.skipSpaces()
+		case spaced && op != 0 && op != ')':
+			op = ' '
+		default:
+			return u, nil
+		}
+
+		if op != '/' && divided {
+			return Unit{}, p.errorf("ambiguous product after /, use parentheses")
+		}
+		f, err := p.parseFactor()
+		if err != nil {
+			return Unit{}, err
+		}
+		if op == '/' {
+			u = u.Div(f)
+			divided = true
+		} else {
+			u = u.Mul(f)
+		}
+		if !u.finite() {
+			return Unit{}, p.errorf("scale out of range")
+		}
+	}
+}
+
+// parseFactor parses a unit symbol or a parenthesized product, with an
+// optional exponent.
+func (p *unitParser) parseFactor() (Unit, error) {
+	var u Unit
+	switch r := p.peek(); {
+	case r == '(':
+		p.pos++
+		p.skipSpaces()
+		inner, err := p.parseProduct()
+		if err != nil {
+			return Unit{}, err
+		}
+		p.skipSpaces()
+		if p.peek() != ')' {
+			return Unit{}, p.errorf("missing )")
+		}
+		p.pos++
+		u = inner
+	case r == '1':
+		// "1/s" is a valid way to write s^-1.
+		p.pos++
+		return Unit{Scale: 1}, nil
+	case isSymbolRune(r):
+		start := p.pos
+		for p.pos < len(p.src) && isSymbolRune(p.src[p.pos]) {
+			p.pos++
+		}
+		symbol := string(p.src[start:p.pos])
+		found, ok := lookup(symbol)
+		if !ok {
+			return Unit{}, p.errorf("unknown unit %q", symbol)
+		}
+		if found.Offset != 0 {
+			return Unit{}, p.errorf("%s cannot be combined with other units", symbol)
+		}
+		u = found
+	case r == 0:
+		return Unit{}, p.errorf("missing unit")
+	default:
+		return Unit{}, p.errorf("unexpected %q", string(r))
+	}
+
+	exp, ok, err := p.parseExponent()
+	if err != nil {
+		return Unit{}, err
+	}
+	if ok {
+		if u, err = u.Pow(exp); err != nil {
+			return Unit{}, p.errorf("scale out of range")
+		}
+	}
+	return u, nil
+}
+
+// parseExponent reads "^n", "^(n)" or a signed integer right after a factor.
+func (p *unitParser) parseExponent() (int, bool, error) {
+	caret := p.peek() == '^'
+	if caret {
+		p.pos++
+	}
+	paren := caret && p.peek() == '('
+	if paren {
+		p.pos++
+	}
+
+	start := p.pos
+	if r := p.peek(); r == '-' || r == '+' || r == '−' {
+		p.pos++
+	}
+	for p.pos < len(p.src) && p.src[p.pos] >= '0' && p.src[p.pos] <= '9' {
+		p.pos++
+	}
+	digits := strings.Replace(string(p.src[start:p.pos]), "−", "-", 1)
+	if digits == "" || digits == "-" || digits == "+" {
+		if caret {
+			return 0, false, p.errorf("missing exponent")
+		}
+		p.pos = start
+		return 0, false, nil
+	}
+	if paren {
+		if p.peek() != ')' {
+			return 0, false, p.errorf("missing )")
+		}
+		p.pos++
+	}
+
+	exp, err := strconv.Atoi(digits)
+	if err != nil || exp < -20 || exp > 20 {
+		return 0, false, p.errorf("invalid exponent %s", digits)
+	}
+	return exp, true, nil
+}
+
+func isSymbolRune(r rune) bool {
+	return unicode.IsLetter(r) || r == '°' || r == '%'
+}
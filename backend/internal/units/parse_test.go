@@ -0,0 +1,82 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in    string
+		scale float64
+		dim   Dimension
+	}{
+		{"", 1, Dimension{}},
+		{"1", 1, Dimension{}},
+		{"m", 1, dim(1)},
+		{"km/h", 1 / 3.6, dim(1, 0, -1)},
+		{"kg m² s⁻²", 1, dim(2, 1, -2)},
+		{"J·K^-1", 1, dim(2, 1, -2, 0, -1)},
+		{"J/(mol·K)", 1, dim(2, 1, -2, 0, -1, -1)},
+		{"eV/K", 1.602176634e-19, dim(2, 1, -2, 0, -1)},
+		{"s-1", 1, dim(0, 0, -1)},
+		{"1/s", 1, dim(0, 0, -1)},
+		{"µm", 1e-6, dim(1)},
+		{"dam", 10, dim(1)},
+		{"°C", 1, dim(0, 0, 0, 0, 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			u, err := Parse(tt.in)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.in, err)
+			}
+			if math.Abs(u.Scale-tt.scale) > 1e-12*tt.scale {
+				t.Errorf("Parse(%q).Scale = %g, want %g", tt.in, u.Scale, tt.scale)
+			}
+			if u.Dim != tt.dim {
+				t.Errorf("Parse(%q).Dim = %s, want %s", tt.in, u.Dim, tt.dim)
+			}
+		})
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		message string
+	}{
+		{"J/mol·K", "ambiguous product after /, use parentheses"},
+		{"J/mol K", "ambiguous product after /, use parentheses"},
+		{"furlong", `unknown unit "furlong"`},
+		{"°C/s", `°C cannot be combined with other units`},
+		{"m^", "missing exponent"},
+		{"m^21", "invalid exponent 21"},
+		{"(m/s", "missing )"},
+		{"Qm^20", "scale out of range"},
+		{"qm^-20", "scale out of range"},
+		{"Qm^10·Qm^10", "scale out of range"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := Parse(tt.in)
+			var unitErr *Error
+			if !errors.As(err, &unitErr) {
+				t.Fatalf("Parse(%q) error = %v, want *Error", tt.in, err)
+			}
+			if unitErr.Message != tt.message {
+				t.Errorf("Parse(%q) message = %q, want %q", tt.in, unitErr.Message, tt.message)
+			}
+		})
+	}
+}
+
+func TestPowOutOfRange(t *testing.T) {
+	if _, err := MustParse("Qm").Pow(20); !errors.Is(err, ErrOutOfRange) {
+		t.Errorf("Qm.Pow(20) error = %v, want ErrOutOfRange", err)
+	}
+	if u, err := MustParse("km").Pow(-2); err != nil || u.Scale != 1e-6 || u.Dim != dim(-2) {
+		t.Errorf("km.Pow(-2) = %+v, %v", u, err)
+	}
+}
@@ -0,0 +1,150 @@
+package units
+
+import (
+	"math"
+	"sort"
+	"strings"
+)
+
+type definition struct {
+	unit       Unit
+	prefixable bool
+}
+
+// prefixes are the SI prefixes. "u" is accepted for micro where µ is hard
+// to type.
+var prefixes = map[string]float64{
+	"Q": 1e30, "R": 1e27, "Y": 1e24, "Z": 1e21, "E": 1e18, "P": 1e15, "T": 1e12,
+	"G": 1e9, "M": 1e6, "k": 1e3, "h": 1e2, "da": 1e1, "d": 1e-1, "c": 1e-2,
+	"m": 1e-3, "µ": 1e-6, "μ": 1e-6, "u": 1e-6, "n": 1e-9, "p": 1e-12, "f": 1e-15,
+	"a": 1e-18, "z": 1e-21, "y": 1e-24, "r": 1e-27, "q": 1e-30,
+}
+
+// prefixOrder lists the prefixes longest first so that "da" wins over "d".
+var prefixOrder []string
+
+var registry = map[string]definition{}
+
+func dim(exps ...int) Dimension {
+	var d Dimension
+	copy(d[:], exps)
+	return d
+}
+
+func define(symbols string, scale float64, d Dimension, prefixable bool) {
+	for _, symbol := range strings.Fields(symbols) {
+		registry[symbol] = definition{unit: Unit{Scale: scale, Dim: d}, prefixable: prefixable}
+	}
+}
+
+func init() {
+	for p := range prefixes {
+		prefixOrder = append(prefixOrder, p)
+	}
+	sort.Slice(prefixOrder, func(i, j int) bool {
+		if len(prefixOrder[i]) != len(prefixOrder[j]) {
+			return len(prefixOrder[i]) > len(prefixOrder[j])
+		}
+		return prefixOrder[i] < prefixOrder[j]
+	})
+
+	var (
+		none      = Dimension{}
+		length    = dim(1)
+		mass      = dim(0, 1)
+		time      = dim(0, 0, 1)
+		frequency = dim(0, 0, -1)
+		force     = dim(1, 1, -2)
+		pressure  = dim(-1, 1, -2)
+		energy    = dim(2, 1, -2)
+		power     = dim(2, 1, -3)
+		charge    = dim(0, 0, 1, 1)
+		voltage   = dim(2, 1, -3, -1)
+	)
+
+	// SI base units. The kilogram is defined through the gram so that
+	// prefixes apply to "g".
+	define("m", 1, length, true)
+	define("g", 1e-3, mass, true)
+	define("s", 1, time, true)
+	define("A", 1, dim(0, 0, 0, 1), true)
+	define("K", 1, dim(0, 0, 0, 0, 1), true)
+	define("mol", 1, dim(0, 0, 0, 0, 0, 1), true)
+	define("cd", 1, dim(0, 0, 0, 0, 0, 0, 1), true)
+
+	// SI derived units with special names.
+	define("rad sr", 1, none, true)
+	define("Hz Bq", 1, frequency, true)
+	define("N", 1, force, true)
+	define("Pa", 1, pressure, true)
+	define("J", 1, energy, true)
+	define("W", 1, power, true)
+	define("C", 1, charge, true)
+	define("V", 1, voltage, true)
+	define("F", 1, dim(-2, -1, 4, 2), true)
+	define("Ω ohm", 1, dim(2, 1, -3, -2), true)
+	define("S", 1, dim(-2, -1, 3, 2), true)
+	define("Wb", 1, dim(2, 1, -2, -1), true)
+	define("T", 1, dim(0, 1, -2, -1), true)
+	define("H", 1, dim(2, 1, -2, -2), true)
+	define("lm", 1, dim(0, 0, 0, 0, 0, 0, 1), true)
+	define("lx", 1, dim(-2, 0, 0, 0, 0, 0, 1), true)
+	define("Gy Sv", 1, dim(2, 0, -2), true)
+	define("kat", 1, dim(0, 0, -1, 0, 0, 1), true)
+
+	// Units accepted for use with the SI and common units in physics.
+	define("min", 60, time, false)
+	define("h", 3600, time, false)
+	define("d", 86400, time, false)
+	define("yr", 365.25*86400, time, false)
+	define("L l", 1e-3, dim(3), true)
+	define("M", 1e3, dim(-3, 0, 0, 0, 0, 1), true)
+	define("t", 1e3, mass, false)
+	define("Da u", 1.66053906660e-27, mass, true)
+	define("eV", 1.602176634e-19, energy, true)
+	define("Wh", 3600, energy, true)
+	define("cal", 4.184, energy, true)
+	define("erg", 1e-7, energy, false)
+	define("dyn", 1e-5, force, false)
+	define("bar", 1e5, pressure, true)
+	define("atm", 101325, pressure, false)
+	define("Torr", 101325.0/760, pressure, false)
+	define("mmHg", 133.322387415, pressure, false)
+	define("Å", 1e-10, length, false)
+	define("au", 149597870700, length, false)
+	define("ly", 9460730472580800, length, false)
+	define("pc", 3.0856775814913673e16, length, true)
+	define("in", 0.0254, length, false)
+	define("ft", 0.3048, length, false)
+	define("mi", 1609.344, length, false)
+	define("lb", 0.45359237, mass, false)
+	define("° deg", math.Pi/180, none, false)
+	define("%", 1e-2, none, false)
+
+	// Temperature scales with an offset from kelvin. They can only be used
+	// on their own, see Parse.
+	registry["°C"] = definition{unit: Unit{Scale: 1, Offset: 273.15, Dim: dim(0, 0, 0, 0, 1)}}
+	registry["degC"] = registry["°C"]
+	registry["°F"] = definition{unit: Unit{Scale: 5.0 / 9, Offset: 459.67 * 5 / 9, Dim: dim(0, 0, 0, 0, 1)}}
+	registry["degF"] = registry["°F"]
+}
+
+// lookup resolves a unit symbol, possibly with an SI prefix. Symbols are
+// matched exactly first, so "min" is a minute and "cd" a candela.
+func lookup(symbol string) (Unit, bool) {
+	if def, ok := registry[symbol]; ok {
+		return def.unit, true
+	}
+	for _, p := range prefixOrder {
+		rest := strings.TrimPrefix(symbol, p)
+		if rest == symbol || rest == "" {
+			continue
+		}
+		if def, ok := registry[rest]; ok && def.prefixable {
+			u := def.unit
+			u.Scale *= prefixes[p]
+			return u, true
+		}
+	}
+	return Unit{}, false
+}
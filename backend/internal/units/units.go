@@ -0,0 +1,193 @@
+// Package units parses physical units and measured values.
+//
+// A unit is reduced to a scale factor and a Dimension, the exponents of the
+// seven SI base dimensions, so that "km/h" becomes 1/3.6 m·s^-1. Values are
+// stored as entered together with the parsed unit; multiplying by the scale
+// gives the value in coherent SI units.
+package units
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// The SI base dimensions, in the order the SI brochure lists them.
+const (
+	Length = iota
+	Mass
+	Time
+	Current
+	Temperature
+	Amount
+	Luminosity
+	numBase
+)
+
+// baseSymbols are the SI base units for each dimension.
+var baseSymbols = [numBase]string{"m", "kg", "s", "A", "K", "mol", "cd"}
+
+// Dimension holds the exponent of each base dimension. The zero value is
+// dimensionless.
+type Dimension [numBase]int
+
+func (d Dimension) Mul(o Dimension) Dimension {
+	for i := range d {
+		d[i] += o[i]
+	}
+	return d
+}
+
+func (d Dimension) Div(o Dimension) Dimension {
+	for i := range d {
+		d[i] -= o[i]
+	}
+	return d
+}
+
+func (d Dimension) Pow(n int) Dimension {
+	for i := range d {
+		d[i] *= n
+	}
+	return d
+}
+
+func (d Dimension) IsDimensionless() bool {
+	return d == Dimension{}
+}
+
+// String writes the dimension in base units, e.g. "kg·m^2·s^-1", listing
+// positive exponents first. Dimensionless is "1".
+func (d Dimension) String() string {
+	var parts []string
+	for _, positive := range []bool{true, false} {
+		for _, i := range []int{Mass, Length, Time, Current, Temperature, Amount, Luminosity} {
+			if d[i] == 0 || (d[i] > 0) != positive {
+				continue
+			}
+			part := baseSymbols[i]
+			if d[i] != 1 {
+				part += "^" + strconv.Itoa(d[i])
+			}
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 0 {
+		return "1"
+	}
+	return strings.Join(parts, "·")
+}
+
+// MarshalJSON encodes the non-zero exponents keyed by base unit, e.g.
+// {"m":1,"s":-1}.
+func (d Dimension) MarshalJSON() ([]byte, error) {
+	m := make(map[string]int)
+	for i, exp := range d {
+		if exp != 0 {
+			m[baseSymbols[i]] = exp
+		}
+	}
+	return json.Marshal(m)
+}
+
+func (d *Dimension) UnmarshalJSON(data []byte) error {
+	var m map[string]int
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	*d = Dimension{}
+	for symbol, exp := range m {
+		i := baseIndex(symbol)
+		if i < 0 {
+			return fmt.Errorf("unknown base unit %q", symbol)
+		}
+		d[i] = exp
+	}
+	return nil
+}
+
+// Value stores the dimension as JSON.
+func (d Dimension) Value() (driver.Value, error) {
+	return d.MarshalJSON()
+}
+
+func (d *Dimension) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case []byte:
+		return d.UnmarshalJSON(v)
+	case string:
+		return d.UnmarshalJSON([]byte(v))
+	case nil:
+		*d = Dimension{}
+		return nil
+	}
+	return errors.New("unsupported dimension type")
+}
+
+func baseIndex(symbol string) int {
+	for i, s := range baseSymbols {
+		if s == symbol {
+			return i
+		}
+	}
+	return -1
+}
+
+// Unit is a parsed unit: a value in it equals Scale·value + Offset in the
+// coherent SI unit of Dim. Only temperature scales such as °C have an offset.
+type Unit struct {
+	Scale  float64
+	Offset float64
+	Dim    Dimension
+}
+
+func (u Unit) Mul(o Unit) Unit {
+	return Unit{Scale: u.Scale * o.Scale, Dim: u.Dim.Mul(o.Dim)}
+}
+
+func (u Unit) Div(o Unit) Unit {
+	return Unit{Scale: u.Scale / o.Scale, Dim: u.Dim.Div(o.Dim)}
+}
+
+// Pow raises u to the n-th power. It fails with ErrOutOfRange if the scale
+// overflows or underflows, as Qm^20 does.
+func (u Unit) Pow(n int) (Unit, error) {
+	scale := 1.0
+	for i := 0; i < abs(n); i++ {
+		scale *= u.Scale
+	}
+	if n < 0 {
+		scale = 1 / scale
+	}
+	p := Unit{Scale: scale, Dim: u.Dim.Pow(n)}
+	if !p.finite() {
+		return Unit{}, ErrOutOfRange
+	}
+	return p, nil
+}
+
+// finite reports whether the scale is a usable, finite nonzero number.
+func (u Unit) finite() bool {
+	return u.Scale != 0 && !math.IsInf(u.Scale, 0) && !math.IsNaN(u.Scale)
+}
+
+// ToSI converts a value in u to the coherent SI unit.
+func (u Unit) ToSI(v float64) float64 {
+	return v*u.Scale + u.Offset
+}
+
+// FromSI converts a value in the coherent SI unit to u.
+func (u Unit) FromSI(v float64) float64 {
+	return (v - u.Offset) / u.Scale
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
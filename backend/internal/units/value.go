@@ -0,0 +1,100 @@
+package units
+
+import (
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	numberRe   = regexp.MustCompile(`^[+-]?(?:[0-9]+\.?[0-9]*|\.[0-9]+)(?:[eE][+-]?[0-9]+)?$`)
+	powerOfTen = regexp.MustCompile(`(?:×|x|\*|·|⋅)10\^?\(?([+-]?[0-9]+)\)?$`)
+	conciseRe  = regexp.MustCompile(`^([+-]?)([0-9]*)\.?([0-9]*)\(([0-9]+(?:\.[0-9]+)?)\)((?:[eE][+-]?[0-9]+)?)$`)
+	spaces     = strings.NewReplacer(" ", "", " ", "", " ", "", " ", "", "−", "-", "…", "", "...", "")
+)
+
+// ParseValue parses a number as written in tables of constants and returns
+// it with its standard uncertainty, which is zero if none is given. Besides
+// plain numbers it accepts digit groups ("299 792 458"), powers of ten
+// ("6.674 × 10⁻¹¹"), the concise notation "6.674 30(15)e-11" where the digits
+// in parentheses are the uncertainty in the last digits, and "a ± b". A
+// trailing ellipsis marks a truncated exact value and is ignored.
+func ParseValue(input string) (value, uncertainty float64, err error) {
+	src := superscripts.Replace(spaces.Replace(strings.TrimSpace(input)))
+	if src == "" {
+		return 0, 0, valueError(input, "missing value")
+	}
+
+	if parts := splitPlusMinus(src); len(parts) == 2 {
+		if value, err = parseNumber(input, parts[0]); err != nil {
+			return 0, 0, err
+		}
+		if uncertainty, err = parseNumber(input, parts[1]); err != nil {
+			return 0, 0, err
+		}
+		if uncertainty <= 0 {
+			return 0, 0, valueError(input, "uncertainty must be positive")
+		}
+		return value, uncertainty, nil
+	}
+
+	src = powerOfTen.ReplaceAllString(src, "e$1")
+	if m := conciseRe.FindStringSubmatch(src); m != nil {
+		return parseConcise(input, m)
+	}
+	value, err = parseNumber(input, src)
+	return value, 0, err
+}
+
+func splitPlusMinus(src string) []string {
+	for _, sep := range []string{"±", "+/-", "+-"} {
+		if i := strings.Index(src, sep); i > 0 {
+			return []string{src[:i], src[i+len(sep):]}
+		}
+	}
+	return nil
+}
+
+// parseConcise evaluates the concise notation matched by conciseRe.
+func parseConcise(input string, m []string) (float64, float64, error) {
+	sign, whole, fraction, digits, exponent := m[1], m[2], m[3], m[4], m[5]
+	if whole == "" && fraction == "" {
+		return 0, 0, valueError(input, "missing digits")
+	}
+
+	value, err := parseNumber(input, sign+whole+"."+fraction+"0"+exponent)
+	if err != nil {
+		return 0, 0, err
+	}
+	exp := 0
+	if exponent != "" {
+		if exp, err = strconv.Atoi(exponent[1:]); err != nil {
+			return 0, 0, valueError(input, "invalid exponent")
+		}
+	}
+	// "1.234(5)" means ±0.005, while "1.234(0.005)" spells it out.
+	if !strings.Contains(digits, ".") {
+		exp -= len(fraction)
+	}
+	uncertainty, err := strconv.ParseFloat(digits+"e"+strconv.Itoa(exp), 64)
+	if err != nil || uncertainty <= 0 {
+		return 0, 0, valueError(input, "uncertainty must be positive")
+	}
+	return value, uncertainty, nil
+}
+
+func parseNumber(input, src string) (float64, error) {
+	if !numberRe.MatchString(src) {
+		return 0, valueError(input, "not a number")
+	}
+	v, err := strconv.ParseFloat(src, 64)
+	if err != nil || math.IsInf(v, 0) {
+		return 0, valueError(input, "out of range")
+	}
+	return v, nil
+}
+
+func valueError(input, message string) error {
+	return &Error{Kind: "value", Input: input, Message: message}
+}
@@ -0,0 +1,64 @@
+package units
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+func TestParseValue(t *testing.T) {
+	tests := []struct {
+		in          string
+		value       float64
+		uncertainty float64
+	}{
+		{"42", 42, 0},
+		{"-1.5e3", -1500, 0},
+		{"299 792 458", 299792458, 0},
+		{"6.674 × 10⁻¹¹", 6.674e-11, 0},
+		{"6.674 30(15) × 10⁻¹¹", 6.67430e-11, 0.00015e-11},
+		{"6.67430(15)e-11", 6.67430e-11, 0.00015e-11},
+		{"1.234(0.005)", 1.234, 0.005},
+		{"9.81 ± 0.02", 9.81, 0.02},
+		{"3.14159…", 3.14159, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			value, uncertainty, err := ParseValue(tt.in)
+			if err != nil {
+				t.Fatalf("ParseValue(%q): %v", tt.in, err)
+			}
+			if math.Abs(value-tt.value) > 1e-12*math.Abs(tt.value) {
+				t.Errorf("ParseValue(%q) value = %g, want %g", tt.in, value, tt.value)
+			}
+			if math.Abs(uncertainty-tt.uncertainty) > 1e-12*math.Abs(tt.uncertainty) {
+				t.Errorf("ParseValue(%q) uncertainty = %g, want %g", tt.in, uncertainty, tt.uncertainty)
+			}
+		})
+	}
+}
+
+func TestParseValueErrors(t *testing.T) {
+	tests := []struct {
+		in      string
+		message string
+	}{
+		{"", "missing value"},
+		{"abc", "not a number"},
+		{"1e400", "out of range"},
+		{"1 ± 0", "uncertainty must be positive"},
+		{"1.5(0)", "uncertainty must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, _, err := ParseValue(tt.in)
+			var valueErr *Error
+			if !errors.As(err, &valueErr) {
+				t.Fatalf("ParseValue(%q) error = %v, want *Error", tt.in, err)
+			}
+			if valueErr.Message != tt.message {
+				t.Errorf("ParseValue(%q) message = %q, want %q", tt.in, valueErr.Message, tt.message)
+			}
+		})
+	}
+}
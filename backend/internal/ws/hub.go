@@ -148,7 +148,14 @@ func (h *Hub) handleClear(message *Message) {
 		return
 	}
 
-	if err := h.store.ClearBoard(boardUUID); err != nil {
+	board, err := h.store.GetBoardByID(boardUUID)
+	if err != nil || board == nil {
+		log.Printf("Failed to load board %s for clear: %v", message.BoardID, err)
+		return
+	}
+
+	activity := store.NewActivity(board.ProjectID, message.UserID, models.ActivityCleared, "board", board.ID, nil, nil)
+	if err := h.store.ClearBoard(boardUUID, activity); err != nil {
 		log.Printf("Failed to clear board: %v", err)
 	}
 
@@ -0,0 +1,15 @@
+CREATE TABLE activity_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(50) NOT NULL,
+    entity_type VARCHAR(50) NOT NULL,
+    entity_id UUID NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_activity_events_project_created ON activity_events(project_id, created_at DESC, id DESC);
+CREATE INDEX idx_activity_events_actor ON activity_events(actor_id);
+CREATE INDEX idx_activity_events_entity ON activity_events(entity_type, entity_id);
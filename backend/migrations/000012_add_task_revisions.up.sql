@@ -0,0 +1,30 @@
+CREATE TABLE task_revisions (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    revision INTEGER NOT NULL,
+    changed_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    changes JSONB NOT NULL DEFAULT '[]'::jsonb,
+    snapshot JSONB NOT NULL,
+    reverted_from INTEGER,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    UNIQUE(task_id, revision)
+);
+
+CREATE INDEX idx_task_revisions_task_id ON task_revisions(task_id);
+
+INSERT INTO task_revisions (task_id, revision, changed_by, changes, snapshot, created_at)
+SELECT
+    t.id,
+    1,
+    t.created_by,
+    '[]'::jsonb,
+    jsonb_build_object(
+        'title', t.title,
+        'description', COALESCE(t.description, ''),
+        'status', t.status,
+        'priority', t.priority,
+        'due_date', t.due_date,
+        'assigned_to', t.assigned_to
+    ),
+    t.updated_at
+FROM tasks t;
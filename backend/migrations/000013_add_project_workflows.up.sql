@@ -0,0 +1,9 @@
+CREATE TABLE project_workflows (
+    project_id UUID PRIMARY KEY REFERENCES projects(id) ON DELETE CASCADE,
+    statuses JSONB NOT NULL,
+    transitions JSONB NOT NULL DEFAULT '[]'::jsonb,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+COMMENT ON COLUMN project_workflows.statuses IS 'Упорядоченный список статусов: [{key, name, category, wip_limit}]';
+COMMENT ON COLUMN project_workflows.transitions IS 'Разрешённые переходы [{from, to}]; пустой список разрешает любые переходы';
@@ -0,0 +1,2 @@
+DROP INDEX IF EXISTS idx_tasks_project_status_rank;
+ALTER TABLE tasks DROP COLUMN IF EXISTS rank;
@@ -0,0 +1,12 @@
+ALTER TABLE tasks ADD COLUMN rank TEXT NOT NULL DEFAULT '';
+
+-- Сохраняем текущий порядок колонок (новые задачи сверху)
+UPDATE tasks t
+SET rank = lpad(to_hex(r.rn::int), 6, '0') || 'i'
+FROM (
+    SELECT id, ROW_NUMBER() OVER (PARTITION BY project_id, status ORDER BY created_at DESC) AS rn
+    FROM tasks
+) r
+WHERE t.id = r.id;
+
+CREATE INDEX idx_tasks_project_status_rank ON tasks(project_id, status, rank COLLATE "C");
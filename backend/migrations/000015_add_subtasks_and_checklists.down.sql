@@ -0,0 +1,4 @@
+ALTER TABLE project_workflows DROP COLUMN IF EXISTS block_open_subtasks;
+DROP TABLE IF EXISTS checklist_items;
+DROP INDEX IF EXISTS idx_tasks_parent_id;
+ALTER TABLE tasks DROP COLUMN IF EXISTS parent_id;
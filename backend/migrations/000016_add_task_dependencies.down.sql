@@ -0,0 +1,2 @@
+ALTER TABLE project_workflows DROP COLUMN IF EXISTS block_on_dependencies;
+DROP TABLE IF EXISTS task_dependencies;
@@ -0,0 +1,12 @@
+CREATE TABLE task_dependencies (
+    blocker_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    blocked_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (blocker_id, blocked_id),
+    CHECK (blocker_id <> blocked_id)
+);
+
+CREATE INDEX idx_task_dependencies_blocked_id ON task_dependencies(blocked_id);
+
+ALTER TABLE project_workflows ADD COLUMN block_on_dependencies BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,4 @@
+-- Полнотекстовый поиск по названию и описанию задач.
+-- Выражение должно совпадать с taskSearchVector в store/query.go.
+CREATE INDEX idx_tasks_search ON tasks
+    USING GIN (to_tsvector('simple', title || ' ' || coalesce(description, '')));
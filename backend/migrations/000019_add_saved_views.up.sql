@@ -0,0 +1,14 @@
+CREATE TABLE saved_views (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    owner_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    query JSONB NOT NULL DEFAULT '{}',
+    group_by VARCHAR(20) NOT NULL DEFAULT '',
+    visibility VARCHAR(20) NOT NULL DEFAULT 'private',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    CHECK (visibility IN ('private', 'project'))
+);
+
+CREATE INDEX idx_saved_views_project_id ON saved_views(project_id);
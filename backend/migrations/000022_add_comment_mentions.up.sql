@@ -0,0 +1,10 @@
+-- Упоминания участников в комментариях; позиции считаются в символах Unicode
+CREATE TABLE comment_mentions (
+    comment_id UUID NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start INTEGER NOT NULL,
+    length INTEGER NOT NULL,
+    PRIMARY KEY (comment_id, start)
+);
+
+CREATE INDEX idx_comment_mentions_user_id ON comment_mentions(user_id);
@@ -0,0 +1,6 @@
+DROP TABLE IF EXISTS comment_reactions;
+
+DROP INDEX IF EXISTS idx_task_comments_parent_id;
+DELETE FROM task_comments WHERE deleted_at IS NOT NULL;
+ALTER TABLE task_comments DROP COLUMN IF EXISTS deleted_at;
+ALTER TABLE task_comments DROP COLUMN IF EXISTS parent_id;
@@ -0,0 +1,14 @@
+-- Ответы на комментарии (один уровень вложенности); удалённый комментарий
+-- с ответами остаётся как заглушка с deleted_at
+ALTER TABLE task_comments ADD COLUMN parent_id UUID REFERENCES task_comments(id) ON DELETE CASCADE;
+ALTER TABLE task_comments ADD COLUMN deleted_at TIMESTAMP WITH TIME ZONE;
+
+CREATE INDEX idx_task_comments_parent_id ON task_comments(parent_id);
+
+CREATE TABLE comment_reactions (
+    comment_id UUID NOT NULL REFERENCES task_comments(id) ON DELETE CASCADE,
+    user_id UUID NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    emoji VARCHAR(32) NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (comment_id, user_id, emoji)
+);
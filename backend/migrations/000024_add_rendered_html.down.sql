@@ -0,0 +1,2 @@
+ALTER TABLE task_comments DROP COLUMN IF EXISTS content_html;
+ALTER TABLE tasks DROP COLUMN IF EXISTS description_html;
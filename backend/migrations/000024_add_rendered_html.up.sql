@@ -0,0 +1,15 @@
+-- Отрендеренный и очищенный HTML рядом с исходным Markdown
+ALTER TABLE tasks ADD COLUMN description_html TEXT NOT NULL DEFAULT '';
+ALTER TABLE task_comments ADD COLUMN content_html TEXT NOT NULL DEFAULT '';
+
+-- Старые тексты написаны до поддержки Markdown, поэтому сохраняются как
+-- экранированный текст, так же как их рендерит markup.Plain
+UPDATE tasks SET description_html = '<p>' || replace(
+    replace(replace(replace(replace(replace(description, '&', '&amp;'), '''', '&#39;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'),
+    E'\n', E'<br>\n') || E'</p>\n'
+WHERE description <> '';
+
+UPDATE task_comments SET content_html = '<p>' || replace(
+    replace(replace(replace(replace(replace(content, '&', '&amp;'), '''', '&#39;'), '<', '&lt;'), '>', '&gt;'), '"', '&#34;'),
+    E'\n', E'<br>\n') || E'</p>\n'
+WHERE content <> '';
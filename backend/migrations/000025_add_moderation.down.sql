@@ -0,0 +1,17 @@
+DROP TABLE IF EXISTS moderation_events;
+
+DROP INDEX IF EXISTS idx_formulas_review_status;
+DROP INDEX IF EXISTS idx_constants_review_status;
+
+ALTER TABLE formulas DROP COLUMN IF EXISTS review_note;
+ALTER TABLE formulas DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE formulas DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE formulas DROP COLUMN IF EXISTS review_status;
+ALTER TABLE formulas DROP COLUMN IF EXISTS is_global;
+
+ALTER TABLE constants DROP COLUMN IF EXISTS review_note;
+ALTER TABLE constants DROP COLUMN IF EXISTS reviewed_at;
+ALTER TABLE constants DROP COLUMN IF EXISTS reviewed_by;
+ALTER TABLE constants DROP COLUMN IF EXISTS review_status;
+
+ALTER TABLE users DROP COLUMN IF EXISTS site_role;
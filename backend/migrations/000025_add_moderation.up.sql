@@ -0,0 +1,37 @@
+-- Роли на уровне сайта: куратор и администратор модерируют глобальные записи
+ALTER TABLE users ADD COLUMN site_role VARCHAR(20) NOT NULL DEFAULT 'user'
+    CHECK (site_role IN ('user', 'curator', 'admin'));
+
+ALTER TABLE constants ADD COLUMN review_status VARCHAR(20) NOT NULL DEFAULT 'approved'
+    CHECK (review_status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE constants ADD COLUMN reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE constants ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE constants ADD COLUMN review_note TEXT NOT NULL DEFAULT '';
+
+-- Глобальными раньше считались только формулы системного пользователя
+ALTER TABLE formulas ADD COLUMN is_global BOOLEAN NOT NULL DEFAULT FALSE;
+UPDATE formulas SET is_global = TRUE
+WHERE created_by IN (SELECT id FROM users WHERE email = 'physics-constants@system.local');
+
+ALTER TABLE formulas ADD COLUMN review_status VARCHAR(20) NOT NULL DEFAULT 'approved'
+    CHECK (review_status IN ('pending', 'approved', 'rejected'));
+ALTER TABLE formulas ADD COLUMN reviewed_by UUID REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE formulas ADD COLUMN reviewed_at TIMESTAMP WITH TIME ZONE;
+ALTER TABLE formulas ADD COLUMN review_note TEXT NOT NULL DEFAULT '';
+
+CREATE INDEX idx_constants_review_status ON constants(review_status) WHERE review_status = 'pending';
+CREATE INDEX idx_formulas_review_status ON formulas(review_status) WHERE review_status = 'pending';
+
+CREATE TABLE moderation_events (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    entity_type VARCHAR(20) NOT NULL,
+    entity_id UUID NOT NULL,
+    actor_id UUID REFERENCES users(id) ON DELETE SET NULL,
+    action VARCHAR(20) NOT NULL,
+    note TEXT NOT NULL DEFAULT '',
+    data JSONB,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_moderation_events_entity ON moderation_events(entity_type, entity_id);
+CREATE INDEX idx_moderation_events_created ON moderation_events(created_at DESC, id DESC);
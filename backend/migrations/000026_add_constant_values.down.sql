@@ -0,0 +1,5 @@
+ALTER TABLE constants DROP COLUMN IF EXISTS dimensions;
+ALTER TABLE constants DROP COLUMN IF EXISTS unit_scale;
+ALTER TABLE constants DROP COLUMN IF EXISTS is_exact;
+ALTER TABLE constants DROP COLUMN IF EXISTS uncertainty;
+ALTER TABLE constants DROP COLUMN IF EXISTS numeric_value;
@@ -0,0 +1,60 @@
+-- Числовое значение, стандартная неопределённость и единица, разобранная в
+-- показатели степеней основных величин СИ: {"m": 1, "s": -1}.
+-- value и unit остаются в том виде, в котором их ввёл пользователь.
+ALTER TABLE constants ADD COLUMN numeric_value DOUBLE PRECISION;
+ALTER TABLE constants ADD COLUMN uncertainty DOUBLE PRECISION CHECK (uncertainty > 0);
+ALTER TABLE constants ADD COLUMN is_exact BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE constants ADD COLUMN unit_scale DOUBLE PRECISION;
+ALTER TABLE constants ADD COLUMN dimensions JSONB;
+
+-- Значения, записанные обычным числом. Остальные строки остаются NULL,
+-- пока их не отредактируют через API, где работает полный разбор
+UPDATE constants SET numeric_value = trim(value)::DOUBLE PRECISION
+WHERE trim(value) ~ '^[+-]?([0-9]+\.?[0-9]*|\.[0-9]+)([eE][+-]?[0-9]{1,2})?$'
+  AND length(trim(value)) <= 40;
+
+-- Единицы из 000005_seed_physics_constants и самые распространённые простые
+UPDATE constants c SET unit_scale = 1, dimensions = u.dimensions::JSONB
+FROM (VALUES
+    ('', '{}'),
+    ('m', '{"m": 1}'),
+    ('kg', '{"kg": 1}'),
+    ('s', '{"s": 1}'),
+    ('A', '{"A": 1}'),
+    ('K', '{"K": 1}'),
+    ('mol', '{"mol": 1}'),
+    ('C', '{"s": 1, "A": 1}'),
+    ('m/s', '{"m": 1, "s": -1}'),
+    ('m/s^2', '{"m": 1, "s": -2}'),
+    ('N', '{"kg": 1, "m": 1, "s": -2}'),
+    ('J', '{"kg": 1, "m": 2, "s": -2}'),
+    ('W', '{"kg": 1, "m": 2, "s": -3}'),
+    ('Pa', '{"kg": 1, "m": -1, "s": -2}'),
+    ('J·s', '{"kg": 1, "m": 2, "s": -1}'),
+    ('mol^-1', '{"mol": -1}'),
+    ('J·K^-1', '{"kg": 1, "m": 2, "s": -2, "K": -1}'),
+    ('m^3·kg^-1·s^-2', '{"m": 3, "kg": -1, "s": -2}'),
+    ('F·m^-1', '{"kg": -1, "m": -3, "s": 4, "A": 2}'),
+    ('N·A^-2', '{"kg": 1, "m": 1, "s": -2, "A": -2}'),
+    ('J·mol^-1·K^-1', '{"kg": 1, "m": 2, "s": -2, "K": -1, "mol": -1}'),
+    ('W·m^-2·K^-4', '{"kg": 1, "s": -3, "K": -4}')
+) AS u(unit, dimensions)
+WHERE trim(c.unit) = u.unit;
+
+-- Точные по определению СИ 2019 года и неопределённости CODATA 2018
+UPDATE constants SET is_exact = TRUE
+WHERE scope = 'global'
+  AND symbol IN ('c', 'h', 'ħ', 'e', 'N_A', 'k_B', 'R', 'σ')
+  AND created_by IN (SELECT id FROM users WHERE email = 'physics-constants@system.local');
+
+UPDATE constants c SET uncertainty = u.uncertainty
+FROM (VALUES
+    ('G', 1.5e-15),
+    ('m_e', 2.8e-40),
+    ('m_p', 5.1e-37),
+    ('ε0', 1.3e-21),
+    ('μ0', 1.9e-16)
+) AS u(symbol, uncertainty)
+WHERE c.symbol = u.symbol
+  AND c.scope = 'global'
+  AND c.created_by IN (SELECT id FROM users WHERE email = 'physics-constants@system.local');
@@ -0,0 +1,3 @@
+ALTER TABLE constants DROP COLUMN IF EXISTS dataset_id;
+
+DROP TABLE IF EXISTS constant_datasets;
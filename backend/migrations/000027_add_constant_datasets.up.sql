@@ -0,0 +1,16 @@
+-- Импорты эталонных значений (CODATA) в глобальную библиотеку констант
+CREATE TABLE constant_datasets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    source VARCHAR(50) NOT NULL,
+    version VARCHAR(50) NOT NULL,
+    added INTEGER NOT NULL DEFAULT 0,
+    updated INTEGER NOT NULL DEFAULT 0,
+    unchanged INTEGER NOT NULL DEFAULT 0,
+    imported_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    imported_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_constant_datasets_imported ON constant_datasets(source, imported_at DESC);
+
+-- Набор данных, из которого пришло текущее значение константы
+ALTER TABLE constants ADD COLUMN dataset_id UUID REFERENCES constant_datasets(id) ON DELETE SET NULL;
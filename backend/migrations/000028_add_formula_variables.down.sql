@@ -0,0 +1 @@
+DROP TABLE IF EXISTS formula_variables;
@@ -0,0 +1,12 @@
+-- Объявленные переменные формул: единица измерения или связь с константой,
+-- по которым проверяется размерность формулы
+CREATE TABLE formula_variables (
+    formula_id UUID NOT NULL REFERENCES formulas(id) ON DELETE CASCADE,
+    symbol VARCHAR(100) NOT NULL,
+    unit VARCHAR(100) NOT NULL DEFAULT '',
+    constant_id UUID REFERENCES constants(id) ON DELETE SET NULL,
+    description TEXT NOT NULL DEFAULT '',
+    PRIMARY KEY (formula_id, symbol)
+);
+
+CREATE INDEX idx_formula_variables_constant ON formula_variables(constant_id);
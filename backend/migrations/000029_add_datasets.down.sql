@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS dataset_formulas;
+DROP TABLE IF EXISTS dataset_tasks;
+DROP TABLE IF EXISTS dataset_rows;
+DROP TABLE IF EXISTS dataset_columns;
+DROP TABLE IF EXISTS datasets;
@@ -0,0 +1,58 @@
+-- Наборы измерений проекта
+CREATE TABLE datasets (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    project_id UUID NOT NULL REFERENCES projects(id) ON DELETE CASCADE,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_by UUID REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_datasets_project_id ON datasets(project_id);
+
+-- Столбцы: тип, единица измерения и погрешность по умолчанию;
+-- производные столбцы вычисляются по формуле
+CREATE TABLE dataset_columns (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    dataset_id UUID NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    kind VARCHAR(20) NOT NULL DEFAULT 'number',
+    unit VARCHAR(100) NOT NULL DEFAULT '',
+    uncertainty DOUBLE PRECISION,
+    formula_id UUID REFERENCES formulas(id) ON DELETE SET NULL,
+    position INTEGER NOT NULL DEFAULT 0,
+    UNIQUE (dataset_id, name),
+    CHECK (kind IN ('number', 'text'))
+);
+
+-- Значения строки хранятся по идентификаторам столбцов
+CREATE TABLE dataset_rows (
+    id UUID PRIMARY KEY DEFAULT uuid_generate_v4(),
+    dataset_id UUID NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    cells JSONB NOT NULL DEFAULT '{}',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_dataset_rows_dataset_id ON dataset_rows(dataset_id, position);
+
+-- Связи наборов с задачами и формулами
+CREATE TABLE dataset_tasks (
+    dataset_id UUID NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+    task_id UUID NOT NULL REFERENCES tasks(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dataset_id, task_id)
+);
+
+CREATE INDEX idx_dataset_tasks_task_id ON dataset_tasks(task_id);
+
+CREATE TABLE dataset_formulas (
+    dataset_id UUID NOT NULL REFERENCES datasets(id) ON DELETE CASCADE,
+    formula_id UUID NOT NULL REFERENCES formulas(id) ON DELETE CASCADE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (dataset_id, formula_id)
+);
+
+CREATE INDEX idx_dataset_formulas_formula_id ON dataset_formulas(formula_id);
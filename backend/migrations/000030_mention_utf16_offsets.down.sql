@@ -0,0 +1,19 @@
+UPDATE comment_mentions m
+SET start = (
+        SELECT COUNT(*)
+        FROM (
+            SELECT SUM(CASE WHEN ascii(ch) > 65535 THEN 2 ELSE 1 END) OVER (ORDER BY n) AS units
+            FROM regexp_split_to_table(c.content, '') WITH ORDINALITY AS t(ch, n)
+        ) AS s
+        WHERE s.units <= m.start
+    ),
+    length = (
+        SELECT COUNT(*)
+        FROM (
+            SELECT SUM(CASE WHEN ascii(ch) > 65535 THEN 2 ELSE 1 END) OVER (ORDER BY n) AS units
+            FROM regexp_split_to_table(c.content, '') WITH ORDINALITY AS t(ch, n)
+        ) AS s
+        WHERE s.units > m.start AND s.units <= m.start + m.length
+    )
+FROM task_comments c
+WHERE c.id = m.comment_id;
@@ -0,0 +1,15 @@
+-- Позиции упоминаний теперь считаются в кодовых единицах UTF-16, как в
+-- JavaScript: символы вне BMP занимают две единицы
+UPDATE comment_mentions m
+SET length = m.length + (
+        SELECT COUNT(*)
+        FROM regexp_split_to_table(substr(c.content, m.start + 1, m.length), '') AS ch
+        WHERE ascii(ch) > 65535
+    ),
+    start = m.start + (
+        SELECT COUNT(*)
+        FROM regexp_split_to_table(substr(c.content, 1, m.start), '') AS ch
+        WHERE ascii(ch) > 65535
+    )
+FROM task_comments c
+WHERE c.id = m.comment_id;